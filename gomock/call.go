@@ -17,26 +17,56 @@ package gomock
 import (
 	"fmt"
 	"reflect"
-	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// defaultKeptInvocations is the number of invocations a Call keeps records
+// of unless KeepInvocations is used.
+const defaultKeptInvocations = 1000
+
 // Call represents an expected call to a mock.
+//
+// The actions declared with Do, DoAndReturn, Return, SetArg and the like
+// are all run, in the order they were declared, when the call is matched.
+// The mocked function returns the values of the last Return or DoAndReturn.
 type Call struct {
-	t TestReporter // for triggering test failures on invalid call setup
+	t    TestHelper  // for triggering test failures on invalid call setup
+	ctrl *Controller // the controller the call was recorded with, if any
+	id   uint64      // the call's position among the controller's calls
+	// caller is the token of the controller the call was recorded with,
+	// with WithCallerIsolation, or 0.
+	caller uint64
 
 	receiver   interface{}  // the receiver of the method call
 	method     string       // the name of the method
 	methodType reflect.Type // the type of the method
 	args       []Matcher    // the args
 	origin     string       // file and line number of call setup
+	name       string       // the label set with Name, if any
 
-	preReqs []*Call // prerequisite calls
+	preReqs   []*Call // prerequisite calls
+	anyOrder  bool    // whether the call is exempt from sequencing
+	byDefault bool    // whether the call was recorded with Controller.Default
+	// strictPrev is the call recorded before this one with WithStrictOrder,
+	// until this one is matched.
+	strictPrev *Call
 
 	// Expectations
 	minCalls, maxCalls int
+	minSet, maxSet     bool // whether minCalls and maxCalls were set explicitly
+
+	numCalls int           // actual number made
+	done     chan struct{} // closed once the call is satisfied, if needed
+
+	invocations     []CallRecord // the most recent invocations, a ring once full
+	oldest          int          // the index of the oldest invocation
+	keptInvocations int          // the maximum length of invocations
 
-	numCalls int // actual number made
+	// returns and panics record whether Return or DoAndReturn, and Panic,
+	// have been declared, since they can't be combined.
+	returns, panics bool
 
 	// actions are called when this Call is called. Each action gets the args and
 	// can set the return values by returning a non-nil slice. Actions run in the
@@ -46,59 +76,179 @@ type Call struct {
 
 // newCall creates a *Call. It requires the method type in order to support
 // unexported methods.
-func newCall(t TestReporter, receiver interface{}, method string, methodType reflect.Type, args ...interface{}) *Call {
-	if h, ok := t.(testHelper); ok {
-		h.Helper()
+func newCall(t TestHelper, receiver interface{}, method string, methodType reflect.Type, args ...interface{}) *Call {
+	t.Helper()
+
+	origin := callerInfo(3)
+	if err := checkArgs(methodType, args); err != nil {
+		t.Fatalf("invalid arguments for %T.%v: %v [%s]", receiver, method, err, origin)
 	}
 
-	// TODO: check arity, types.
 	margs := make([]Matcher, len(args))
 	for i, arg := range args {
-		if m, ok := arg.(Matcher); ok {
-			margs[i] = m
-		} else if arg == nil {
-			// Handle nil specially so that passing a nil interface value
-			// will match the typed nils of concrete args.
-			margs[i] = Nil()
-		} else {
-			margs[i] = Eq(arg)
-		}
+		margs[i] = wrapMatcher(arg)
 	}
 
-	origin := callerInfo(3)
-	actions := []func([]interface{}) []interface{}{func([]interface{}) []interface{} {
-		// Synthesize the zero value for each of the return args' types.
-		rets := make([]interface{}, methodType.NumOut())
-		for i := 0; i < methodType.NumOut(); i++ {
-			rets[i] = reflect.Zero(methodType.Out(i)).Interface()
+	for i, m := range margs {
+		if _, ok := m.(restAnyMatcher); ok && (!methodType.IsVariadic() || i < methodType.NumIn()-1 || i != len(margs)-1) {
+			t.Fatalf("invalid matcher for argument %d of %T.%v: gomock.RestAny() can only be the last argument of a variadic method [%s]", i, receiver, method, origin)
 		}
-		return rets
+		v, ok := m.(argTypeValidator)
+		if !ok || i >= methodType.NumIn() || (methodType.IsVariadic() && i >= methodType.NumIn()-1) {
+			continue
+		}
+		if err := v.validateArgType(methodType.In(i)); err != nil {
+			t.Fatalf("invalid matcher for argument %d of %T.%v: %v [%s]", i, receiver, method, err, origin)
+		}
+	}
+	// Synthesize the zero value for each of the return args' types, once:
+	// like the values passed to Return, they are shared by the calls.
+	zeros := make([]interface{}, methodType.NumOut())
+	for i := range zeros {
+		zeros[i] = reflect.Zero(methodType.Out(i)).Interface()
+	}
+	actions := []func([]interface{}) []interface{}{func([]interface{}) []interface{} {
+		return zeros
 	}}
 	return &Call{t: t, receiver: receiver, method: method, methodType: methodType,
-		args: margs, origin: origin, minCalls: 1, maxCalls: 1, actions: actions}
+		args: margs, origin: origin, minCalls: 1, maxCalls: 1, actions: actions,
+		keptInvocations: defaultKeptInvocations}
+}
+
+// Name labels the call, to tell it apart from similar calls in failure
+// messages. The label doesn't affect matching.
+func (c *Call) Name(label string) *Call {
+	c.name = label
+	return c
+}
+
+// Receiver returns the mock the call is expected on.
+func (c *Call) Receiver() interface{} {
+	return c.receiver
+}
+
+// Method returns the name of the expected method.
+func (c *Call) Method() string {
+	return c.method
+}
+
+// Origin returns the file and line number where the call was recorded.
+func (c *Call) Origin() string {
+	return c.origin
 }
 
-// AnyTimes allows the expectation to be called 0 or more times
+// IsSatisfied reports whether the call has been made the minimum number of
+// times it is expected.
+func (c *Call) IsSatisfied() bool {
+	if c.ctrl != nil {
+		c.ctrl.mu.Lock()
+		defer c.ctrl.mu.Unlock()
+	}
+	return c.satisfied()
+}
+
+// checkArgs checks the number of expected arguments against the method
+// type, and that the types of those that aren't matchers, and of the
+// TypedMatchers, fit the parameters they are expected for.
+func checkArgs(methodType reflect.Type, args []interface{}) error {
+	n := methodType.NumIn()
+	if methodType.IsVariadic() {
+		n--
+		if len(args) < n {
+			return fmt.Errorf("got %d arguments, want at least %d", len(args), n)
+		}
+	} else if len(args) != n {
+		return fmt.Errorf("got %d arguments, want %d", len(args), n)
+	}
+
+	for i, arg := range args {
+		var at reflect.Type
+		var packed reflect.Type // the variadic slice type, for a single variadic argument
+		if i < n {
+			at = methodType.In(i)
+		} else {
+			packed = methodType.In(n)
+			at = packed.Elem()
+			if len(args) != n+1 {
+				packed = nil
+			}
+		}
+
+		var t reflect.Type
+		switch arg := arg.(type) {
+		case TypedMatcher:
+			t = arg.ExpectedType()
+		case Matcher:
+			continue
+		case nil:
+			if !nillable(at) && (packed == nil || !nillable(packed)) {
+				return fmt.Errorf("argument %d is nil, but %v is not nillable", i, at)
+			}
+			continue
+		default:
+			t = reflect.TypeOf(arg)
+		}
+		if t == nil || t.AssignableTo(at) || (packed != nil && t.AssignableTo(packed)) {
+			continue
+		}
+		if _, ok := arg.(Matcher); ok {
+			// A matcher for an interface type may match the values of
+			// the types implementing it.
+			if t.Kind() == reflect.Interface && (at.Implements(t) || at.Kind() == reflect.Interface) {
+				continue
+			}
+			return fmt.Errorf("argument %d is a matcher for %v, which is not assignable to %v", i, t, at)
+		}
+		return fmt.Errorf("argument %d is a %v, which is not assignable to %v", i, t, at)
+	}
+	return nil
+}
+
+func nillable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return true
+	}
+	return false
+}
+
+// AnyTimes allows the expectation to be called 0 or more times. If MinTimes
+// or Times have been called, their minimum is kept.
 func (c *Call) AnyTimes() *Call {
-	c.minCalls, c.maxCalls = 0, 1e8 // close enough to infinity
+	if c.ctrl != nil {
+		c.ctrl.mu.Lock()
+		defer c.ctrl.mu.Unlock()
+	}
+	c.maxCalls, c.maxSet = 1e8, true // close enough to infinity
+	if !c.minSet {
+		c.minCalls = 0
+	}
 	return c
 }
 
-// MinTimes requires the call to occur at least n times. If AnyTimes or MaxTimes have not been called, MinTimes also
+// MinTimes requires the call to occur at least n times. If AnyTimes, MaxTimes or Times have not been called, MinTimes also
 // sets the maximum number of calls to infinity.
 func (c *Call) MinTimes(n int) *Call {
-	c.minCalls = n
-	if c.maxCalls == 1 {
+	if c.ctrl != nil {
+		c.ctrl.mu.Lock()
+		defer c.ctrl.mu.Unlock()
+	}
+	c.minCalls, c.minSet = n, true
+	if !c.maxSet {
 		c.maxCalls = 1e8
 	}
 	return c
 }
 
-// MaxTimes limits the number of calls to n times. If AnyTimes or MinTimes have not been called, MaxTimes also
+// MaxTimes limits the number of calls to n times. If AnyTimes, MinTimes or Times have not been called, MaxTimes also
 // sets the minimum number of calls to 0.
 func (c *Call) MaxTimes(n int) *Call {
-	c.maxCalls = n
-	if c.minCalls == 1 {
+	if c.ctrl != nil {
+		c.ctrl.mu.Lock()
+		defer c.ctrl.mu.Unlock()
+	}
+	c.maxCalls, c.maxSet = n, true
+	if !c.minSet {
 		c.minCalls = 0
 	}
 	return c
@@ -107,25 +257,41 @@ func (c *Call) MaxTimes(n int) *Call {
 // DoAndReturn declares the action to run when the call is matched.
 // The return values from this function are returned by the mocked function.
 // It takes an interface{} argument to support n-arity functions.
+// See Do for the accepted function signatures.
 func (c *Call) DoAndReturn(f interface{}) *Call {
-	// TODO: Check arity and types here, rather than dying badly elsewhere.
-	v := reflect.ValueOf(f)
+	c.t.Helper()
 
-	c.addAction(func(args []interface{}) []interface{} {
-		vargs := make([]reflect.Value, len(args))
-		ft := v.Type()
-		for i := 0; i < len(args); i++ {
-			if args[i] != nil {
-				vargs[i] = reflect.ValueOf(args[i])
-			} else {
-				// Use the zero value for the arg.
-				vargs[i] = reflect.Zero(ft.In(i))
-			}
+	if !c.checkNotPanicking("DoAndReturn") {
+		return c
+	}
+	call := c.actionFunc("DoAndReturn", f)
+	if call == nil {
+		return c
+	}
+	ft, mt := reflect.TypeOf(f), c.methodType
+	if ft.NumOut() != mt.NumOut() {
+		c.t.Fatalf("wrong number of return values of the func passed to DoAndReturn for %T.%v: got %d, want %d [%s]",
+			c.receiver, c.method, ft.NumOut(), mt.NumOut(), c.origin)
+		return c
+	}
+	for i := 0; i < mt.NumOut(); i++ {
+		if got, want := ft.Out(i), mt.Out(i); !got.AssignableTo(want) {
+			c.t.Fatalf("wrong type of return value %d of the func passed to DoAndReturn for %T.%v: %v is not assignable to %v [%s]",
+				i, c.receiver, c.method, got, want, c.origin)
+			return c
 		}
-		vrets := v.Call(vargs)
+	}
+
+	c.returns = true
+	c.addAction(func(args []interface{}) []interface{} {
+		vrets := call(args)
 		rets := make([]interface{}, len(vrets))
 		for i, ret := range vrets {
-			rets[i] = ret.Interface()
+			// Convert to the method's return type so that the generated
+			// code can return the values with a type assertion.
+			v := reflect.New(mt.Out(i)).Elem()
+			v.Set(ret)
+			rets[i] = v.Interface()
 		}
 		return rets
 	})
@@ -136,149 +302,676 @@ func (c *Call) DoAndReturn(f interface{}) *Call {
 // return values are ignored to retain backward compatibility. To use the
 // return values call DoAndReturn.
 // It takes an interface{} argument to support n-arity functions.
+//
+// The function must take as many arguments as the method, and each of the
+// method's parameter types must be assignable to the function's, or be an
+// interface the function's parameter type implements. For a variadic
+// method, the function may either be variadic as well or take the variadic
+// arguments as a slice:
+//
+//	// For Printf(format string, args ...interface{}):
+//	call.Do(func(format string, args ...interface{}) { ... })
+//	call.Do(func(format string, args []interface{}) { ... })
 func (c *Call) Do(f interface{}) *Call {
-	// TODO: Check arity and types here, rather than dying badly elsewhere.
-	v := reflect.ValueOf(f)
+	c.t.Helper()
 
+	call := c.actionFunc("Do", f)
+	if call == nil {
+		return c
+	}
 	c.addAction(func(args []interface{}) []interface{} {
+		call(args)
+		return nil
+	})
+	return c
+}
+
+// DoVariadicSlice is like Do, for a variadic method, but requires the
+// function to take the variadic arguments packed into a slice, rather than to
+// be variadic itself:
+//
+//	// For Printf(format string, args ...interface{}):
+//	call.DoVariadicSlice(func(format string, args []interface{}) { ... })
+//
+// The slice holds all of the variadic arguments, and is empty if there are
+// none.
+func (c *Call) DoVariadicSlice(f interface{}) *Call {
+	c.t.Helper()
+
+	if !c.methodType.IsVariadic() {
+		c.t.Fatalf("DoVariadicSlice for %T.%v, which isn't variadic [%s]", c.receiver, c.method, c.origin)
+		return c
+	}
+	if ft := reflect.TypeOf(f); ft != nil && ft.Kind() == reflect.Func && ft.IsVariadic() {
+		c.t.Fatalf("the func passed to DoVariadicSlice for %T.%v is variadic, want a %v parameter instead [%s]",
+			c.receiver, c.method, c.methodType.In(c.methodType.NumIn()-1), c.origin)
+		return c
+	}
+	return c.Do(f)
+}
+
+// actionFunc checks that f can be called with the arguments of the mocked
+// method, reporting a fatal error at setup time if not, and returns a
+// function that calls f with the arguments of an actual call.
+func (c *Call) actionFunc(name string, f interface{}) func(args []interface{}) []reflect.Value {
+	c.t.Helper()
+
+	v := reflect.ValueOf(f)
+	if v.Kind() != reflect.Func {
+		c.t.Fatalf("argument to %s for %T.%v is a %T, not a func [%s]",
+			name, c.receiver, c.method, f, c.origin)
+		return nil
+	}
+	ft, mt := v.Type(), c.methodType
+	if ft.NumIn() != mt.NumIn() || (ft.IsVariadic() && !mt.IsVariadic()) {
+		c.t.Fatalf("wrong number of arguments of the func passed to %s for %T.%v: got %v, want %v [%s]",
+			name, c.receiver, c.method, ft, mt, c.origin)
+		return nil
+	}
+	for i := 0; i < mt.NumIn(); i++ {
+		want, got := mt.In(i), ft.In(i)
+		variadic := ft.IsVariadic() && i == ft.NumIn()-1
+		if variadic {
+			want, got = want.Elem(), got.Elem()
+		}
+		// Every argument of the method must be assignable to the func's
+		// parameter: a func taking a concrete type can't receive all the
+		// values of an interface parameter. The variadic arguments are the
+		// exception, as a func may take, e.g., the ...interface{} of a
+		// method as ...int, to be checked when the call is made.
+		if !want.AssignableTo(got) && !(variadic && want.Kind() == reflect.Interface && got.Implements(want)) {
+			c.t.Fatalf("wrong type of argument %d of the func passed to %s for %T.%v: %v is not assignable to %v [%s]",
+				i, name, c.receiver, c.method, want, got, c.origin)
+			return nil
+		}
+	}
+
+	// A variadic method whose action takes the variadic arguments as a
+	// slice needs them packed first.
+	pack := mt.IsVariadic() && !ft.IsVariadic()
+	return func(args []interface{}) []reflect.Value {
+		if pack {
+			n := mt.NumIn() - 1
+			args = append(append([]interface{}(nil), args[:n]...), c.packVariadic(args, n))
+		}
 		vargs := make([]reflect.Value, len(args))
-		ft := v.Type()
-		for i := 0; i < len(args); i++ {
-			if args[i] != nil {
-				vargs[i] = reflect.ValueOf(args[i])
+		for i, arg := range args {
+			if arg != nil {
+				vargs[i] = reflect.ValueOf(arg)
+			} else if ft.IsVariadic() && i >= ft.NumIn()-1 {
+				vargs[i] = reflect.Zero(ft.In(ft.NumIn() - 1).Elem())
 			} else {
 				// Use the zero value for the arg.
 				vargs[i] = reflect.Zero(ft.In(i))
 			}
 		}
-		v.Call(vargs)
-		return nil
-	})
-	return c
+		return v.Call(vargs)
+	}
 }
 
 // Return declares the values to be returned by the mocked function call.
+// Without Return or DoAndReturn, the mocked function returns the zero values
+// of its return types, e.g. "" and nil for a method returning
+// (string, error). Lazy, ReturnChannelOf and ReturnChannelFrom make values
+// computed each time the call is matched.
 func (c *Call) Return(rets ...interface{}) *Call {
-	if h, ok := c.t.(testHelper); ok {
-		h.Helper()
+	c.t.Helper()
+
+	if !c.checkNotPanicking("Return") {
+		return c
 	}
+	rets, err := c.checkReturns("Return", rets)
+	if err != nil {
+		c.t.Fatalf("%v [%s]", err, c.origin)
+		return c
+	}
+
+	c.returns = true
+	c.addAction(func([]interface{}) []interface{} {
+		c.t.Helper()
+		return c.evalReturns("Return", rets)
+	})
 
+	return c
+}
+
+// ReturnSeq declares the values returned by successive calls: the first
+// call returns the values in the first set, the second those in the second
+// set, and so on. Unless the number of calls is set, e.g. with Times or
+// AnyTimes, the call is expected once per set. Calls beyond the last set
+// return the values of the last set again.
+//
+//	mockStore.EXPECT().Get("k").ReturnSeq(
+//		[]interface{}{"", errTimeout},
+//		[]interface{}{"v", nil},
+//	)
+func (c *Call) ReturnSeq(sets ...[]interface{}) *Call {
+	c.t.Helper()
+
+	if !c.checkNotPanicking("ReturnSeq") {
+		return c
+	}
+	if len(sets) == 0 {
+		c.t.Fatalf("ReturnSeq for %T.%v needs at least one set of return values [%s]",
+			c.receiver, c.method, c.origin)
+		return c
+	}
+	checked := make([][]interface{}, len(sets))
+	for i, set := range sets {
+		rets, err := c.checkReturns(fmt.Sprintf("ReturnSeq (set %d)", i), set)
+		if err != nil {
+			c.t.Fatalf("%v [%s]", err, c.origin)
+			return c
+		}
+		checked[i] = rets
+	}
+
+	c.expectSets(len(sets))
+	c.returns = true
+	var mu sync.Mutex
+	next := 0
+	c.addAction(func([]interface{}) []interface{} {
+		c.t.Helper()
+		mu.Lock()
+		rets := checked[next]
+		if next < len(checked)-1 {
+			next++
+		}
+		mu.Unlock()
+		return c.evalReturns("ReturnSeq", rets)
+	})
+	return c
+}
+
+// expectSets expects the call n times, once per set of values passed to
+// ReturnSeq, unless the number of calls was set explicitly.
+func (c *Call) expectSets(n int) {
+	if c.ctrl != nil {
+		c.ctrl.mu.Lock()
+		defer c.ctrl.mu.Unlock()
+	}
+	if !c.minSet && !c.maxSet {
+		c.minCalls, c.maxCalls = n, n
+	}
+}
+
+// evalReturns computes the Lazy values among rets, if any, and checks them,
+// and makes the channels of the ReturnChannelOf and ReturnChannelFrom values,
+// which were checked when they were passed to name.
+//
+// It runs when the call is matched, outside the controller's lock and maybe
+// on a goroutine other than the test's, where Fatalf can't stop the test. So
+// a Lazy value of the wrong type is reported with Errorf, and the call
+// returns zero values.
+func (c *Call) evalReturns(name string, rets []interface{}) []interface{} {
+	c.t.Helper()
+
+	lazy := false
+	for _, ret := range rets {
+		switch ret.(type) {
+		case lazyValue, channelValue:
+			lazy = true
+		}
+	}
+	if !lazy {
+		return rets
+	}
+	vals := make([]interface{}, len(rets))
+	for i, ret := range rets {
+		vals[i] = ret
+		switch v := ret.(type) {
+		case lazyValue:
+			val, err := c.checkReturn(name, i, v.f())
+			if err != nil {
+				c.t.Errorf("%v, as evaluated by Lazy [%s]", err, c.origin)
+				return c.zeroReturns()
+			}
+			vals[i] = val
+		case channelValue:
+			vals[i] = v.make()
+		}
+	}
+	return vals
+}
+
+// zeroReturns returns the zero values of the method's results.
+func (c *Call) zeroReturns() []interface{} {
+	zeros := make([]interface{}, c.methodType.NumOut())
+	for i := range zeros {
+		zeros[i] = reflect.Zero(c.methodType.Out(i)).Interface()
+	}
+	return zeros
+}
+
+// checkReturns checks that rets can be returned by the mocked method and
+// returns a copy of them converted to its return types, so that the
+// generated code can return them with type assertions. Lazy values are left
+// unchecked.
+func (c *Call) checkReturns(name string, rets []interface{}) ([]interface{}, error) {
 	mt := c.methodType
 	if len(rets) != mt.NumOut() {
-		c.t.Fatalf("wrong number of arguments to Return for %T.%v: got %d, want %d [%s]",
-			c.receiver, c.method, len(rets), mt.NumOut(), c.origin)
+		return nil, fmt.Errorf("wrong number of arguments to %s for %T.%v: got %d, want %d",
+			name, c.receiver, c.method, len(rets), mt.NumOut())
 	}
+	rets = append([]interface{}(nil), rets...)
 	for i, ret := range rets {
-		if got, want := reflect.TypeOf(ret), mt.Out(i); got == want {
-			// Identical types; nothing to do.
-		} else if got == nil {
-			// Nil needs special handling.
-			switch want.Kind() {
-			case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
-				// ok
-			default:
-				c.t.Fatalf("argument %d to Return for %T.%v is nil, but %v is not nillable [%s]",
-					i, c.receiver, c.method, want, c.origin)
+		if _, ok := ret.(lazyValue); ok {
+			continue
+		}
+		if v, ok := ret.(channelValue); ok {
+			if v.typ != nil {
+				// Checked when it was passed to Return.
+				continue
 			}
-		} else if got.AssignableTo(want) {
-			// Assignable type relation. Make the assignment now so that the generated code
-			// can return the values with a type assertion.
-			v := reflect.New(want).Elem()
-			v.Set(reflect.ValueOf(ret))
-			rets[i] = v.Interface()
-		} else {
-			c.t.Fatalf("wrong type of argument %d to Return for %T.%v: %v is not assignable to %v [%s]",
-				i, c.receiver, c.method, got, want, c.origin)
+			v, err := v.check(mt.Out(i))
+			if err != nil {
+				return nil, fmt.Errorf("wrong channel for argument %d to %s for %T.%v: %v",
+					i, name, c.receiver, c.method, err)
+			}
+			rets[i] = v
+			continue
+		}
+		v, err := c.checkReturn(name, i, ret)
+		if err != nil {
+			return nil, err
+		}
+		rets[i] = v
+	}
+	return rets, nil
+}
+
+// checkReturn checks that ret can be returned as the result i of the mocked
+// method, and returns it converted to the type of the result.
+func (c *Call) checkReturn(name string, i int, ret interface{}) (interface{}, error) {
+	got, want := reflect.TypeOf(ret), c.methodType.Out(i)
+	switch {
+	case got == want:
+		// Identical types; nothing to do.
+	case got == nil:
+		// Nil needs special handling.
+		switch want.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+			// ok
+		default:
+			return nil, fmt.Errorf("argument %d to %s for %T.%v is nil, but %v is not nillable",
+				i, name, c.receiver, c.method, want)
 		}
+	case got.AssignableTo(want):
+		// Assignable type relation. Make the assignment now so that the generated code
+		// can return the values with a type assertion.
+		v := reflect.New(want).Elem()
+		v.Set(reflect.ValueOf(ret))
+		return v.Interface(), nil
+	default:
+		return nil, fmt.Errorf("wrong type of argument %d to %s for %T.%v: %v is not assignable to %v",
+			i, name, c.receiver, c.method, got, want)
 	}
+	return ret, nil
+}
 
+type lazyValue struct {
+	f func() interface{}
+}
+
+// Lazy wraps a value passed to Return so that it is computed by f each time
+// the call is matched, rather than when the expectation is set. This allows
+// returning values that are only known later in the test. The value is
+// type-checked when it is computed.
+//
+//	var conn *Conn
+//	mockDialer.EXPECT().Dial().Return(gomock.Lazy(func() interface{} { return conn }), nil)
+//	conn = newConn()
+func Lazy(f func() interface{}) interface{} {
+	return lazyValue{f}
+}
+
+// Panic declares that the mocked function panics with v when the call is
+// matched. The call counts as made before the panic, so a test that recovers
+// from it can still pass Finish. Panic can't be combined with Return or
+// DoAndReturn.
+func (c *Call) Panic(v interface{}) *Call {
+	c.t.Helper()
+
+	if c.returns {
+		c.t.Fatalf("Panic for %T.%v can't be combined with Return or DoAndReturn [%s]",
+			c.receiver, c.method, c.origin)
+		return c
+	}
+	c.panics = true
 	c.addAction(func([]interface{}) []interface{} {
-		return rets
+		panic(v)
+	})
+	return c
+}
+
+// WaitUntil declares that the mocked function blocks, when the call is
+// matched, until ch is closed or a value is received from it. Like other
+// actions, it runs without holding the controller's lock, so other calls
+// can proceed meanwhile.
+func (c *Call) WaitUntil(ch <-chan struct{}) *Call {
+	c.addAction(func([]interface{}) []interface{} {
+		<-ch
+		return nil
+	})
+	return c
+}
+
+// Notify declares that a value is sent on ch when the call is matched. The
+// send doesn't block: if ch is unbuffered and nobody is receiving, or its
+// buffer is full, the notification is dropped. Use a buffered channel to
+// count calls.
+func (c *Call) Notify(ch chan<- struct{}) *Call {
+	c.addAction(func([]interface{}) []interface{} {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+		return nil
 	})
+	return c
+}
 
+// WithinDuration declares that the call has to be satisfied within d from
+// now. Otherwise an error naming the call is reported, from another
+// goroutine, so it is reported with Errorf. Nothing is reported once the
+// controller is finished.
+func (c *Call) WithinDuration(d time.Duration) *Call {
+	satisfied := c.satisfiedChan()
+	go func() {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-satisfied:
+			return
+		case <-timer.C:
+		}
+		if c.ctrl != nil {
+			c.ctrl.mu.Lock()
+			defer c.ctrl.mu.Unlock()
+			if c.ctrl.finished {
+				return
+			}
+		}
+		if !c.satisfied() {
+			c.t.Errorf("Expected call %s wasn't made within %v: %v", c.where(), d, c)
+		}
+	}()
 	return c
 }
 
+// Wait blocks until the call is satisfied, or d has elapsed. It reports
+// whether the call is satisfied.
+func (c *Call) Wait(d time.Duration) bool {
+	satisfied := c.satisfiedChan()
+	select {
+	case <-satisfied:
+		return true
+	default:
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-satisfied:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// satisfiedChan returns a channel that is closed once the call is
+// satisfied.
+func (c *Call) satisfiedChan() <-chan struct{} {
+	if c.ctrl != nil {
+		c.ctrl.mu.Lock()
+		defer c.ctrl.mu.Unlock()
+	}
+	if c.done == nil {
+		c.done = make(chan struct{})
+		c.notifySatisfied()
+	}
+	return c.done
+}
+
+// notifySatisfied closes the channel returned by satisfiedChan if the call
+// has become satisfied.
+func (c *Call) notifySatisfied() {
+	if c.done == nil || !c.satisfied() {
+		return
+	}
+	select {
+	case <-c.done:
+	default:
+		close(c.done)
+	}
+}
+
+// checkNotPanicking reports a fatal error if Panic has been declared for the
+// call, which conflicts with the method named name.
+func (c *Call) checkNotPanicking(name string) bool {
+	c.t.Helper()
+
+	if c.panics {
+		c.t.Fatalf("%s for %T.%v can't be combined with Panic [%s]",
+			name, c.receiver, c.method, c.origin)
+		return false
+	}
+	return true
+}
+
 // Times declares the exact number of times a function call is expected to be executed.
+// It overrides any earlier AnyTimes, MinTimes and MaxTimes.
+// Times(0) forbids the call: a call matching its arguments fails, even if
+// another expectation would match it as well.
 func (c *Call) Times(n int) *Call {
+	if c.ctrl != nil {
+		c.ctrl.mu.Lock()
+		defer c.ctrl.mu.Unlock()
+	}
 	c.minCalls, c.maxCalls = n, n
+	c.minSet, c.maxSet = true, true
 	return c
 }
 
 // SetArg declares an action that will set the nth argument's value,
-// indirected through a pointer. Or, in the case of a slice, SetArg
-// will copy value's elements into the nth argument.
+// indirected through a pointer. In the case of a slice, SetArg copies
+// value's elements into the nth argument, as many as fit, and in the case
+// of a map it adds value's entries to the nth argument. For a variadic
+// method, n may refer to any of the variadic arguments.
 func (c *Call) SetArg(n int, value interface{}) *Call {
-	if h, ok := c.t.(testHelper); ok {
-		h.Helper()
-	}
+	c.t.Helper()
 
 	mt := c.methodType
-	// TODO: This will break on variadic methods.
-	// We will need to check those at invocation time.
-	if n < 0 || n >= mt.NumIn() {
+	if n < 0 || (!mt.IsVariadic() && n >= mt.NumIn()) {
 		c.t.Fatalf("SetArg(%d, ...) called for a method with %d args [%s]",
 			n, mt.NumIn(), c.origin)
+		return c
 	}
+	at := c.argType(n)
 	// Permit setting argument through an interface.
 	// In the interface case, we don't (nay, can't) check the type here.
-	at := mt.In(n)
+	if at.Kind() != reflect.Interface {
+		if err := checkSetArg(at, reflect.TypeOf(value)); err != nil {
+			c.t.Fatalf("SetArg(%d, ...) %v [%s]", n, err, c.origin)
+			return c
+		}
+	}
+
+	c.addAction(func(args []interface{}) []interface{} {
+		c.t.Helper()
+		if n >= len(args) {
+			c.t.Fatalf("SetArg(%d, ...) called for a call with %d args [%s]", n, len(args), c.origin)
+			return nil
+		}
+		va := reflect.ValueOf(args[n])
+		if !va.IsValid() || (va.Kind() == reflect.Ptr && va.IsNil()) {
+			c.t.Fatalf("SetArg(%d, ...) can't write through a nil %T argument [%s]", n, args[n], c.origin)
+			return nil
+		}
+		if err := checkSetArg(va.Type(), reflect.TypeOf(value)); err != nil {
+			c.t.Fatalf("SetArg(%d, ...) %v [%s]", n, err, c.origin)
+			return nil
+		}
+		setArg(va, value)
+		return nil
+	})
+	return c
+}
+
+// argType returns the type of the nth argument of a call, taking the
+// variadic arguments into account.
+func (c *Call) argType(n int) reflect.Type {
+	mt := c.methodType
+	if mt.IsVariadic() && n >= mt.NumIn()-1 {
+		return mt.In(mt.NumIn() - 1).Elem()
+	}
+	return mt.In(n)
+}
+
+// checkSetArg checks that a value of type vt can be written through an
+// argument of type at by SetArg.
+func checkSetArg(at, vt reflect.Type) error {
 	switch at.Kind() {
 	case reflect.Ptr:
 		dt := at.Elem()
-		if vt := reflect.TypeOf(value); !vt.AssignableTo(dt) {
-			c.t.Fatalf("SetArg(%d, ...) argument is a %v, not assignable to %v [%s]",
-				n, vt, dt, c.origin)
+		if vt == nil {
+			switch dt.Kind() {
+			case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+				return nil
+			}
+			return fmt.Errorf("argument is nil, but %v is not nillable", dt)
+		}
+		if !vt.AssignableTo(dt) {
+			return fmt.Errorf("argument is a %v, not assignable to %v", vt, dt)
 		}
-	case reflect.Interface:
-		// nothing to do
 	case reflect.Slice:
-		// nothing to do
+		if vt == nil || (vt.Kind() != reflect.Slice && vt.Kind() != reflect.Array) || !vt.Elem().AssignableTo(at.Elem()) {
+			return fmt.Errorf("argument is a %v, not a slice or array of elements assignable to %v", vt, at.Elem())
+		}
+	case reflect.Map:
+		if vt == nil || vt.Kind() != reflect.Map || !vt.Key().AssignableTo(at.Key()) || !vt.Elem().AssignableTo(at.Elem()) {
+			return fmt.Errorf("argument is a %v, not a map assignable to %v", vt, at)
+		}
 	default:
-		c.t.Fatalf("SetArg(%d, ...) referring to argument of non-pointer non-interface non-slice type %v [%s]",
-			n, at, c.origin)
+		return fmt.Errorf("referring to argument of non-pointer non-interface non-slice non-map type %v", at)
 	}
+	return nil
+}
 
-	c.addAction(func(args []interface{}) []interface{} {
-		v := reflect.ValueOf(value)
-		switch reflect.TypeOf(args[n]).Kind() {
-		case reflect.Slice:
-			setSlice(args[n], v)
-		default:
-			reflect.ValueOf(args[n]).Elem().Set(v)
+// setArg writes value through arg, which checkSetArg has accepted.
+func setArg(arg reflect.Value, value interface{}) {
+	v := reflect.ValueOf(value)
+	switch arg.Kind() {
+	case reflect.Slice:
+		setSlice(arg, v)
+	case reflect.Map:
+		if arg.IsNil() {
+			// There is nothing to add entries to; the caller would not
+			// see them anyway.
+			return
 		}
-		return nil
-	})
-	return c
+		for _, k := range v.MapKeys() {
+			arg.SetMapIndex(k, v.MapIndex(k))
+		}
+	default:
+		if !v.IsValid() {
+			v = reflect.Zero(arg.Type().Elem())
+		}
+		arg.Elem().Set(v)
+	}
 }
 
 // isPreReq returns true if other is a direct or indirect prerequisite to c.
+// Each call is visited at most once, so this is linear in the number of
+// prerequisite edges.
 func (c *Call) isPreReq(other *Call) bool {
-	for _, preReq := range c.preReqs {
-		if other == preReq || preReq.isPreReq(other) {
+	visited := make(map[*Call]bool)
+	stack := append([]*Call(nil), c.preReqs...)
+	for len(stack) > 0 {
+		preReq := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if preReq == other {
 			return true
 		}
+		if visited[preReq] {
+			continue
+		}
+		visited[preReq] = true
+		stack = append(stack, preReq.preReqs...)
 	}
 	return false
 }
 
+// Prereqs returns the calls declared with After, or InOrder, that the call
+// still waits for. The returned slice is a snapshot.
+func (c *Call) Prereqs() []*Call {
+	if c.ctrl != nil {
+		c.ctrl.mu.Lock()
+		defer c.ctrl.mu.Unlock()
+	}
+	return append([]*Call(nil), c.preReqs...)
+}
+
 // After declares that the call may only match after preReq has been exhausted.
 func (c *Call) After(preReq *Call) *Call {
-	if h, ok := c.t.(testHelper); ok {
-		h.Helper()
+	c.t.Helper()
+
+	if c.ctrl != nil {
+		c.ctrl.mu.Lock()
+		defer c.ctrl.mu.Unlock()
 	}
 
 	if c == preReq {
-		c.t.Fatalf("A call isn't allowed to be its own prerequisite")
+		c.t.Fatalf("A call isn't allowed to be its own prerequisite: %v", c)
+		return c
+	}
+	if c.ctrl != nil && preReq.ctrl != nil && c.ctrl.controllerState != preReq.ctrl.controllerState {
+		c.t.Fatalf("%v can't be called after %v, which belongs to a different Controller", c, preReq)
+		return c
 	}
 	if preReq.isPreReq(c) {
-		c.t.Fatalf("Loop in call order: %v is a prerequisite to %v (possibly indirectly).", c, preReq)
+		c.t.Fatalf("Loop in call order: %v is a prerequisite to %v (possibly indirectly), so it can't be called after it.", c, preReq)
+		return c
 	}
 
 	c.preReqs = append(c.preReqs, preReq)
 	return c
 }
 
+// AnyOrder exempts the call from sequencing: it matches regardless of its
+// prerequisites, and calls that come after it, e.g. in InOrder, only wait
+// for the calls before it. This is useful for logging or metrics calls that
+// may happen at any point of an ordered sequence.
+//
+//	gomock.InOrder(
+//		mockConn.EXPECT().Open(),
+//		mockMetrics.EXPECT().Inc("requests").AnyTimes().AnyOrder(),
+//		mockConn.EXPECT().Close(),
+//	)
+func (c *Call) AnyOrder() *Call {
+	if c.ctrl != nil {
+		c.ctrl.mu.Lock()
+		defer c.ctrl.mu.Unlock()
+	}
+	c.anyOrder = true
+	return c
+}
+
+// AfterAll declares that the call may only match after all of preReqs have
+// been exhausted, in any order among themselves. It is the same as calling
+// After for each of them.
+//
+//	openA := mockFile.EXPECT().Open("a")
+//	openB := mockFile.EXPECT().Open("b")
+//	mockFile.EXPECT().CloseAll().AfterAll(openA, openB)
+func (c *Call) AfterAll(preReqs ...*Call) *Call {
+	c.t.Helper()
+
+	for _, preReq := range preReqs {
+		c.After(preReq)
+	}
+	return c
+}
+
 // Returns true if the minimum number of calls have been made.
 func (c *Call) satisfied() bool {
 	return c.numCalls >= c.minCalls
@@ -289,126 +982,460 @@ func (c *Call) exhausted() bool {
 	return c.numCalls >= c.maxCalls
 }
 
+// preferredTo reports whether the call is to be matched rather than other,
+// another call matching the same arguments, which was recorded before it.
+func (c *Call) preferredTo(other *Call) bool {
+	if c.byDefault != other.byDefault {
+		return other.byDefault
+	}
+	return c.specificity() > other.specificity()
+}
+
+// specificity is the number of the call's matchers that don't match any
+// argument, which FindMatch uses to choose between matching calls.
+func (c *Call) specificity() int {
+	n := 0
+	for _, m := range c.args {
+		if cp, ok := m.(*Captor); ok {
+			m = cp.m
+		}
+		switch m.(type) {
+		case anyMatcher, restAnyMatcher:
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// forbidden reports whether the call must not be made at all, as declared
+// with Times(0) or MaxTimes(0).
+func (c *Call) forbidden() bool {
+	return c.maxCalls == 0
+}
+
+// String describes the expected call, its matchers, where it was recorded
+// and how many calls it expects and has seen.
 func (c *Call) String() string {
+	return fmt.Sprintf("%s registered at %s, expected %s calls, got %d",
+		c.signature(), c.origin, c.cardinality(), c.numCalls)
+}
+
+// signature describes the method and the matchers of the call, and its
+// label, e.g. `*pkg.Mock.Get(is equal to 1) named "first get"`.
+func (c *Call) signature() string {
 	args := make([]string, len(c.args))
 	for i, arg := range c.args {
 		args[i] = arg.String()
 	}
 	arguments := strings.Join(args, ", ")
-	return fmt.Sprintf("%T.%v(%s) %s", c.receiver, c.method, arguments, c.origin)
+	name := ""
+	if c.name != "" {
+		name = fmt.Sprintf(" named %q", c.name)
+	}
+	return fmt.Sprintf("%s.%v(%s)%s", c.receiverName(), c.method, arguments, name)
+}
+
+// receiverName describes the receiver of the call, with the function set
+// with WithNameForReceiver if there is one.
+func (c *Call) receiverName() string {
+	if c.ctrl != nil {
+		return c.ctrl.receiverName(c.receiver)
+	}
+	return fmt.Sprintf("%T", c.receiver)
+}
+
+// where locates the call for error messages, e.g. `at file.go:42` or
+// `"first get" at file.go:42` for a call with a name.
+func (c *Call) where() string {
+	if c.name != "" {
+		return fmt.Sprintf("%q at %s", c.name, c.origin)
+	}
+	return "at " + c.origin
+}
+
+// cardinality describes the number of calls the expectation allows, e.g.
+// "1..1" or "2..unlimited".
+func (c *Call) cardinality() string {
+	if c.maxCalls >= 1e8 {
+		return fmt.Sprintf("%d..unlimited", c.minCalls)
+	}
+	return fmt.Sprintf("%d..%d", c.minCalls, c.maxCalls)
 }
 
 // Tests if the given call matches the expected call.
-// If yes, returns nil. If no, returns error with message explaining why it does not match.
-func (c *Call) matches(args []interface{}) error {
+// If yes, returns nil. If no, returns a mismatch explaining why it does not match.
+func (c *Call) matches(args []interface{}) *callMismatch {
+	if m := c.matchArgs(args); m != nil {
+		return m
+	}
+
+	// Check that all prerequisite calls have been satisfied.
+	for _, preReqCall := range c.effectivePreReqs() {
+		if !preReqCall.satisfied() {
+			return c.mismatch(missingPrereq, -1, "doesn't have a prerequisite call satisfied:\n%v\nshould be called before:\n%v",
+				preReqCall, c)
+		}
+	}
+
+	// With WithStrictOrder, check that the calls recorded before have been
+	// satisfied.
+	if prev := c.orderedPrev(); prev != nil && !prev.satisfied() {
+		next := prev
+		for p := prev; p != nil; p = p.strictPrev {
+			if !p.unordered() && !p.satisfied() {
+				next = p
+			}
+		}
+		return c.mismatch(missingPrereq, -1, "is out of order, the call expected next is:\n%v", next)
+	}
+
+	// Check that the call is not exhausted.
+	if c.exhausted() {
+		return c.mismatch(exhaustedCall, -1, "has already been called the max number of times (%d).", c.maxCalls)
+	}
+
+	return nil
+}
+
+// acceptsCaller reports whether the call may match a call made with the
+// controllers acting for caller, see WithCallerIsolation. A caller of 0
+// stands for any.
+func (c *Call) acceptsCaller(caller uint64) bool {
+	return c.caller == 0 || caller == 0 || c.caller == caller
+}
+
+// matchesFrom is like matches, for a call made with the controllers acting
+// for caller.
+func (c *Call) matchesFrom(caller uint64, args []interface{}) *callMismatch {
+	if !c.acceptsCaller(caller) {
+		if m := c.matchArgs(args); m != nil {
+			return m
+		}
+		return c.mismatch(otherCaller, -1, "was recorded with another controller, which only the calls made with it, or with the handles its Go passes, may match.")
+	}
+	return c.matches(args)
+}
+
+// matchArgs tests if the arguments of the given call match the expected
+// call's.
+func (c *Call) matchArgs(args []interface{}) *callMismatch {
 	if !c.methodType.IsVariadic() {
 		if len(args) != len(c.args) {
-			return fmt.Errorf("Expected call at %s has the wrong number of arguments. Got: %d, want: %d",
-				c.origin, len(args), len(c.args))
+			return c.mismatch(wrongArgs, -1, "has the wrong number of arguments. Got: %d, want: %d",
+				len(args), len(c.args))
 		}
 
 		for i, m := range c.args {
 			if !m.Matches(args[i]) {
-				return fmt.Errorf("Expected call at %s doesn't match the argument at index %s.\nGot: %v\nWant: %v",
-					c.origin, strconv.Itoa(i), args[i], m)
+				return c.argMismatch(i, m, args[i])
 			}
 		}
-	} else {
-		if len(c.args) < c.methodType.NumIn()-1 {
-			return fmt.Errorf("Expected call at %s has the wrong number of matchers. Got: %d, want: %d",
-				c.origin, len(c.args), c.methodType.NumIn()-1)
+	} else if c.endsWithRestAny() {
+		n := len(c.args) - 1
+		if len(args) < n {
+			return c.mismatch(wrongArgs, -1, "has the wrong number of arguments. Got: %d, want: greater than or equal to %d",
+				len(args), n)
 		}
-		if len(c.args) != c.methodType.NumIn() && len(args) != len(c.args) {
-			return fmt.Errorf("Expected call at %s has the wrong number of arguments. Got: %d, want: %d",
-				c.origin, len(args), len(c.args))
-		}
-		if len(args) < len(c.args)-1 {
-			return fmt.Errorf("Expected call at %s has the wrong number of arguments. Got: %d, want: greater than or equal to %d",
-				c.origin, len(args), len(c.args)-1)
+		for i, m := range c.args[:n] {
+			if !m.Matches(args[i]) {
+				return c.argMismatch(i, m, args[i])
+			}
 		}
+	} else if m := c.matchesVariadic(args); m != nil {
+		return m
+	}
+	return nil
+}
 
-		for i, m := range c.args {
-			if i < c.methodType.NumIn()-1 {
-				// Non-variadic args
-				if !m.Matches(args[i]) {
-					return fmt.Errorf("Expected call at %s doesn't match the argument at index %s.\nGot: %v\nWant: %v",
-						c.origin, strconv.Itoa(i), args[i], m)
-				}
-				continue
-			}
-			// The last arg has a possibility of a variadic argument, so let it branch
-
-			// sample: Foo(a int, b int, c ...int)
-			if len(c.args) == len(args) {
-				if m.Matches(args[i]) {
-					// Got Foo(a, b, c) want Foo(matcherA, matcherB, gomock.Any())
-					// Got Foo(a, b, c) want Foo(matcherA, matcherB, someSliceMatcher)
-					// Got Foo(a, b, c) want Foo(matcherA, matcherB, matcherC)
-					// Got Foo(a, b) want Foo(matcherA, matcherB)
-					// Got Foo(a, b, c, d) want Foo(matcherA, matcherB, matcherC, matcherD)
-					break
-				}
-			}
+// matchesVariadic matches the arguments of a call to a variadic method.
+// The matchers for the variadic arguments may either match them one by one,
+// or, if there is a single one, match all of them packed into a slice:
+//
+//	Got Foo(a, b, c, d) want Foo(matcherA, matcherB, matcherC, matcherD)
+//	Got Foo(a, b, c, d) want Foo(matcherA, matcherB, sliceMatcher)
+//	Got Foo(a, b) want Foo(matcherA, matcherB, emptySliceMatcher)
+//	Got Foo(a, b) want Foo(matcherA, matcherB)
+//
+// for Foo(a int, b int, c ...int). The one by one interpretation is tried
+// first.
+func (c *Call) matchesVariadic(args []interface{}) *callMismatch {
+	n := c.methodType.NumIn() - 1
+	if len(c.args) < n {
+		return c.mismatch(wrongArgs, -1, "has the wrong number of matchers. Got: %d, want: %d",
+			len(c.args), n)
+	}
+	if len(args) < n {
+		return c.mismatch(wrongArgs, -1, "has the wrong number of arguments. Got: %d, want: greater than or equal to %d",
+			len(args), n)
+	}
+	for i, m := range c.args[:n] {
+		if !m.Matches(args[i]) {
+			return c.argMismatch(i, m, args[i])
+		}
+	}
 
-			// The number of actual args don't match the number of matchers,
-			// or the last matcher is a slice and the last arg is not.
-			// If this function still matches it is because the last matcher
-			// matches all the remaining arguments or the lack of any.
-			// Convert the remaining arguments, if any, into a slice of the
-			// expected type.
-			vargsType := c.methodType.In(c.methodType.NumIn() - 1)
-			vargs := reflect.MakeSlice(vargsType, 0, len(args)-i)
-			for _, arg := range args[i:] {
-				vargs = reflect.Append(vargs, reflect.ValueOf(arg))
-			}
-			if m.Matches(vargs.Interface()) {
-				// Got Foo(a, b, c, d, e) want Foo(matcherA, matcherB, gomock.Any())
-				// Got Foo(a, b, c, d, e) want Foo(matcherA, matcherB, someSliceMatcher)
-				// Got Foo(a, b) want Foo(matcherA, matcherB, gomock.Any())
-				// Got Foo(a, b) want Foo(matcherA, matcherB, someEmptySliceMatcher)
+	vmatchers, vargs := c.args[n:], args[n:]
+	var mismatch *callMismatch
+	if len(vmatchers) == len(vargs) {
+		for i, m := range vmatchers {
+			if !m.Matches(vargs[i]) {
+				mismatch = c.argMismatch(n+i, m, vargs[i])
 				break
 			}
-			// Wrong number of matchers or not match. Fail.
-			// Got Foo(a, b) want Foo(matcherA, matcherB, matcherC, matcherD)
-			// Got Foo(a, b, c) want Foo(matcherA, matcherB, matcherC, matcherD)
-			// Got Foo(a, b, c, d) want Foo(matcherA, matcherB, matcherC, matcherD, matcherE)
-			// Got Foo(a, b, c, d, e) want Foo(matcherA, matcherB, matcherC, matcherD)
-			// Got Foo(a, b, c) want Foo(matcherA, matcherB)
-			return fmt.Errorf("Expected call at %s doesn't match the argument at index %s.\nGot: %v\nWant: %v",
-				c.origin, strconv.Itoa(i), args[i:], c.args[i])
-
 		}
+		if mismatch == nil {
+			return nil
+		}
+	}
+	if len(vmatchers) == 1 {
+		if vmatchers[0].Matches(c.packVariadic(args, n)) {
+			return nil
+		}
+		if mismatch == nil {
+			mismatch = c.argMismatch(n, vmatchers[0], vargs)
+		}
+	}
+	if mismatch == nil {
+		mismatch = c.mismatch(wrongArgs, -1, "has the wrong number of arguments. Got: %d, want: %d",
+			len(args), len(c.args))
 	}
+	return mismatch
+}
 
-	// Check that all prerequisite calls have been satisfied.
-	for _, preReqCall := range c.preReqs {
-		if !preReqCall.satisfied() {
-			return fmt.Errorf("Expected call at %s doesn't have a prerequisite call satisfied:\n%v\nshould be called before:\n%v",
-				c.origin, preReqCall, c)
+// argMismatch returns the mismatch reported when m doesn't match arg, the
+// argument at index i.
+func (c *Call) argMismatch(i int, m Matcher, arg interface{}) *callMismatch {
+	describe := func() string {
+		msg := fmt.Sprintf("Expected call %s doesn't match the argument at index %d.\nGot: %s\nWant: %v",
+			c.where(), i, formatGottenArg(m, arg), m)
+		if d, ok := m.(differ); ok {
+			if diff := d.diff(arg); diff != "" {
+				msg += "\nDiff:\n" + diff
+			}
 		}
+		return msg
 	}
+	return &callMismatch{call: c, kind: wrongArgs, argIndex: i, describe: describe}
+}
 
-	// Check that the call is not exhausted.
-	if c.exhausted() {
-		return fmt.Errorf("Expected call at %s has already been called the max number of times.", c.origin)
+// mismatch returns a mismatch of the given kind, described by format and
+// args, after the location of the call, e.g. "Expected call at file.go:42
+// has ...".
+func (c *Call) mismatch(kind mismatchKind, argIndex int, format string, args ...interface{}) *callMismatch {
+	describe := func() string { return "Expected call " + c.where() + " " + fmt.Sprintf(format, args...) }
+	return &callMismatch{call: c, kind: kind, argIndex: argIndex, describe: describe}
+}
+
+// formatGottenArg renders an argument which m failed to match, using m's
+// GotFormatter if it has one.
+func formatGottenArg(m Matcher, arg interface{}) string {
+	if gf, ok := m.(GotFormatter); ok {
+		return gf.Got(arg)
 	}
+	return FormatValue(arg)
+}
 
-	return nil
+// replacePreReq replaces preReq, if it is a prerequisite of the call, by
+// its own prerequisites. The same goes for the call it has to follow with
+// WithStrictOrder.
+func (c *Call) replacePreReq(preReq *Call) {
+	if c.strictPrev == preReq {
+		c.strictPrev = preReq.strictPrev
+	}
+	for i, p := range c.preReqs {
+		if p == preReq {
+			preReqs := append(c.preReqs[:i:i], c.preReqs[i+1:]...)
+			c.preReqs = append(preReqs, preReq.preReqs...)
+			return
+		}
+	}
 }
 
 // dropPrereqs tells the expected Call to not re-check prerequisite calls any
 // longer, and to return its current set.
 func (c *Call) dropPrereqs() (preReqs []*Call) {
-	preReqs = c.preReqs
+	preReqs = c.effectivePreReqs()
+	if prev := c.orderedPrev(); prev != nil {
+		preReqs = append(preReqs, prev)
+		c.strictPrev = nil
+	}
 	c.preReqs = nil
 	return
 }
 
-func (c *Call) call(args []interface{}) []func([]interface{}) []interface{} {
+// orderedPrev returns the call the call has to be made after because of
+// WithStrictOrder, if any: the last one recorded before it which isn't
+// exempt from the order.
+func (c *Call) orderedPrev() *Call {
+	if c.unordered() {
+		return nil
+	}
+	p := c.strictPrev
+	for p != nil && p.unordered() {
+		p = p.strictPrev
+	}
+	return p
+}
+
+// unordered reports whether the call is exempt from the order of
+// WithStrictOrder: calls expected any number of times, and those recorded
+// with Controller.Default or marked with AnyOrder, are.
+func (c *Call) unordered() bool {
+	return c.anyOrder || c.byDefault || c.maxCalls >= 1e8
+}
+
+// effectivePreReqs returns the prerequisites the call has to wait for. A
+// prerequisite marked with AnyOrder is replaced by its own prerequisites,
+// so that it doesn't break a sequence it is part of.
+func (c *Call) effectivePreReqs() []*Call {
+	if c.anyOrder {
+		return nil
+	}
+	var preReqs []*Call
+	visited := make(map[*Call]bool)
+	stack := append([]*Call(nil), c.preReqs...)
+	for len(stack) > 0 {
+		preReq := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[preReq] {
+			continue
+		}
+		visited[preReq] = true
+		if preReq.anyOrder {
+			stack = append(stack, preReq.preReqs...)
+			continue
+		}
+		preReqs = append(preReqs, preReq)
+	}
+	return preReqs
+}
+
+// packVariadic converts args[i:] into a slice of the type of the method's
+// variadic parameter.
+func (c *Call) packVariadic(args []interface{}, i int) interface{} {
+	vargsType := c.methodType.In(c.methodType.NumIn() - 1)
+	vargs := reflect.MakeSlice(vargsType, 0, len(args)-i)
+	for _, arg := range args[i:] {
+		if arg == nil {
+			vargs = reflect.Append(vargs, reflect.Zero(vargsType.Elem()))
+			continue
+		}
+		vargs = reflect.Append(vargs, reflect.ValueOf(arg))
+	}
+	return vargs.Interface()
+}
+
+func (c *Call) call(receiver interface{}, args []interface{}, seq uint64) []func([]interface{}) []interface{} {
 	c.numCalls++
+	c.notifySatisfied()
+	c.captureArgs(args)
+	c.recordInvocation(receiver, args, seq)
 	return c.actions
 }
 
+// A CallRecord describes an invocation matched by a Call.
+type CallRecord struct {
+	Receiver interface{}   // the receiver of the invocation
+	Method   string        // the name of the method
+	Args     []interface{} // the arguments of the invocation
+	Seq      uint64        // the invocation's position among all of the controller's calls, from 1
+	Time     time.Time     // when the invocation was made
+}
+
+func (c *Call) recordInvocation(receiver interface{}, args []interface{}, seq uint64) {
+	if c.keptInvocations <= 0 {
+		return
+	}
+	r := newCallRecord(receiver, c.method, args, seq)
+	if len(c.invocations) < c.keptInvocations {
+		c.invocations = append(c.invocations, r)
+		return
+	}
+	c.invocations[c.oldest] = r
+	c.oldest = (c.oldest + 1) % len(c.invocations)
+}
+
+// newCallRecord records a call with args, which it keeps rather than copies:
+// the arguments passed to Controller.Call aren't changed afterwards, and the
+// records are copied when they are handed out.
+func newCallRecord(receiver interface{}, method string, args []interface{}, seq uint64) CallRecord {
+	return CallRecord{
+		Receiver: receiver,
+		Method:   method,
+		Args:     args,
+		Seq:      seq,
+		Time:     time.Now(),
+	}
+}
+
+// Invocations returns records of the invocations matched by the call so
+// far, oldest first. Only the most recent ones are kept, 1000 unless
+// changed with KeepInvocations. It is safe to call Invocations while the
+// mock is in use.
+func (c *Call) Invocations() []CallRecord {
+	if c.ctrl != nil {
+		c.ctrl.mu.Lock()
+		defer c.ctrl.mu.Unlock()
+	}
+	records := make([]CallRecord, 0, len(c.invocations))
+	for _, rs := range [][]CallRecord{c.invocations[c.oldest:], c.invocations[:c.oldest]} {
+		for _, r := range rs {
+			r.Args = append([]interface{}(nil), r.Args...)
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// KeepInvocations sets the number of invocation records the call keeps.
+// A value of 0 disables the records.
+func (c *Call) KeepInvocations(n int) *Call {
+	if c.ctrl != nil {
+		c.ctrl.mu.Lock()
+		defer c.ctrl.mu.Unlock()
+	}
+	if n < 0 {
+		n = 0
+	}
+	c.keptInvocations = n
+	// Keep the most recent records, oldest first.
+	invocations := append(append([]CallRecord(nil), c.invocations[c.oldest:]...), c.invocations[:c.oldest]...)
+	if len(invocations) > n {
+		invocations = invocations[len(invocations)-n:]
+	}
+	c.invocations, c.oldest = invocations, 0
+	return c
+}
+
+// captureArgs hands the arguments of a matched call to the matchers that
+// record them.
+func (c *Call) captureArgs(args []interface{}) {
+	for i, m := range c.args {
+		ac, ok := m.(argCapturer)
+		if !ok {
+			continue
+		}
+		mt := c.methodType
+		if mt.IsVariadic() && i == mt.NumIn()-1 && !c.endsWithRestAny() && !(len(args) == len(c.args) && m.Matches(args[i])) {
+			// The matcher matched the packed variadic arguments.
+			ac.captureArg(c.packVariadic(args, i))
+		} else if i < len(args) {
+			ac.captureArg(args[i])
+		}
+	}
+}
+
+// endsWithRestAny reports whether the last expected argument is RestAny,
+// which newCall only allows for variadic methods.
+func (c *Call) endsWithRestAny() bool {
+	if len(c.args) == 0 {
+		return false
+	}
+	_, ok := c.args[len(c.args)-1].(restAnyMatcher)
+	return ok
+}
+
 // InOrder declares that the given calls should occur in order.
 func InOrder(calls ...*Call) {
 	for i := 1; i < len(calls); i++ {
@@ -416,9 +1443,9 @@ func InOrder(calls ...*Call) {
 	}
 }
 
-func setSlice(arg interface{}, v reflect.Value) {
-	va := reflect.ValueOf(arg)
-	for i := 0; i < v.Len(); i++ {
+// setSlice copies the elements of v into va, as many as fit.
+func setSlice(va, v reflect.Value) {
+	for i := 0; i < v.Len() && i < va.Len(); i++ {
 		va.Index(i).Set(v.Index(i))
 	}
 }
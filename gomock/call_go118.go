@@ -0,0 +1,145 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package gomock
+
+// This file contains typed wrappers of Call, which use type parameters, which
+// were added in Go 1.18.
+//
+// A typed call is parameterized by F, the function type of the mocked
+// method, and by its return types, so that the arguments to Do, DoAndReturn
+// and Return are checked at compile time:
+//
+//	// For Get(key string) (string, error):
+//	call := &gomock.Call2[func(string) (string, error), string, error]{
+//		Call: ctrl.RecordCall(m, "Get", "key"),
+//	}
+//	call.Return("value", nil)
+//
+// Mocks may return typed calls from their recorders. Other methods, like
+// Times, are those of the embedded *Call.
+
+// A Call0 is a typed Call of a method without return values.
+type Call0[F any] struct {
+	*Call
+}
+
+// Do declares the action to run when the call is matched.
+func (c *Call0[F]) Do(f F) *Call0[F] {
+	c.Call.t.Helper()
+
+	c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn declares the action to run when the call is matched.
+func (c *Call0[F]) DoAndReturn(f F) *Call0[F] {
+	c.Call.t.Helper()
+
+	c.Call.DoAndReturn(f)
+	return c
+}
+
+// A Call1 is a typed Call of a method returning a single value.
+type Call1[F, R1 any] struct {
+	*Call
+}
+
+// Do declares the action to run when the call is matched.
+func (c *Call1[F, R1]) Do(f F) *Call1[F, R1] {
+	c.Call.t.Helper()
+
+	c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn declares the action to run when the call is matched, which
+// returns the value returned by the mocked method.
+func (c *Call1[F, R1]) DoAndReturn(f F) *Call1[F, R1] {
+	c.Call.t.Helper()
+
+	c.Call.DoAndReturn(f)
+	return c
+}
+
+// Return declares the value to be returned by the mocked method.
+func (c *Call1[F, R1]) Return(r1 R1) *Call1[F, R1] {
+	c.Call.t.Helper()
+
+	c.Call.Return(r1)
+	return c
+}
+
+// A Call2 is a typed Call of a method returning two values.
+type Call2[F, R1, R2 any] struct {
+	*Call
+}
+
+// Do declares the action to run when the call is matched.
+func (c *Call2[F, R1, R2]) Do(f F) *Call2[F, R1, R2] {
+	c.Call.t.Helper()
+
+	c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn declares the action to run when the call is matched, which
+// returns the values returned by the mocked method.
+func (c *Call2[F, R1, R2]) DoAndReturn(f F) *Call2[F, R1, R2] {
+	c.Call.t.Helper()
+
+	c.Call.DoAndReturn(f)
+	return c
+}
+
+// Return declares the values to be returned by the mocked method.
+func (c *Call2[F, R1, R2]) Return(r1 R1, r2 R2) *Call2[F, R1, R2] {
+	c.Call.t.Helper()
+
+	c.Call.Return(r1, r2)
+	return c
+}
+
+// A Call3 is a typed Call of a method returning three values.
+type Call3[F, R1, R2, R3 any] struct {
+	*Call
+}
+
+// Do declares the action to run when the call is matched.
+func (c *Call3[F, R1, R2, R3]) Do(f F) *Call3[F, R1, R2, R3] {
+	c.Call.t.Helper()
+
+	c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn declares the action to run when the call is matched, which
+// returns the values returned by the mocked method.
+func (c *Call3[F, R1, R2, R3]) DoAndReturn(f F) *Call3[F, R1, R2, R3] {
+	c.Call.t.Helper()
+
+	c.Call.DoAndReturn(f)
+	return c
+}
+
+// Return declares the values to be returned by the mocked method.
+func (c *Call3[F, R1, R2, R3]) Return(r1 R1, r2 R2, r3 R3) *Call3[F, R1, R2, R3] {
+	c.Call.t.Helper()
+
+	c.Call.Return(r1, r2, r3)
+	return c
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package gomock_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestTypedCalls(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	s := new(Subject)
+
+	foo := &gomock.Call1[func(string) int, int]{Call: ctrl.RecordCall(s, "FooMethod", "a")}
+	foo.Return(1).Times(2)
+	get := &gomock.Call2[func(int) (string, error), string, error]{Call: ctrl.RecordCall(s, "GetMethod", 1)}
+	get.Return("one", nil)
+	get2 := &gomock.Call2[func(int) (string, error), string, error]{Call: ctrl.RecordCall(s, "GetMethod", 2)}
+	get2.DoAndReturn(func(n int) (string, error) {
+		return "", fmt.Errorf("no %d", n)
+	})
+	var got []string
+	set := &gomock.Call0[func([]byte, *int)]{Call: ctrl.RecordCall(s, "SetArgMethod", gomock.Any(), gomock.Any())}
+	set.Do(func(b []byte, _ *int) {
+		got = append(got, string(b))
+	})
+
+	assertEqual(t, []interface{}{1}, ctrl.Call(s, "FooMethod", "a"))
+	assertEqual(t, []interface{}{1}, ctrl.Call(s, "FooMethod", "a"))
+	assertEqual(t, []interface{}{"one", nil}, ctrl.Call(s, "GetMethod", 1))
+	rets := ctrl.Call(s, "GetMethod", 2)
+	if err, ok := rets[1].(error); !ok || err.Error() != "no 2" {
+		t.Errorf("GetMethod(2) returned %v, want the error %q", rets, "no 2")
+	}
+	ctrl.Call(s, "SetArgMethod", []byte("x"), (*int)(nil))
+	assertEqual(t, []string{"x"}, got)
+	ctrl.Finish()
+}
+
+func TestTypedCallHelperCalls(t *testing.T) {
+	rep := &helperReporter{NewErrorReporter(t), make(map[string]bool)}
+	defer rep.recoverUnexpectedFatal()
+	ctrl := gomock.NewController(rep)
+	s := new(Subject)
+
+	(&gomock.Call0[func([]byte, *int)]{Call: ctrl.RecordCall(s, "SetArgMethod", gomock.Any(), gomock.Any())}).Do(func([]byte, *int) {})
+	(&gomock.Call1[func(string) int, int]{Call: ctrl.RecordCall(s, "FooMethod", "a")}).DoAndReturn(func(string) int { return 1 })
+	(&gomock.Call2[func(int) (string, error), string, error]{Call: ctrl.RecordCall(s, "GetMethod", 1)}).Return("one", nil)
+	ctrl.Call(s, "SetArgMethod", []byte("x"), (*int)(nil))
+	ctrl.Call(s, "FooMethod", "a")
+	ctrl.Call(s, "GetMethod", 1)
+	ctrl.Finish()
+
+	// Setup failures point at the test, not at the typed wrappers.
+	for _, f := range []string{"(*Call0[...]).Do", "(*Call1[...]).DoAndReturn", "(*Call2[...]).Return"} {
+		if name := "github.com/golang/mock/gomock." + f; !rep.helpers[name] {
+			t.Errorf("%s didn't call Helper, only %v did", name, rep.helpers)
+		}
+	}
+}
+
+func TestTypedCallMismatchedMethod(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	s := new(Subject)
+
+	// The types of a typed call aren't checked against the method until an
+	// action is declared.
+	call := &gomock.Call1[func(int) int, int]{Call: ctrl.RecordCall(s, "FooMethod", "a").AnyTimes()}
+	rep.assertFatal(func() {
+		call.DoAndReturn(func(int) int { return 0 })
+	}, "wrong type of argument 0 of the func passed to DoAndReturn")
+	ctrl.Finish()
+}
+
+func ExampleCall2() {
+	var t gomock.TestReporter
+	ctrl := gomock.NewController(t)
+	s := new(Subject)
+
+	call := &gomock.Call2[func(int) (string, error), string, error]{Call: ctrl.RecordCall(s, "GetMethod", 1)}
+	call.Return("one", nil)
+	// Unlike with an untyped *Call, the types of the values are checked by
+	// the compiler:
+	//
+	//	call.Return(1, nil) // cannot use 1 (untyped int constant) as string value
+	//	call.Return("one")  // not enough arguments in call to call.Return
+}
@@ -49,3 +49,80 @@ func TestCall_After(t *testing.T) {
 		}
 	})
 }
+
+func TestCall_Cardinality(t *testing.T) {
+	const inf = 1e8
+	tests := []struct {
+		name     string
+		set      func(c *Call)
+		min, max int
+	}{
+		{"Default", func(c *Call) {}, 1, 1},
+		{"Times", func(c *Call) { c.Times(3) }, 3, 3},
+		{"AnyTimes", func(c *Call) { c.AnyTimes() }, 0, inf},
+		{"MinTimes", func(c *Call) { c.MinTimes(2) }, 2, inf},
+		{"MinTimes1", func(c *Call) { c.MinTimes(1) }, 1, inf},
+		{"MaxTimes", func(c *Call) { c.MaxTimes(2) }, 0, 2},
+		{"MaxTimes1", func(c *Call) { c.MaxTimes(1) }, 0, 1},
+		{"MinTimesMaxTimes", func(c *Call) { c.MinTimes(1).MaxTimes(3) }, 1, 3},
+		{"MaxTimesMinTimes", func(c *Call) { c.MaxTimes(1).MinTimes(1) }, 1, 1},
+		{"MinTimesAnyTimes", func(c *Call) { c.MinTimes(2).AnyTimes() }, 2, inf},
+		{"AnyTimesMinTimes", func(c *Call) { c.AnyTimes().MinTimes(2) }, 2, inf},
+		{"AnyTimesMaxTimes", func(c *Call) { c.AnyTimes().MaxTimes(2) }, 0, 2},
+		{"MaxTimesAnyTimes", func(c *Call) { c.MaxTimes(2).AnyTimes() }, 0, inf},
+		{"TimesAnyTimes", func(c *Call) { c.Times(2).AnyTimes() }, 2, inf},
+		{"AnyTimesTimes", func(c *Call) { c.AnyTimes().Times(2) }, 2, 2},
+		{"MinTimesMaxTimesTimes", func(c *Call) { c.MinTimes(1).MaxTimes(5).Times(3) }, 3, 3},
+		{"TimesMaxTimes", func(c *Call) { c.Times(3).MaxTimes(5) }, 3, 5},
+		{"Times0", func(c *Call) { c.Times(0) }, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Call{minCalls: 1, maxCalls: 1}
+			tt.set(c)
+			if c.minCalls != tt.min || c.maxCalls != tt.max {
+				t.Fatalf("min, max calls == %d, %d, want %d, %d", c.minCalls, c.maxCalls, tt.min, tt.max)
+			}
+
+			for c.numCalls = 0; c.numCalls <= tt.max && c.numCalls < 5; c.numCalls++ {
+				if got, want := c.satisfied(), c.numCalls >= tt.min; got != want {
+					t.Errorf("after %d calls, satisfied() == %v, want %v", c.numCalls, got, want)
+				}
+				if got, want := c.exhausted(), c.numCalls >= tt.max; got != want {
+					t.Errorf("after %d calls, exhausted() == %v, want %v", c.numCalls, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCall_String(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func(c *Call)
+		want string
+	}{
+		{"Default", func(c *Call) {},
+			"*gomock.mockTestReporter.Foo(is equal to 3, is anything) registered at file.go:42, expected 1..1 calls, got 0"},
+		{"MinTimes", func(c *Call) { c.MinTimes(2); c.numCalls = 1 },
+			"*gomock.mockTestReporter.Foo(is equal to 3, is anything) registered at file.go:42, expected 2..unlimited calls, got 1"},
+		{"MaxTimes", func(c *Call) { c.MaxTimes(3) },
+			"*gomock.mockTestReporter.Foo(is equal to 3, is anything) registered at file.go:42, expected 0..3 calls, got 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Call{
+				receiver: &mockTestReporter{},
+				method:   "Foo",
+				args:     []Matcher{Eq(3), Any()},
+				origin:   "file.go:42",
+				minCalls: 1,
+				maxCalls: 1,
+			}
+			tt.set(c)
+			if got := c.String(); got != tt.want {
+				t.Errorf("String() == %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
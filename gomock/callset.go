@@ -16,16 +16,34 @@ package gomock
 
 import (
 	"bytes"
+	"container/list"
 	"fmt"
+	"reflect"
+	"sort"
 )
 
 // callSet represents a set of expected calls, indexed by receiver and method
 // name.
 type callSet struct {
-	// Calls that are still expected.
-	expected map[callSetKey][]*Call
-	// Calls that have been exhausted.
-	exhausted map[callSetKey][]*Call
+	// Calls that are still expected, in the order they were recorded, which
+	// FindMatch relies on to consume identical calls first in, first out.
+	expected map[callSetKey]*list.List
+	// Calls that have been exhausted, kept to explain why a call that was
+	// already made doesn't match any longer.
+	exhausted map[callSetKey]*list.List
+	// Where each call is in expected or exhausted, to remove it in constant
+	// time.
+	elements map[*Call]*list.Element
+	// The interface types calls were recorded for with RecordCallOnType,
+	// with the order they were first recorded in.
+	types map[reflect.Type]int
+	// The keys searched for the calls of each receiver and method, see
+	// keys. It is computed once per receiver and method, and reset when a
+	// call is recorded for a new interface type.
+	keyCache map[callSetKey][]callSetKey
+	// Whether a call replaces the calls added before with the same
+	// receiver, method and matchers.
+	allowOverride bool
 }
 
 // callSetKey is the key in the maps in callSet
@@ -35,74 +53,411 @@ type callSetKey struct {
 }
 
 func newCallSet() *callSet {
-	return &callSet{make(map[callSetKey][]*Call), make(map[callSetKey][]*Call)}
+	return &callSet{
+		expected:  make(map[callSetKey]*list.List),
+		exhausted: make(map[callSetKey]*list.List),
+		elements:  make(map[*Call]*list.Element),
+		types:     make(map[reflect.Type]int),
+		keyCache:  make(map[callSetKey][]callSetKey),
+	}
+}
+
+// typeReceiver is the receiver of the calls recorded for an interface type
+// with Controller.RecordCallOnType, rather than for a receiver.
+type typeReceiver struct {
+	t reflect.Type
 }
 
 // Add adds a new expected call.
 func (cs callSet) Add(call *Call) {
 	key := callSetKey{call.receiver, call.method}
+	if cs.allowOverride {
+		var overridden []*Call
+		for _, c := range append(cs.expectedCalls(key), cs.exhaustedCalls(key)...) {
+			if reflect.DeepEqual(c.args, call.args) {
+				overridden = append(overridden, c)
+			}
+		}
+		for _, c := range overridden {
+			cs.Delete(c)
+		}
+	}
+	if r, ok := call.receiver.(typeReceiver); ok {
+		if _, ok := cs.types[r.t]; !ok {
+			cs.types[r.t] = len(cs.types)
+			for k := range cs.keyCache {
+				delete(cs.keyCache, k)
+			}
+		}
+	}
 	m := cs.expected
 	if call.exhausted() {
 		m = cs.exhausted
 	}
-	m[key] = append(m[key], call)
+	cs.push(m, key, call)
+}
+
+// push appends call to the list for key in m.
+func (cs callSet) push(m map[callSetKey]*list.List, key callSetKey, call *Call) {
+	l := m[key]
+	if l == nil {
+		l = list.New()
+		m[key] = l
+	}
+	cs.elements[call] = l.PushBack(call)
 }
 
-// Remove removes an expected call.
+// Remove removes an expected call, which is kept as exhausted.
 func (cs callSet) Remove(call *Call) {
 	key := callSetKey{call.receiver, call.method}
-	calls := cs.expected[key]
-	for i, c := range calls {
-		if c == call {
-			// maintain order for remaining calls
-			cs.expected[key] = append(calls[:i], calls[i+1:]...)
-			cs.exhausted[key] = append(cs.exhausted[key], call)
-			break
+	e, ok := cs.elements[call]
+	if !ok || !cs.remove(cs.expected, key, e) {
+		return
+	}
+	cs.push(cs.exhausted, key, call)
+}
+
+// remove removes the element e from the list for key in m, if it is in it.
+// It reports whether it was.
+func (cs callSet) remove(m map[callSetKey]*list.List, key callSetKey, e *list.Element) bool {
+	l := m[key]
+	if l == nil {
+		return false
+	}
+	// list.Remove does nothing if e is in another list, and returns
+	// e.Value regardless, so tell by the length.
+	n := l.Len()
+	l.Remove(e)
+	if l.Len() == n {
+		return false
+	}
+	if l.Len() == 0 {
+		delete(m, key)
+	}
+	return true
+}
+
+// Delete deletes a call, whether it is still expected or exhausted, so that
+// it is neither matched nor reported missing any longer. It reports whether
+// the call was in the set. The calls that had to be made after the call
+// have to be made after its prerequisites instead.
+func (cs callSet) Delete(call *Call) bool {
+	key := callSetKey{call.receiver, call.method}
+	e, ok := cs.elements[call]
+	if !ok {
+		return false
+	}
+	delete(cs.elements, call)
+	if !cs.remove(cs.expected, key, e) {
+		cs.remove(cs.exhausted, key, e)
+	}
+	for c := range cs.elements {
+		c.replacePreReq(call)
+	}
+	return true
+}
+
+// DeleteFunc deletes the calls for which f returns true.
+func (cs callSet) DeleteFunc(f func(*Call) bool) {
+	var calls []*Call
+	for c := range cs.elements {
+		if f(c) {
+			calls = append(calls, c)
 		}
 	}
+	sort.Sort(byRecordingOrder(calls))
+	for _, c := range calls {
+		cs.Delete(c)
+	}
 }
 
-// FindMatch searches for a matching call. Returns error with explanation message if no call matched.
-func (cs callSet) FindMatch(receiver interface{}, method string, args []interface{}) (*Call, error) {
+// expectedCalls returns the calls still expected for key, in order.
+func (cs callSet) expectedCalls(key callSetKey) []*Call {
+	return listCalls(cs.expected[key])
+}
+
+// exhaustedCalls returns the exhausted calls for key, in order.
+func (cs callSet) exhaustedCalls(key callSetKey) []*Call {
+	return listCalls(cs.exhausted[key])
+}
+
+// listCalls returns the calls in l, which may be nil.
+func listCalls(l *list.List) []*Call {
+	if l == nil {
+		return nil
+	}
+	calls := make([]*Call, 0, l.Len())
+	for e := l.Front(); e != nil; e = e.Next() {
+		calls = append(calls, e.Value.(*Call))
+	}
+	return calls
+}
+
+// MethodType returns the type of the method, as recorded by its calls, or
+// those for an interface the receiver implements, or nil if there are none.
+func (cs callSet) MethodType(receiver interface{}, method string) reflect.Type {
+	for _, key := range cs.keys(receiver, method) {
+		for _, l := range []*list.List{cs.expected[key], cs.exhausted[key]} {
+			if l != nil && l.Len() > 0 {
+				return l.Front().Value.(*Call).methodType
+			}
+		}
+	}
+	return nil
+}
+
+// keys returns the keys of the calls a call of the method of receiver may
+// match: those recorded for the receiver first, then those recorded for
+// the interfaces it implements, in the order they were first recorded. The
+// slice is shared by the calls of the method, and must not be modified.
+func (cs callSet) keys(receiver interface{}, method string) []callSetKey {
 	key := callSetKey{receiver, method}
+	if keys, ok := cs.keyCache[key]; ok {
+		return keys
+	}
+	keys := cs.resolveKeys(key)
+	cs.keyCache[key] = keys
+	return keys
+}
+
+// Prepare fills keyCache for the calls recorded so far, as the first calls
+// made to them would.
+func (cs callSet) Prepare() {
+	for _, m := range []map[callSetKey]*list.List{cs.expected, cs.exhausted} {
+		for key := range m {
+			if _, ok := key.receiver.(typeReceiver); !ok {
+				cs.keys(key.receiver, key.fname)
+			}
+		}
+	}
+}
+
+// resolveKeys computes the keys of the calls matching the calls for key,
+// for keys.
+func (cs callSet) resolveKeys(key callSetKey) []callSetKey {
+	receiver, method := key.receiver, key.fname
+	keys := []callSetKey{key}
+	if len(cs.types) == 0 {
+		return keys
+	}
+	rt := reflect.TypeOf(receiver)
+	if rt == nil {
+		return keys
+	}
+	var types []reflect.Type
+	for t := range cs.types {
+		if rt.Implements(t) {
+			types = append(types, t)
+		}
+	}
+	sort.Sort(byFirstRecorded{types, cs.types})
+	for _, t := range types {
+		keys = append(keys, callSetKey{typeReceiver{t}, method})
+	}
+	return keys
+}
+
+type byFirstRecorded struct {
+	types []reflect.Type
+	order map[reflect.Type]int
+}
+
+func (ts byFirstRecorded) Len() int      { return len(ts.types) }
+func (ts byFirstRecorded) Swap(i, j int) { ts.types[i], ts.types[j] = ts.types[j], ts.types[i] }
+func (ts byFirstRecorded) Less(i, j int) bool {
+	return ts.order[ts.types[i]] < ts.order[ts.types[j]]
+}
+
+// FindMatch searches for a matching call. If several calls match, it returns
+// one that wasn't recorded with Controller.Default if there is any, then the
+// most specific one, i.e. the one with the fewest arguments matched by Any,
+// and the first one recorded among those. The calls recorded for the
+// receiver are searched first, then those recorded with
+// Controller.RecordCallOnType for the interfaces it implements. If no call
+// matches it returns a *matchError, which explains why each of the calls
+// for the method was rejected.
+func (cs callSet) FindMatch(receiver interface{}, method string, args []interface{}) (*Call, error) {
+	return cs.FindMatchFrom(0, receiver, method, args)
+}
+
+// FindMatchFrom is like FindMatch, for a call made with the controllers
+// acting for caller, see WithCallerIsolation: the calls recorded with other
+// controllers don't match it.
+func (cs callSet) FindMatchFrom(caller uint64, receiver interface{}, method string, args []interface{}) (*Call, error) {
+	var mismatches []*callMismatch
+	for _, key := range cs.keys(receiver, method) {
+		call, ms, err := cs.findMatch(key, caller, args)
+		if call != nil || err != nil {
+			return call, err
+		}
+		mismatches = append(mismatches, ms...)
+	}
+	sort.Stable(byCallOrder(mismatches))
+
+	return nil, &matchError{method: method, mismatches: mismatches}
+}
+
+// findMatch searches for a call matching among the calls for key, like
+// FindMatch. If there is none, it returns the reasons the calls for key
+// were rejected.
+func (cs callSet) findMatch(key callSetKey, caller uint64, args []interface{}) (*Call, []*callMismatch, error) {
+	expected, exhausted := cs.expected[key], cs.exhausted[key]
 
-	// Search through the expected calls.
-	expected := cs.expected[key]
-	var callsErrors bytes.Buffer
-	for _, call := range expected {
-		err := call.matches(args)
-		if err != nil {
-			fmt.Fprintf(&callsErrors, "\n%v", err)
-		} else {
-			return call, nil
+	// A forbidden call takes precedence over the calls matching as well.
+	for _, l := range []*list.List{expected, exhausted} {
+		if l == nil {
+			continue
+		}
+		for e := l.Front(); e != nil; e = e.Next() {
+			if call := e.Value.(*Call); call.forbidden() && call.acceptsCaller(caller) && call.matchArgs(args) == nil {
+				return nil, nil, &forbiddenCallError{call}
+			}
 		}
 	}
 
+	// Search through the expected calls for the best one matching.
+	var mismatches []*callMismatch
+	var best *Call
+	if expected != nil {
+		for e := expected.Front(); e != nil; e = e.Next() {
+			call := e.Value.(*Call)
+			if m := call.matchesFrom(caller, args); m != nil {
+				mismatches = append(mismatches, m)
+			} else if best == nil || call.preferredTo(best) {
+				best = call
+			}
+		}
+	}
+	if best != nil {
+		return best, nil, nil
+	}
+
 	// If we haven't found a match then search through the exhausted calls so we
 	// get useful error messages.
-	exhausted := cs.exhausted[key]
-	for _, call := range exhausted {
-		if err := call.matches(args); err != nil {
-			fmt.Fprintf(&callsErrors, "\n%v", err)
+	if exhausted != nil {
+		for e := exhausted.Front(); e != nil; e = e.Next() {
+			if m := e.Value.(*Call).matchesFrom(caller, args); m != nil {
+				mismatches = append(mismatches, m)
+			}
+		}
+	}
+	return nil, mismatches, nil
+}
+
+// A forbiddenCallError is returned by FindMatch when an invocation matches
+// a call declared with Times(0).
+type forbiddenCallError struct {
+	call *Call
+}
+
+func (e *forbiddenCallError) Error() string {
+	return fmt.Sprintf("%s was explicitly forbidden at %s", e.call.signature(), e.call.origin)
+}
+
+// A mismatchKind says why an expected call was rejected for an invocation.
+type mismatchKind int
+
+const (
+	wrongArgs     mismatchKind = iota // an argument, or their number, didn't match
+	missingPrereq                     // a prerequisite call isn't satisfied yet
+	exhaustedCall                     // the call was already made the max number of times
+	otherCaller                       // the call was recorded with another controller
+)
+
+// A callMismatch explains why an expected call didn't match an invocation.
+type callMismatch struct {
+	call     *Call
+	kind     mismatchKind
+	argIndex int // the index of the argument that didn't match, or -1
+	// describe formats the message of the mismatch. It is only called when
+	// the mismatch is reported, since most are not: a call rejected by an
+	// expected call usually matches another one.
+	describe func() string
+}
+
+func (m *callMismatch) Error() string { return m.describe() }
+
+// A matchError is returned by FindMatch when no expected call matches an
+// invocation. It holds the reason each call for the method was rejected, in
+// the order the calls were recorded.
+type matchError struct {
+	method     string
+	mismatches []*callMismatch
+}
+
+func (e *matchError) Error() string {
+	if len(e.mismatches) == 0 {
+		return fmt.Sprintf("there are no expected calls of the method %q for that receiver", e.method)
+	}
+	var buf bytes.Buffer
+	if calls := e.exhausted(); calls != nil {
+		max := 0
+		for _, c := range calls {
+			max += c.maxCalls
+		}
+		fmt.Fprintf(&buf, "expected call to %s.%v has already been made the max allowed number of times (%d):",
+			calls[0].receiverName(), e.method, max)
+		for _, c := range calls {
+			fmt.Fprintf(&buf, "\n%v", c)
 		}
+		return buf.String()
 	}
+	for _, m := range e.mismatches {
+		fmt.Fprintf(&buf, "\n%v", m)
+	}
+	return buf.String()
+}
 
-	if len(expected)+len(exhausted) == 0 {
-		fmt.Fprintf(&callsErrors, "there are no expected calls of the method %q for that receiver", method)
+// exhausted returns the calls whose arguments matched, if all of them were
+// rejected only because they were already made the max number of times.
+func (e *matchError) exhausted() []*Call {
+	var calls []*Call
+	for _, m := range e.mismatches {
+		switch m.kind {
+		case wrongArgs, otherCaller:
+		case exhaustedCall:
+			calls = append(calls, m.call)
+		default:
+			return nil
+		}
 	}
+	return calls
+}
+
+type byCallOrder []*callMismatch
+
+func (ms byCallOrder) Len() int           { return len(ms) }
+func (ms byCallOrder) Swap(i, j int)      { ms[i], ms[j] = ms[j], ms[i] }
+func (ms byCallOrder) Less(i, j int) bool { return ms[i].call.id < ms[j].call.id }
 
-	return nil, fmt.Errorf(callsErrors.String())
+// Expected returns the calls that are still expected, in the order they
+// were recorded.
+func (cs callSet) Expected() []*Call {
+	var calls []*Call
+	for _, l := range cs.expected {
+		calls = append(calls, listCalls(l)...)
+	}
+	sort.Sort(byRecordingOrder(calls))
+	return calls
 }
 
-// Failures returns the calls that are not satisfied.
+type byRecordingOrder []*Call
+
+func (cs byRecordingOrder) Len() int           { return len(cs) }
+func (cs byRecordingOrder) Swap(i, j int)      { cs[i], cs[j] = cs[j], cs[i] }
+func (cs byRecordingOrder) Less(i, j int) bool { return cs[i].id < cs[j].id }
+
+// Failures returns the calls that are not satisfied, in the order they
+// were recorded.
 func (cs callSet) Failures() []*Call {
 	failures := make([]*Call, 0, len(cs.expected))
-	for _, calls := range cs.expected {
-		for _, call := range calls {
-			if !call.satisfied() {
+	for _, l := range cs.expected {
+		for e := l.Front(); e != nil; e = e.Next() {
+			if call := e.Value.(*Call); !call.satisfied() && !call.byDefault {
 				failures = append(failures, call)
 			}
 		}
 	}
+	sort.Sort(byRecordingOrder(failures))
 	return failures
 }
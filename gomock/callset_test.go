@@ -23,6 +23,8 @@ type receiverType struct{}
 
 func (receiverType) Func() {}
 
+func (receiverType) Args(string, int) {}
+
 func TestCallSetAdd(t *testing.T) {
 	method := "TestMethod"
 	var receiver interface{} = "TestReceiver"
@@ -70,7 +72,138 @@ func TestCallSetRemove(t *testing.T) {
 	}
 
 	for _, c := range ourCalls {
-		validateOrder(cs.expected[callSetKey{receiver, method}])
+		validateOrder(cs.expectedCalls(callSetKey{receiver, method}))
 		cs.Remove(c)
 	}
 }
+
+func TestCallSetFindMatchMismatches(t *testing.T) {
+	method := "Args"
+	var receiver interface{} = "TestReceiver"
+	methodType := reflect.TypeOf(receiverType{}.Args)
+	cs := newCallSet()
+
+	exhausted := newCall(t, receiver, method, methodType, "a", 1)
+	exhausted.id, exhausted.numCalls = 1, 1
+	wrongArg := newCall(t, receiver, method, methodType, "a", 2)
+	wrongArg.id = 2
+	prereq := newCall(t, receiver, method, methodType, "b", 1)
+	prereq.id = 4
+	blocked := newCall(t, receiver, method, methodType, "a", 1).After(prereq)
+	blocked.id = 3
+	for _, c := range []*Call{wrongArg, prereq, blocked, exhausted} {
+		cs.Add(c)
+	}
+
+	call, err := cs.FindMatch(receiver, method, []interface{}{"a", 1})
+	if call != nil {
+		t.Fatalf("FindMatch: got %v, want no match", call)
+	}
+	me, ok := err.(*matchError)
+	if !ok {
+		t.Fatalf("FindMatch: got a %T error, want a *matchError", err)
+	}
+	want := []struct {
+		call     *Call
+		kind     mismatchKind
+		argIndex int
+	}{
+		{exhausted, exhaustedCall, -1},
+		{wrongArg, wrongArgs, 1},
+		{blocked, missingPrereq, -1},
+		{prereq, wrongArgs, 0},
+	}
+	if len(me.mismatches) != len(want) {
+		t.Fatalf("got %d mismatches, want %d: %v", len(me.mismatches), len(want), err)
+	}
+	for i, w := range want {
+		m := me.mismatches[i]
+		if m.call != w.call || m.kind != w.kind || m.argIndex != w.argIndex {
+			t.Errorf("mismatch %d: got (%v, %d, %d), want (%v, %d, %d)", i, m.call, m.kind, m.argIndex, w.call, w.kind, w.argIndex)
+		}
+	}
+}
+
+func TestCallSetFindMatchNoCalls(t *testing.T) {
+	cs := newCallSet()
+	_, err := cs.FindMatch("TestReceiver", "TestMethod", nil)
+	if err == nil || err.Error() != `there are no expected calls of the method "TestMethod" for that receiver` {
+		t.Errorf("FindMatch: got error %v", err)
+	}
+}
+
+func TestCallSetDelete(t *testing.T) {
+	method := "TestMethod"
+	var receiver interface{} = "TestReceiver"
+	cs := newCallSet()
+
+	first := &Call{receiver: receiver, method: method, minCalls: 1, maxCalls: 1}
+	second := &Call{receiver: receiver, method: method, minCalls: 1, maxCalls: 1, preReqs: []*Call{first}}
+	cs.Add(first)
+	cs.Add(second)
+
+	if !cs.Delete(first) {
+		t.Fatal("Delete: got false for an expected call")
+	}
+	if cs.Delete(first) {
+		t.Error("Delete: got true for a deleted call")
+	}
+	if got := cs.expectedCalls(callSetKey{receiver, method}); len(got) != 1 || got[0] != second {
+		t.Errorf("expected calls after Delete: got %v, want only the second call", got)
+	}
+	if len(second.preReqs) != 0 {
+		t.Errorf("the deleted call is still a prerequisite of %v", second.preReqs)
+	}
+}
+
+func TestCallSetRemoveAndDeleteKeepOrder(t *testing.T) {
+	method := "TestMethod"
+	var receiver interface{} = "TestReceiver"
+	key := callSetKey{receiver, method}
+	cs := newCallSet()
+
+	calls := make([]*Call, 5)
+	for i := range calls {
+		calls[i] = &Call{receiver: receiver, method: method, id: uint64(i + 1), minCalls: 1, maxCalls: 1}
+		cs.Add(calls[i])
+	}
+	cs.Remove(calls[1])
+	cs.Remove(calls[3])
+	// Removing a call again, or deleting one twice, does nothing.
+	cs.Remove(calls[1])
+	cs.Delete(calls[4])
+	cs.Delete(calls[4])
+	cs.Delete(calls[3])
+
+	if got, want := cs.expectedCalls(key), []*Call{calls[0], calls[2]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected calls: got %v, want %v", got, want)
+	}
+	if got, want := cs.exhaustedCalls(key), []*Call{calls[1]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("exhausted calls: got %v, want %v", got, want)
+	}
+}
+
+func TestCallSetFindMatchExhausted(t *testing.T) {
+	method := "Args"
+	var receiver interface{} = "TestReceiver"
+	methodType := reflect.TypeOf(receiverType{}.Args)
+	cs := newCallSet()
+
+	call := newCall(t, receiver, method, methodType, "a", 1).Times(3)
+	cs.Add(call)
+	for i := 0; i < 3; i++ {
+		if _, err := cs.FindMatch(receiver, method, []interface{}{"a", 1}); err != nil {
+			t.Fatalf("FindMatch: %v", err)
+		}
+		call.numCalls++
+	}
+	cs.Remove(call)
+
+	_, err := cs.FindMatch(receiver, method, []interface{}{"a", 1})
+	if err == nil {
+		t.Fatal("FindMatch: got no error for an exhausted call")
+	}
+	if want := "expected call to string.Args has already been made the max allowed number of times (3):\n" + call.String(); err.Error() != want {
+		t.Errorf("FindMatch: got error %q, want %q", err, want)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomock
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// argCapturer is implemented by matchers that want to see the arguments of
+// the calls matched by the expectation they belong to.
+type argCapturer interface {
+	captureArg(x interface{})
+}
+
+// A Captor is a Matcher that records the arguments of the calls it was
+// used to match, so that tests can inspect them afterwards.
+//
+// Arguments are only recorded when the whole expected call matches, and
+// only when the Captor is passed directly as an argument to an expectation
+// rather than nested inside another matcher. It is safe to use a Captor
+// from multiple goroutines.
+//
+//	var id string
+//	idCaptor := gomock.Capture(&id)
+//	mockStore.EXPECT().Save(idCaptor, gomock.Any()).Times(2)
+//	// ... exercise the code under test ...
+//	// id holds the last saved ID, idCaptor.Values() both of them.
+type Captor struct {
+	m   Matcher
+	dst reflect.Value // the pointer passed to Capture, if any
+
+	mu     sync.Mutex
+	values []interface{}
+}
+
+// Capture returns a Captor that matches any argument. If dst is non-nil, it
+// must be a pointer; every captured argument is also stored in the value it
+// points to. Using a Captor for a parameter whose type isn't assignable to
+// that value fails the test when the expectation is recorded.
+func Capture(dst interface{}) *Captor {
+	return CaptureMatching(dst, Any())
+}
+
+// CaptureMatching is like Capture, but the returned Captor only matches
+// arguments that m matches.
+func CaptureMatching(dst interface{}, m Matcher) *Captor {
+	c := &Captor{m: m}
+	if dst != nil {
+		v := reflect.ValueOf(dst)
+		if v.Kind() != reflect.Ptr || v.IsNil() {
+			panic(fmt.Sprintf("gomock: destination passed to Capture must be a non-nil pointer, not %T", dst))
+		}
+		c.dst = v
+	}
+	return c
+}
+
+// Matches implements Matcher.
+func (c *Captor) Matches(x interface{}) bool {
+	if c.dst.IsValid() && !assignableTo(x, c.dst.Elem().Type()) {
+		return false
+	}
+	return c.m.Matches(x)
+}
+
+// String implements Matcher.
+func (c *Captor) String() string {
+	return c.m.String()
+}
+
+func (c *Captor) validateArgType(t reflect.Type) error {
+	if c.dst.IsValid() && t.Kind() != reflect.Interface && !t.AssignableTo(c.dst.Elem().Type()) {
+		return fmt.Errorf("can't capture an argument of type %v into a %v", t, c.dst.Type())
+	}
+	if v, ok := c.m.(argTypeValidator); ok {
+		return v.validateArgType(t)
+	}
+	return nil
+}
+
+func (c *Captor) captureArg(x interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values = append(c.values, x)
+	if c.dst.IsValid() {
+		if x == nil {
+			c.dst.Elem().Set(reflect.Zero(c.dst.Elem().Type()))
+		} else {
+			c.dst.Elem().Set(reflect.ValueOf(x))
+		}
+	}
+}
+
+// Value returns the most recently captured argument, or nil if none was.
+func (c *Captor) Value() interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.values) == 0 {
+		return nil
+	}
+	return c.values[len(c.values)-1]
+}
+
+// Values returns all captured arguments, in the order they were captured.
+func (c *Captor) Values() []interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]interface{}(nil), c.values...)
+}
+
+// assignableTo reports whether x can be stored in a variable of type t.
+func assignableTo(x interface{}, t reflect.Type) bool {
+	if x == nil {
+		switch t.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
+			reflect.Ptr, reflect.Slice:
+			return true
+		}
+		return false
+	}
+	return reflect.TypeOf(x).AssignableTo(t)
+}
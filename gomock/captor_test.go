@@ -0,0 +1,119 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomock_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestCaptureAccumulatesValues(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	defer reporter.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	var last string
+	captor := gomock.Capture(&last)
+	ctrl.RecordCall(subject, "FooMethod", captor).Times(3)
+
+	if v := captor.Value(); v != nil {
+		t.Errorf("Value() before any call == %v, want nil", v)
+	}
+	ctrl.Call(subject, "FooMethod", "a")
+	ctrl.Call(subject, "FooMethod", "b")
+	ctrl.Call(subject, "FooMethod", "c")
+	ctrl.Finish()
+	reporter.assertPass("captured calls")
+
+	if last != "c" {
+		t.Errorf("captured destination == %q, want %q", last, "c")
+	}
+	if v := captor.Value(); v != "c" {
+		t.Errorf("Value() == %v, want c", v)
+	}
+	assertEqual(t, []interface{}{"a", "b", "c"}, captor.Values())
+}
+
+func TestCaptureMatchingOnlyRecordsMatchedCalls(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	defer reporter.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	captor := gomock.CaptureMatching(nil, gomock.HasPrefix("x"))
+	ctrl.RecordCall(subject, "ActOnTestStructMethod", gomock.Any(), captor).AnyTimes()
+	ctrl.RecordCall(subject, "FooMethod", captor).AnyTimes()
+
+	ctrl.Call(subject, "FooMethod", "x1")
+	reporter.assertFatal(func() {
+		ctrl.Call(subject, "FooMethod", "y")
+	}, "doesn't match the argument at index 0")
+	ctrl.Call(subject, "FooMethod", "x2")
+	ctrl.Finish()
+
+	assertEqual(t, []interface{}{"x1", "x2"}, captor.Values())
+}
+
+func TestCaptureTypeMismatch(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	subject := new(Subject)
+
+	var n int
+	reporter.assertFatal(func() {
+		ctrl.RecordCall(subject, "FooMethod", gomock.Capture(&n))
+	}, "invalid matcher for argument 0", "can't capture an argument of type string into a *int")
+}
+
+func TestCaptureVariadic(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	defer reporter.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	var rest []string
+	ctrl.RecordCall(subject, "VariadicMethod", 0, gomock.Capture(&rest))
+	ctrl.Call(subject, "VariadicMethod", 0, "1", "2")
+	ctrl.Finish()
+	reporter.assertPass("captured variadic arguments")
+
+	if !reflect.DeepEqual(rest, []string{"1", "2"}) {
+		t.Errorf("captured variadic arguments == %v, want [1 2]", rest)
+	}
+}
+
+func TestCaptureConcurrentCalls(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	defer reporter.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	captor := gomock.Capture(nil)
+	ctrl.RecordCall(subject, "FooMethod", captor).Times(50)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctrl.Call(subject, "FooMethod", "arg")
+		}()
+	}
+	wg.Wait()
+	ctrl.Finish()
+
+	if n := len(captor.Values()); n != 50 {
+		t.Errorf("captured %d values, want 50", n)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomock
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A channelValue is a value passed to Return which stands for a new channel
+// each time the call is matched, see ReturnChannelOf and ReturnChannelFrom.
+type channelValue struct {
+	typ    reflect.Type    // the type of the channel, once checked
+	values []reflect.Value // the values sent to the channel, converted to its element type
+	source reflect.Value   // the channel the values are received from, if valid
+}
+
+// ReturnChannelOf stands, in the values passed to Return, for a channel
+// holding values: each time the call is matched, it returns a new channel
+// of the type of the method's result, e.g. <-chan int, with values buffered
+// in it, and closed.
+//
+//	mockFeed.EXPECT().Items().Return(gomock.ReturnChannelOf(1, 2, 3), nil)
+//
+// The values must be assignable to the element type of the channel. Return
+// fails the test otherwise.
+func ReturnChannelOf(values ...interface{}) interface{} {
+	vs := make([]reflect.Value, len(values))
+	for i, v := range values {
+		vs[i] = reflect.ValueOf(v)
+	}
+	return channelValue{values: vs}
+}
+
+// ReturnChannelFrom stands, in the values passed to Return, for a channel
+// streaming the values received from source, a channel: each time the call
+// is matched, it returns a new unbuffered channel of the type of the
+// method's result, which a goroutine sends the values received from source
+// to, and closes once source is closed. The calls share source, so each
+// value is sent to one of their channels only. The goroutine blocks until
+// the values are received.
+//
+//	items := make(chan int)
+//	mockFeed.EXPECT().Items().Return(gomock.ReturnChannelFrom(items), nil)
+//
+// The element type of source must be assignable to the element type of the
+// channel. Return fails the test otherwise.
+func ReturnChannelFrom(source interface{}) interface{} {
+	return channelValue{source: reflect.ValueOf(source)}
+}
+
+// check checks that v can be returned as a value of type want, and returns
+// it with its values converted to the element type of want.
+func (v channelValue) check(want reflect.Type) (channelValue, error) {
+	if want.Kind() != reflect.Chan || want.ChanDir()&reflect.RecvDir == 0 {
+		return v, fmt.Errorf("%v is not a channel which can be received from", want)
+	}
+	elem := want.Elem()
+	if v.source.IsValid() {
+		st := v.source.Type()
+		if st.Kind() != reflect.Chan || st.ChanDir()&reflect.RecvDir == 0 {
+			return v, fmt.Errorf("the source %v is not a channel which can be received from", st)
+		}
+		if !st.Elem().AssignableTo(elem) {
+			return v, fmt.Errorf("the values of the source %v are not assignable to %v", st, elem)
+		}
+		return channelValue{typ: want, source: v.source}, nil
+	}
+	values := make([]reflect.Value, len(v.values))
+	for i, x := range v.values {
+		switch {
+		case !x.IsValid() && nillable(elem):
+			values[i] = reflect.Zero(elem)
+		case !x.IsValid():
+			return v, fmt.Errorf("value %d of the channel is nil, but %v is not nillable", i, elem)
+		case !x.Type().AssignableTo(elem):
+			return v, fmt.Errorf("value %d of the channel is a %v, which is not assignable to %v", i, x.Type(), elem)
+		default:
+			values[i] = reflect.New(elem).Elem()
+			values[i].Set(x)
+		}
+	}
+	return channelValue{typ: want, values: values}, nil
+}
+
+// make returns a new channel for a call which was matched.
+func (v channelValue) make() interface{} {
+	bidi := reflect.ChanOf(reflect.BothDir, v.typ.Elem())
+	if !v.source.IsValid() {
+		ch := reflect.MakeChan(bidi, len(v.values))
+		for _, x := range v.values {
+			ch.Send(x)
+		}
+		ch.Close()
+		return ch.Convert(v.typ).Interface()
+	}
+	ch := reflect.MakeChan(bidi, 0)
+	go func() {
+		defer ch.Close()
+		for {
+			x, ok := v.source.Recv()
+			if !ok {
+				return
+			}
+			ch.Send(x)
+		}
+	}()
+	return ch.Convert(v.typ).Interface()
+}
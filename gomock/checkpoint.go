@@ -0,0 +1,81 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomock
+
+// A Checkpoint marks a point in a test, to verify the calls recorded and
+// made since then, see Controller.Checkpoint.
+type Checkpoint struct {
+	recorded uint64 // the ID of the last call recorded before it
+	matched  uint64 // the number of calls matched before it
+	origin   string // where the checkpoint was taken
+}
+
+// Checkpoint returns a Checkpoint of the current point in the test, to
+// structure a long scenario into phases and check the calls each phase
+// caused:
+//
+//	cp := ctrl.Checkpoint()
+//	m.EXPECT().Put("a", 1)
+//	c.Store("a", 1)
+//	ctrl.VerifySince(cp)
+//
+// The controller keeps records of the calls matched since the latest
+// checkpoint only, and drops the older ones when a new checkpoint is taken.
+func (ctrl *Controller) Checkpoint() Checkpoint {
+	ctrl.t.Helper()
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	ctrl.checkpointed = true
+	ctrl.records = nil
+	return Checkpoint{recorded: ctrl.lastID, matched: ctrl.numCalls, origin: callerInfo(1)}
+}
+
+// CallsSince returns records of the calls matched since cp, oldest first.
+// For a checkpoint older than the latest one, it only returns the calls
+// matched since the latest checkpoint.
+func (ctrl *Controller) CallsSince(cp Checkpoint) []CallRecord {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	var records []CallRecord
+	for _, r := range ctrl.records {
+		if r.Seq > cp.matched {
+			r.Args = append([]interface{}(nil), r.Args...)
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// VerifySince checks that the expected calls recorded since cp have all
+// been made, without waiting for Finish, and fails the test with Fatalf
+// otherwise. The calls are still expected afterwards: Finish checks them,
+// and they may match more calls.
+func (ctrl *Controller) VerifySince(cp Checkpoint) {
+	ctrl.t.Helper()
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	var missing []*Call
+	for _, c := range ctrl.failures() {
+		if c.id > cp.recorded {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) != 0 {
+		ctrl.t.Fatalf("missing call(s) since the checkpoint at %s:\n%s", cp.origin, newExpectationReport(missing))
+	}
+}
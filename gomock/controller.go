@@ -30,6 +30,19 @@
 //           // pass mockObj to a real object and play with it.
 //         }
 //
+// When several expected calls match a call, the most specific one is used,
+// that is the one with the fewest arguments matched by gomock.Any(), so that
+// a specific expectation overrides a broad one whatever order they are
+// recorded in. Among calls which are as specific, the one recorded first is
+// used, so identical expectations are consumed in the order they were
+// recorded:
+//
+//     mockObj.EXPECT().Next().Return(1)
+//     mockObj.EXPECT().Next().Return(2) // returned by the second call
+//
+// Calls recorded with Controller.Default are only used when no other call
+// matches.
+//
 // By default, expected calls are not enforced to run in any particular order.
 // Call order dependency can be enforced by use of InOrder and/or Call.After.
 // Call.After can create more varied call order dependencies, but InOrder is
@@ -56,64 +69,421 @@
 package gomock
 
 import (
+	"bytes"
 	"fmt"
 	"golang.org/x/net/context"
+	"io"
 	"reflect"
 	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 // A TestReporter is something that can be used to report test failures.
 // It is satisfied by the standard library's *testing.T.
+//
+// Fatalf is expected not to return, like that of *testing.T, which ends the
+// goroutine of the test with runtime.Goexit. If it does return, gomock calls
+// runtime.Goexit itself.
 type TestReporter interface {
 	Errorf(format string, args ...interface{})
 	Fatalf(format string, args ...interface{})
 }
 
+// A TestHelper is a TestReporter that can mark functions as test helpers,
+// so that failures are reported at the line of the test that called them
+// rather than inside gomock. It is satisfied by the standard library's
+// *testing.T since Go 1.9.
+type TestHelper interface {
+	TestReporter
+	Helper()
+}
+
 // A Controller represents the top-level control of a mock ecosystem.
 // It defines the scope and lifetime of mock objects, as well as their expectations.
 // It is safe to call Controller's methods from multiple goroutines.
+//
+// An unexpected call made from a goroutine other than the test's, e.g. one
+// started by the code under test, or with a handle passed by Go, is reported
+// with Errorf rather than Fatalf, which can only stop the test from the
+// test's goroutine, and returns zero values. Finish fails the test then.
 type Controller struct {
+	// The state shared with the scopes of the controller, see Scope.
+	*controllerState
+
+	t           TestHelper
+	finished    bool
+	scoped      bool   // whether the controller was returned by Scope
+	numRecorded uint64 // the number of calls recorded so far
+	numFinished uint64 // the number of calls recorded when Finish was called
+
+	// restoreFuncs put back the functions replaced by MockFunction, once
+	// Finish is called.
+	restoreFuncs []func()
+	// cancel cancels the context returned by WithContext, once Finish is
+	// called.
+	cancel func()
+
+	// owner is the controller which a handle passed by Go acts for, nil for
+	// the controllers returned by NewController and Scope.
+	owner *Controller
+	// caller identifies the controller, and the handles acting for it, as
+	// the caller of the calls recorded and made with them, see
+	// WithCallerIsolation.
+	caller uint64
+
+	// otherGoroutineFailures are the unexpected calls made from goroutines
+	// other than the test's, which are reported with Errorf.
+	otherGoroutineFailures []string
+}
+
+// controllerState is the state of a Controller shared with its scopes.
+type controllerState struct {
 	mu            sync.Mutex
-	t             TestReporter
 	expectedCalls *callSet
-	finished      bool
+	numCalls      uint64 // the number of calls matched so far
+	lastID        uint64 // the ID of the last call recorded
+
+	callsLogger     io.Writer                // set by WithExpectedCallsLogger
+	nameForReceiver func(interface{}) string // set by WithNameForReceiver
+	strictOrder     bool                     // set by WithStrictOrder
+	lastRecorded    *Call                    // the last call recorded with strictOrder
+	relaxed         bool                     // set by Relaxed
+	verbose         bool                     // set by WithVerbose
+	verboseLog      io.Writer                // set by WithVerbose
+	journal         []JournalEntry           // kept with verbose
+	useAfterFinish  bool                     // set by AllowUseAfterFinish
+	isolated        bool                     // set by WithCallerIsolation
+	callObserver    func(CallInfo)           // set by WithCallObserver
+	stats           callStats
+	checkpointed    bool         // whether Checkpoint was called
+	records         []CallRecord // the calls matched since the latest checkpoint
+
+	// changed is closed, and replaced, when a call is matched or removed,
+	// to wake up WaitForSatisfaction. It is nil until someone waits.
+	changed chan struct{}
+
+	// scopes are the unfinished scopes, latest last.
+	scopes []*Controller
+
+	lastCaller uint64 // the last caller handed out, see Controller.caller
+
+	// receivers are the receivers of the calls recorded, see register.
+	receivers map[interface{}]bool
+
+	// byTest records whether the controller was created by a test, a
+	// benchmark or an example, whose goroutine the others are told from.
+	byTest bool
+
+	idleTimeout time.Duration // set by WithIdleTimeout
+	lastCall    time.Time     // when the last call was made or recorded, with idleTimeout
+	stopIdle    chan struct{} // closed by Finish to stop watchIdle
+}
+
+// receiverOwners maps the receivers of the calls recorded with controllers
+// which haven't finished to their state, to tell when a mock is used with a
+// controller other than the one it was created with.
+var receiverOwners = struct {
+	sync.Mutex
+	m map[interface{}]*controllerState
+}{m: make(map[interface{}]*controllerState)}
+
+// register registers receiver as a receiver of calls recorded with ctrl. It
+// must be called with the lock held.
+func (ctrl *Controller) register(receiver interface{}) {
+	if ctrl.receivers[receiver] {
+		return
+	}
+	if ctrl.receivers == nil {
+		ctrl.receivers = make(map[interface{}]bool)
+	}
+	ctrl.receivers[receiver] = true
+	receiverOwners.Lock()
+	receiverOwners.m[receiver] = ctrl.controllerState
+	receiverOwners.Unlock()
+}
+
+// unregister unregisters the receivers registered with ctrl.
+func (ctrl *Controller) unregister() {
+	receiverOwners.Lock()
+	defer receiverOwners.Unlock()
+	for receiver := range ctrl.receivers {
+		if receiverOwners.m[receiver] == ctrl.controllerState {
+			delete(receiverOwners.m, receiver)
+		}
+	}
 }
 
-func NewController(t TestReporter) *Controller {
-	return &Controller{
-		t:             t,
-		expectedCalls: newCallSet(),
+// foreignReceiver reports whether receiver isn't registered with ctrl, but
+// with another controller. It must be called with the lock held.
+func (ctrl *Controller) foreignReceiver(receiver interface{}) bool {
+	if ctrl.receivers[receiver] {
+		return false
 	}
+	receiverOwners.Lock()
+	defer receiverOwners.Unlock()
+	owner, ok := receiverOwners.m[receiver]
+	return ok && owner != ctrl.controllerState
 }
 
+// NewController returns a new Controller reporting failures to t. If t is a
+// TestHelper, the functions of gomock are marked as helpers. If t has a
+// Cleanup method, as *testing.T has since Go 1.14, Finish is registered with
+// it, so that calling Finish is optional.
+func NewController(t TestReporter, opts ...ControllerOption) *Controller {
+	ctrl := &Controller{
+		controllerState: &controllerState{expectedCalls: newCallSet()},
+		t:               newFatalExiter(t),
+	}
+	ctrl.caller = ctrl.newCaller()
+	ctrl.byTest = onTestGoroutine()
+	for _, opt := range opts {
+		opt.apply(ctrl)
+	}
+	if ctrl.idleTimeout > 0 {
+		ctrl.lastCall = time.Now()
+		ctrl.stopIdle = make(chan struct{})
+		go ctrl.watchIdle()
+	}
+	ctrl.registerCleanup(t)
+	return ctrl
+}
+
+// A ControllerOption configures a Controller created by NewController.
+type ControllerOption interface {
+	apply(*Controller)
+}
+
+type optionFunc func(*Controller)
+
+func (f optionFunc) apply(ctrl *Controller) { f(ctrl) }
+
+// WithOverridableExpectations makes each expected call replace the calls
+// recorded before it for the same receiver and method with the same
+// matchers, e.g. to override an expectation set up for all test cases.
+func WithOverridableExpectations() ControllerOption {
+	return optionFunc(func(ctrl *Controller) {
+		ctrl.expectedCalls.allowOverride = true
+	})
+}
+
+// WithExpectedCallsLogger makes the controller write a line to w for every
+// call matching an expected call, with its arguments and the origin of the
+// expected call.
+func WithExpectedCallsLogger(w io.Writer) ControllerOption {
+	return optionFunc(func(ctrl *Controller) {
+		ctrl.callsLogger = w
+	})
+}
+
+// WithNameForReceiver sets how the receivers of calls are described in
+// failure messages. By default a receiver is described by its type, e.g.
+// *mock_foo.MockFoo, which doesn't tell apart mocks of the same type.
+func WithNameForReceiver(name func(receiver interface{}) string) ControllerOption {
+	return optionFunc(func(ctrl *Controller) {
+		ctrl.nameForReceiver = name
+	})
+}
+
+// WithStrictOrder makes every expected call a prerequisite of the next one
+// recorded with the controller, across all of its mocks, as if they were
+// all passed to InOrder. Calls expected any number of times, like stubs, and
+// those recorded with Default or marked with AnyOrder are exempt: they may
+// be made at any time, and don't delay others.
+func WithStrictOrder() ControllerOption {
+	return optionFunc(func(ctrl *Controller) {
+		ctrl.strictOrder = true
+	})
+}
+
+// Relaxed makes a call which no expected call matches return the zero
+// values of the results of the method instead of failing the test, like
+// the nice mocks of other frameworks do. The call is only logged, with the
+// Logf method of the TestReporter if it has one, as *testing.T does, which
+// shows it with go test -v. Calls forbidden with Times(0) still fail, and
+// so do expected calls which aren't made.
+func Relaxed() ControllerOption {
+	return optionFunc(func(ctrl *Controller) {
+		ctrl.relaxed = true
+	})
+}
+
+// AllowUseAfterFinish lets calls be recorded with, and made to the mocks
+// of, a controller which has already finished. By default that fails the
+// test, since the calls can't be checked any longer, but legacy tests may
+// depend on it. Finish called again still fails if calls were recorded.
+func AllowUseAfterFinish() ControllerOption {
+	return optionFunc(func(ctrl *Controller) {
+		ctrl.useAfterFinish = true
+	})
+}
+
+// WithCallerIsolation makes an expected call match only the calls made with
+// the controller it was recorded with: the controller itself, one of its
+// scopes, or a handle passed by Go acting for either. The controller is the
+// token of the caller, passed explicitly, so that the parallel subtests of a
+// test can share a controller, and the receivers of its calls, without
+// consuming each other's calls:
+//
+//	ctrl := gomock.NewController(t, gomock.WithCallerIsolation())
+//	for _, tc := range cases {
+//		tc := tc
+//		t.Run(tc.name, func(t *testing.T) {
+//			t.Parallel()
+//			scope := ctrl.Scope(t)
+//			m := NewMockFoo(scope)
+//			m.EXPECT().Get(tc.key).Return(tc.value)
+//			scope.RecordCallOnType(fooType, "Close")
+//			...
+//		})
+//	}
+//
+// Mocks created with a scope, or with a handle, make their calls with it.
+func WithCallerIsolation() ControllerOption {
+	return optionFunc(func(ctrl *Controller) {
+		ctrl.isolated = true
+	})
+}
+
+// WithIdleTimeout makes the controller fail the test, with Errorf, if no
+// call is made to its mocks, nor recorded, for d while expected calls are
+// still pending, instead of letting a test waiting for a call that never
+// comes hang until go test times out. The failure lists the pending calls.
+// With WithContext, it also cancels the context. Calls expected any number
+// of times aren't pending. The watch stops at the first failure, or when
+// Finish is called.
+func WithIdleTimeout(d time.Duration) ControllerOption {
+	return optionFunc(func(ctrl *Controller) {
+		ctrl.idleTimeout = d
+	})
+}
+
+// watchIdle implements WithIdleTimeout.
+func (ctrl *Controller) watchIdle() {
+	timer := time.NewTimer(ctrl.idleTimeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctrl.stopIdle:
+			return
+		case <-timer.C:
+		}
+		ctrl.mu.Lock()
+		if ctrl.finished {
+			ctrl.mu.Unlock()
+			return
+		}
+		if idle := time.Since(ctrl.lastCall); idle < ctrl.idleTimeout {
+			timer.Reset(ctrl.idleTimeout - idle)
+			ctrl.mu.Unlock()
+			continue
+		}
+		failures := ctrl.failures()
+		if len(failures) == 0 {
+			timer.Reset(ctrl.idleTimeout)
+			ctrl.mu.Unlock()
+			continue
+		}
+		ctrl.t.Errorf("gomock: no call was made to the mocks for %v, while expected calls are pending:\n%s",
+			ctrl.idleTimeout, newExpectationReport(failures))
+		ctrl.mu.Unlock()
+		return
+	}
+}
+
+// receiverName describes receiver in failure messages.
+func (ctrl *Controller) receiverName(receiver interface{}) string {
+	switch r := receiver.(type) {
+	case typeReceiver:
+		return r.t.String()
+	case *FuncMock:
+		return r.typ.String()
+	}
+	if ctrl.nameForReceiver != nil {
+		return ctrl.nameForReceiver(receiver)
+	}
+	return fmt.Sprintf("%T", receiver)
+}
+
+// cleanuper is implemented by test reporters, like *testing.T, which can
+// run functions when the test completes.
+type cleanuper interface {
+	Cleanup(func())
+}
+
+func (ctrl *Controller) registerCleanup(t TestReporter) {
+	if c, ok := t.(cleanuper); ok {
+		c.Cleanup(ctrl.Finish)
+	}
+}
+
+// cancelReporter cancels a context on any failure, before reporting it.
+// Cancelling first matters for Fatalf, which doesn't return.
 type cancelReporter struct {
-	t      TestReporter
+	t      TestHelper
 	cancel func()
 }
 
-func (r *cancelReporter) Errorf(format string, args ...interface{}) { r.t.Errorf(format, args...) }
+func (r *cancelReporter) Errorf(format string, args ...interface{}) {
+	r.t.Helper()
+	r.cancel()
+	r.t.Errorf(format, args...)
+}
 func (r *cancelReporter) Fatalf(format string, args ...interface{}) {
-	defer r.cancel()
+	r.t.Helper()
+	r.cancel()
 	r.t.Fatalf(format, args...)
 }
+func (r *cancelReporter) Helper() { r.t.Helper() }
+func (r *cancelReporter) Logf(format string, args ...interface{}) {
+	logf(r.t, format, args...)
+}
 
-// WithContext returns a new Controller and a Context, which is cancelled on any
-// fatal failure.
-func WithContext(ctx context.Context, t TestReporter) (*Controller, context.Context) {
+// WithContext returns a new Controller and a Context derived from ctx, which
+// is cancelled on any failure, so that code blocked on the context by the
+// test unwinds instead of waiting for the test to time out. Finish cancels
+// it too, once it has checked the calls.
+func WithContext(ctx context.Context, t TestReporter, opts ...ControllerOption) (*Controller, context.Context) {
 	ctx, cancel := context.WithCancel(ctx)
-	return NewController(&cancelReporter{t, cancel}), ctx
+	h, ok := t.(TestHelper)
+	if !ok {
+		h = nopTestHelper{t}
+	}
+	ctrl := NewController(&cancelReporter{h, cancel}, opts...)
+	ctrl.cancel = cancel
+	ctrl.registerCleanup(t)
+	return ctrl, ctx
 }
 
 func (ctrl *Controller) RecordCall(receiver interface{}, method string, args ...interface{}) *Call {
-	if h, ok := ctrl.t.(testHelper); ok {
-		h.Helper()
-	}
+	ctrl.t.Helper()
+
+	return ctrl.RecordCallWithMethodType(receiver, method, ctrl.methodType(receiver, method), args...)
+}
+
+// Default records a default expectation of a call, to share baseline
+// expectations, e.g. of logging, across tests. A default call is expected
+// any number of times unless declared otherwise, is never reported missing
+// by Finish, and only matches a call that no other expected call matches.
+func (ctrl *Controller) Default(receiver interface{}, method string, args ...interface{}) *Call {
+	ctrl.t.Helper()
+
+	call := ctrl.RecordCallWithMethodType(receiver, method, ctrl.methodType(receiver, method), args...)
+	call.byDefault = true
+	return call.AnyTimes()
+}
+
+// methodType returns the type of the method of receiver.
+func (ctrl *Controller) methodType(receiver interface{}, method string) reflect.Type {
+	ctrl.t.Helper()
 
 	recv := reflect.ValueOf(receiver)
 	for i := 0; i < recv.Type().NumMethod(); i++ {
 		if recv.Type().Method(i).Name == method {
-			return ctrl.RecordCallWithMethodType(receiver, method, recv.Method(i).Type(), args...)
+			return recv.Method(i).Type()
 		}
 	}
 	ctrl.t.Fatalf("gomock: failed finding method %s on %T", method, receiver)
@@ -121,86 +491,596 @@ func (ctrl *Controller) RecordCall(receiver interface{}, method string, args ...
 }
 
 func (ctrl *Controller) RecordCallWithMethodType(receiver interface{}, method string, methodType reflect.Type, args ...interface{}) *Call {
-	if h, ok := ctrl.t.(testHelper); ok {
-		h.Helper()
-	}
+	ctrl.t.Helper()
 
-	call := newCall(ctrl.t, receiver, method, methodType, args...)
+	ctrl.mu.Lock()
+	owner := ctrl.current()
+	ctrl.mu.Unlock()
+
+	call := newCall(owner.t, receiver, method, methodType, args...)
+	call.ctrl = owner
 
 	ctrl.mu.Lock()
 	defer ctrl.mu.Unlock()
+	if owner.finished && !ctrl.useAfterFinish {
+		owner.t.Fatalf("gomock: controller already finished, can't record %s at %s", call.signature(), call.origin)
+		return call
+	}
+	owner.numRecorded++
+	ctrl.lastID++
+	call.id = ctrl.lastID
+	if ctrl.isolated {
+		call.caller = owner.caller
+	}
+	if ctrl.strictOrder {
+		call.strictPrev = ctrl.lastRecorded
+		ctrl.lastRecorded = call
+	}
 	ctrl.expectedCalls.Add(call)
+	ctrl.register(receiver)
+	if ctrl.idleTimeout > 0 {
+		ctrl.lastCall = time.Now()
+	}
+	ctrl.stats.recorded(receiver, method)
+	if ctrl.verbose {
+		ctrl.journalRecorded(call)
+	}
 
 	return call
 }
 
-func (ctrl *Controller) Call(receiver interface{}, method string, args ...interface{}) []interface{} {
-	if h, ok := ctrl.t.(testHelper); ok {
-		h.Helper()
+// RecordCallOnType records an expected call of a method of the interface
+// ifaceType, e.g. reflect.TypeOf((*Foo)(nil)).Elem(), which the calls of
+// the method on any receiver implementing the interface may match. The
+// expected calls recorded for the receiver itself have priority: only when
+// none of them matches a call is one recorded for its type used.
+func (ctrl *Controller) RecordCallOnType(ifaceType reflect.Type, method string, args ...interface{}) *Call {
+	ctrl.t.Helper()
+
+	if ifaceType.Kind() != reflect.Interface {
+		ctrl.t.Fatalf("gomock: RecordCallOnType needs an interface type, got %v", ifaceType)
+		panic("unreachable")
 	}
+	m, ok := ifaceType.MethodByName(method)
+	if !ok {
+		ctrl.t.Fatalf("gomock: failed finding method %s on %v", method, ifaceType)
+		panic("unreachable")
+	}
+	return ctrl.RecordCallWithMethodType(typeReceiver{ifaceType}, method, m.Type, args...)
+}
 
-	// Nest this code so we can use defer to make sure the lock is released.
-	actions := func() []func([]interface{}) []interface{} {
-		ctrl.mu.Lock()
-		defer ctrl.mu.Unlock()
+// RecordCallByFunc is like RecordCall, but takes the method as a method value
+// or a method expression instead of its name, so that renaming the method
+// carries through to the expectation:
+//
+//	ctrl.RecordCallByFunc(m, m.Get, "key")
+//	ctrl.RecordCallByFunc(m, (*MockFoo).Get, "key")
+func (ctrl *Controller) RecordCallByFunc(receiver interface{}, method interface{}, args ...interface{}) *Call {
+	ctrl.t.Helper()
 
-		expected, err := ctrl.expectedCalls.FindMatch(receiver, method, args)
-		if err != nil {
-			origin := callerInfo(2)
-			ctrl.t.Fatalf("Unexpected call to %T.%v(%v) at %s because: %s", receiver, method, args, origin, err)
-		}
+	name, err := methodName(receiver, method)
+	if err != nil {
+		ctrl.t.Fatalf("gomock: %v", err)
+		panic("unreachable")
+	}
+	return ctrl.RecordCallWithMethodType(receiver, name, ctrl.methodType(receiver, name), args...)
+}
 
-		// Two things happen here:
-		// * the matching call no longer needs to check prerequite calls,
-		// * and the prerequite calls are no longer expected, so remove them.
-		preReqCalls := expected.dropPrereqs()
-		for _, preReqCall := range preReqCalls {
-			ctrl.expectedCalls.Remove(preReqCall)
-		}
+// methodName returns the name of the method of receiver which f, a method
+// value or a method expression, refers to.
+func methodName(receiver interface{}, f interface{}) (string, error) {
+	fv := reflect.ValueOf(f)
+	if fv.Kind() != reflect.Func || fv.IsNil() {
+		return "", fmt.Errorf("%T is not a method of %T", f, receiver)
+	}
+	fn := runtime.FuncForPC(fv.Pointer())
+	if fn == nil {
+		return "", fmt.Errorf("can't resolve the name of %T", f)
+	}
+	// The name is e.g. "path/to/pkg.(*T).Method" for a method expression,
+	// with a "-fm" suffix for a method value.
+	full := strings.TrimSuffix(fn.Name(), "-fm")
+	i := strings.LastIndex(full, ".")
+	typeName, name := full[:i], full[i+1:]
 
-		actions := expected.call(args)
-		if expected.exhausted() {
-			ctrl.expectedCalls.Remove(expected)
-		}
-		return actions
-	}()
+	rt := reflect.TypeOf(receiver)
+	t := rt
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	pkg := funcPkgPath(t.PkgPath())
+	if typeName != pkg+"."+t.Name() && typeName != pkg+".(*"+t.Name()+")" {
+		return "", fmt.Errorf("%s is not a method of %T", fn.Name(), receiver)
+	}
+	if _, ok := rt.MethodByName(name); !ok {
+		return "", fmt.Errorf("%s is not in the method set of %T", fn.Name(), receiver)
+	}
+	return name, nil
+}
+
+// funcPkgPath returns the import path as it appears in function names, in
+// which dots in the last element are escaped.
+func funcPkgPath(path string) string {
+	i := strings.LastIndex(path, "/") + 1
+	return path[:i] + strings.Replace(path[i:], ".", "%2e", -1)
+}
+
+// Prepare warms up the cache of the expected calls each method of the mocks
+// may match, for the calls recorded so far. It only moves that work ahead,
+// e.g. out of the timed loop of a benchmark: the first call of each method
+// fills the cache otherwise, and the calls made after it are no faster. The
+// calls recorded after Prepare are matched just the same.
+func (ctrl *Controller) Prepare() {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	ctrl.expectedCalls.Prepare()
+}
+
+// RemoveCall revokes the expectation of call, so that it is neither matched
+// nor reported missing by Finish. The calls that have to be made after it
+// have to be made after its prerequisites instead. Removing a call that has
+// already been removed does nothing. A call may be removed once it has been
+// made, as long as it hasn't been made more times than its minimum, e.g. a
+// call expected with MinTimes(2) and made once, but not after more calls.
+func (ctrl *Controller) RemoveCall(call *Call) {
+	ctrl.t.Helper()
+
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if call.ctrl == nil || call.ctrl.controllerState != ctrl.controllerState {
+		ctrl.t.Fatalf("gomock: can't remove %v, which belongs to a different Controller", call)
+		return
+	}
+	if call.numCalls > call.minCalls {
+		ctrl.t.Fatalf("gomock: can't remove %v, which has already been made %d time(s), more than its minimum of %d",
+			call, call.numCalls, call.minCalls)
+		return
+	}
+	ctrl.expectedCalls.Delete(call)
+	ctrl.notifyChanged()
+}
+
+// ExpectedCalls returns the calls that are still expected, i.e. those that
+// haven't been made the maximum number of times, in the order they were
+// recorded. The returned slice is a snapshot; changing it doesn't affect the
+// controller.
+func (ctrl *Controller) ExpectedCalls() []*Call {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	return ctrl.expectedCalls.Expected()
+}
+
+func (ctrl *Controller) Call(receiver interface{}, method string, args ...interface{}) []interface{} {
+	ctrl.t.Helper()
+
+	// The actions run without the lock held, so that they may call mocks
+	// of the controller, and so that a panicking action leaves it unlocked.
+	actions, args, seq := ctrl.expectedActions(receiver, method, args)
 
+	var start time.Time
+	if ctrl.callObserver != nil {
+		start = time.Now()
+	}
 	var rets []interface{}
 	for _, action := range actions {
 		if r := action(args); r != nil {
 			rets = r
 		}
 	}
+	if ctrl.callObserver != nil && seq != 0 {
+		ctrl.observeCall(receiver, method, start, seq)
+	}
 
 	return rets
 }
 
+// expectedActions finds the expected call matching a call, does the
+// bookkeeping of the call and returns its actions, with the arguments to
+// pass them, and the sequence number of the call, or 0 if it matched no
+// expected call.
+func (ctrl *Controller) expectedActions(receiver interface{}, method string, args []interface{}) ([]func([]interface{}) []interface{}, []interface{}, uint64) {
+	ctrl.t.Helper()
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	if ctrl.idleTimeout > 0 {
+		ctrl.lastCall = time.Now()
+	}
+
+	// Failures are reported to the scope the call belongs to, if any.
+	cur := ctrl.current()
+	if cur.finished && !ctrl.useAfterFinish {
+		ctrl.stats.failed(receiver, method)
+		msg := fmt.Sprintf("gomock: controller already finished, unexpected call to %s.%v(%s) at %s",
+			ctrl.receiverName(receiver), method, formatArgs(args), callerInfo(2))
+		if ctrl.onOtherGoroutine() {
+			cur.t.Errorf("%s", msg)
+			return []func([]interface{}) []interface{}{ctrl.zeroReturns(receiver, method)}, args, 0
+		}
+		cur.t.Fatalf("%s", msg)
+	}
+
+	// Matchers and actions get the variadic arguments one by one, even
+	// if the mock passed them as a slice.
+	if mt := ctrl.expectedCalls.MethodType(receiver, method); mt != nil {
+		args = flattenVariadic(mt, args)
+	}
+
+	var caller uint64
+	if ctrl.isolated {
+		caller = cur.caller
+	}
+	expected, err := ctrl.expectedCalls.FindMatchFrom(caller, receiver, method, args)
+	if ctrl.verbose {
+		ctrl.journalCall(receiver, method, args, callerInfo(2), expected, err)
+	}
+	if err != nil {
+		ctrl.stats.failed(receiver, method)
+		if ctrl.foreignReceiver(receiver) {
+			err = fmt.Errorf("receiver %T was created with a different gomock.Controller", receiver)
+		}
+		origin := callerInfo(2)
+		msg := fmt.Sprintf("Unexpected call to %s.%v(%s) at %s because: %s", ctrl.receiverName(receiver), method, formatArgs(args), origin, err)
+		if _, forbidden := err.(*forbiddenCallError); ctrl.relaxed && !forbidden {
+			logf(cur.t, "%s\nThe call returns zero values, as the controller is relaxed.", msg)
+			return []func([]interface{}) []interface{}{ctrl.zeroReturns(receiver, method)}, args, 0
+		}
+		if ctrl.onOtherGoroutine() {
+			// Fatalf can't stop the test from another goroutine, so the
+			// call returns zero values and Finish fails the test.
+			cur.t.Errorf("%s", msg)
+			cur.otherGoroutineFailures = append(cur.otherGoroutineFailures, msg)
+			return []func([]interface{}) []interface{}{ctrl.zeroReturns(receiver, method)}, args, 0
+		}
+		cur.t.Fatalf("%s", msg)
+	}
+
+	// Two things happen here:
+	// * the matching call no longer needs to check prerequite calls,
+	// * and the prerequite calls are no longer expected, so remove them.
+	preReqCalls := expected.dropPrereqs()
+	for _, preReqCall := range preReqCalls {
+		ctrl.expectedCalls.Remove(preReqCall)
+	}
+
+	ctrl.numCalls++
+	ctrl.stats.matched(receiver, method)
+	actions := expected.call(receiver, args, ctrl.numCalls)
+	if ctrl.checkpointed {
+		ctrl.records = append(ctrl.records, newCallRecord(receiver, method, args, ctrl.numCalls))
+	}
+	if ctrl.callsLogger != nil {
+		fmt.Fprintf(ctrl.callsLogger, "%s.%v(%s) at %s matched expected call at %s\n",
+			ctrl.receiverName(receiver), method, formatArgs(args), callerInfo(2), expected.origin)
+	}
+	if expected.exhausted() {
+		ctrl.expectedCalls.Remove(expected)
+	}
+	ctrl.notifyChanged()
+	return actions, args, ctrl.numCalls
+}
+
+// flattenVariadic returns args with the variadic arguments of a method of
+// type mt flattened, if they were passed packed into a slice. A slice that
+// could be a variadic argument by itself, like an []interface{} passed for
+// ...interface{}, is left as is.
+func flattenVariadic(mt reflect.Type, args []interface{}) []interface{} {
+	if !mt.IsVariadic() || len(args) != mt.NumIn() {
+		return args
+	}
+	vt := mt.In(mt.NumIn() - 1)
+	last := args[len(args)-1]
+	if last == nil || reflect.TypeOf(last) != vt || vt.AssignableTo(vt.Elem()) {
+		return args
+	}
+	v := reflect.ValueOf(last)
+	flat := append(make([]interface{}, 0, len(args)-1+v.Len()), args[:len(args)-1]...)
+	for i := 0; i < v.Len(); i++ {
+		flat = append(flat, v.Index(i).Interface())
+	}
+	return flat
+}
+
+// Finish checks that all the expected calls have been made. It is called
+// automatically when the test completes if the TestReporter supports
+// Cleanup, and may be called more than once. Deferred by a test which
+// panics, it logs the missing calls with Errorf, then stops the panic and
+// fails the test with Fatalf, with the panic's value and stack.
 func (ctrl *Controller) Finish() {
-	if h, ok := ctrl.t.(testHelper); ok {
-		h.Helper()
+	ctrl.t.Helper()
+	if ctrl.owner != nil {
+		// A handle passed by Go finishes the controller it acts for.
+		ctrl = ctrl.owner
+	}
+	if ctrl.cancel != nil {
+		// Deferred first, to run even if Finish fails the test, once the
+		// controller is unlocked.
+		defer ctrl.cancel()
 	}
 
 	ctrl.mu.Lock()
 	defer ctrl.mu.Unlock()
 
+	// Finish may be called more than once, e.g. with defer and by Cleanup,
+	// but only checks the calls once.
 	if ctrl.finished {
-		ctrl.t.Fatalf("Controller.Finish was called more than once. It has to be called exactly once.")
+		if n := ctrl.numRecorded - ctrl.numFinished; n != 0 {
+			ctrl.t.Fatalf("%d call(s) were recorded after Controller.Finish was called, they can't be checked", n)
+		}
+		return
 	}
 	ctrl.finished = true
+	ctrl.numFinished = ctrl.numRecorded
+	for _, restore := range ctrl.restoreFuncs {
+		restore()
+	}
+	ctrl.restoreFuncs = nil
+	failures := ctrl.failures()
+	for _, c := range failures {
+		ctrl.stats.failed(c.receiver, c.method)
+	}
+	if ctrl.scoped {
+		// The calls of the scope aren't expected by its siblings.
+		ctrl.expectedCalls.DeleteFunc(func(c *Call) bool { return c.ctrl == ctrl })
+		for i, scope := range ctrl.scopes {
+			if scope == ctrl {
+				ctrl.scopes = append(ctrl.scopes[:i], ctrl.scopes[i+1:]...)
+				break
+			}
+		}
+	} else {
+		ctrl.unregister()
+		if ctrl.stopIdle != nil {
+			close(ctrl.stopIdle)
+		}
+	}
 
 	// If we're currently panicking, probably because this is a deferred call,
-	// pass through the panic.
-	if err := recover(); err != nil {
-		panic(err)
+	// stop the panic and report it with Fatalf, after the missing calls,
+	// which are only logged with Errorf: failing the test for them would
+	// mask the panic. The Fatalf of an ErrorReporter has recorded its
+	// failure already, so its panic needs no report.
+	if r := recover(); r != nil {
+		if len(failures) != 0 {
+			ctrl.t.Errorf("missing call(s) when the test panicked:\n%s", newExpectationReport(failures))
+		}
+		if _, ok := r.(fatalFailure); ok {
+			return
+		}
+		ctrl.t.Fatalf("the test panicked: %v\n%s", r, debug.Stack())
 	}
 
 	// Check that all remaining expected calls are satisfied.
+	if len(failures) != 0 {
+		ctrl.t.Fatalf("aborting test due to missing call(s):\n%s", newExpectationReport(failures))
+	}
+	if fs := ctrl.otherGoroutineFailures; len(fs) != 0 {
+		ctrl.t.Fatalf("aborting test due to %d unexpected call(s) from other goroutines:\n%s", len(fs), strings.Join(fs, "\n"))
+	}
+}
+
+// Satisfied reports whether all the expected calls have been made at least
+// as many times as they have to be, i.e. whether Finish would pass.
+func (ctrl *Controller) Satisfied() bool {
+	return len(ctrl.PendingCalls()) == 0
+}
+
+// PendingCalls returns the expected calls which haven't been made as many
+// times as they have to be yet, in the order they were recorded.
+func (ctrl *Controller) PendingCalls() []*Call {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	return ctrl.failures()
+}
+
+// WaitForSatisfaction blocks until all the expected calls are satisfied, or
+// timeout has elapsed. In the latter case, the error lists the calls which
+// are still pending.
+func (ctrl *Controller) WaitForSatisfaction(timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		pending, changed := ctrl.pendingCallsAndChanged()
+		if len(pending) == 0 {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			if pending, _ = ctrl.pendingCallsAndChanged(); len(pending) == 0 {
+				return nil
+			}
+			sort.Stable(byReportOrder(pending))
+			var buf bytes.Buffer
+			for _, call := range pending {
+				fmt.Fprintf(&buf, "\n%v", call)
+			}
+			return fmt.Errorf("gomock: %d expected call(s) not satisfied within %v:%s", len(pending), timeout, buf.String())
+		}
+	}
+}
+
+// pendingCallsAndChanged returns the pending calls, and a channel closed
+// on the next change to them.
+func (ctrl *Controller) pendingCallsAndChanged() ([]*Call, <-chan struct{}) {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	if ctrl.changed == nil {
+		ctrl.changed = make(chan struct{})
+	}
+	return ctrl.failures(), ctrl.changed
+}
+
+// notifyChanged wakes up WaitForSatisfaction. It must be called with the
+// lock held.
+func (ctrl *Controller) notifyChanged() {
+	if ctrl.changed != nil {
+		close(ctrl.changed)
+		ctrl.changed = nil
+	}
+}
+
+// Scope returns a controller for the expectations of a subtest, which
+// shares the mocks of ctrl but reports failures to t, e.g.
+//
+//	ctrl := gomock.NewController(t)
+//	m := NewMockFoo(ctrl)
+//	for _, tc := range cases {
+//		t.Run(tc.name, func(t *testing.T) {
+//			ctrl.Scope(t)
+//			m.EXPECT().Get(tc.key).Return(tc.value)
+//			...
+//		})
+//	}
+//
+// The calls recorded and made with the scope, or with the mocks created with
+// it, belong to the scope, and so do those recorded and made with ctrl until
+// the scope finishes, or a newer one is created: the unexpected calls are
+// reported to t. The Finish method of the scope, registered with t.Cleanup
+// if t has one, checks the calls of the scope only, and removes them all, so
+// that they don't affect the other subtests. Finish on ctrl checks the calls
+// which are left. Subtests which run in parallel have their scopes live at
+// the same time, so they have to use their scope rather than ctrl, see
+// WithCallerIsolation.
+func (ctrl *Controller) Scope(t TestReporter) *Controller {
+	scope := &Controller{
+		controllerState: ctrl.controllerState,
+		t:               newFatalExiter(t),
+		scoped:          true,
+	}
+	ctrl.mu.Lock()
+	scope.caller = ctrl.newCaller()
+	ctrl.scopes = append(ctrl.scopes, scope)
+	ctrl.mu.Unlock()
+	scope.registerCleanup(t)
+	return scope
+}
+
+// current returns the controller which the calls recorded and made with
+// ctrl belong to: the one a handle passed by Go acts for, the latest
+// unfinished scope if ctrl isn't a scope itself and there is one, or ctrl.
+// It must be called with the lock held.
+func (ctrl *Controller) current() *Controller {
+	if ctrl.owner != nil {
+		return ctrl.owner
+	}
+	if !ctrl.scoped && len(ctrl.scopes) != 0 {
+		return ctrl.scopes[len(ctrl.scopes)-1]
+	}
+	return ctrl
+}
+
+// Go runs f in a new goroutine, passing it a handle on ctrl which acts for
+// the controller the calls made with ctrl belong to: with
+// WithCallerIsolation, the calls made with the handle, or with the mocks
+// created with it, match the expected calls recorded with that controller,
+// and with Scope, their unexpected calls are reported to the scope:
+//
+//	ctrl.Go(func(ctrl *gomock.Controller) {
+//		worker.Run(NewMockFoo(ctrl))
+//	})
+//
+// As for any goroutine other than the test's, an unexpected call made with
+// the handle is reported with Errorf and returns zero values, and Finish
+// fails the test.
+// Calling Finish on the handle finishes the controller it acts for.
+func (ctrl *Controller) Go(f func(ctrl *Controller)) {
+	ctrl.mu.Lock()
+	owner := ctrl.current()
+	ctrl.mu.Unlock()
+
+	h := &Controller{
+		controllerState: ctrl.controllerState,
+		t:               owner.t,
+		owner:           owner,
+		caller:          owner.caller,
+	}
+	go f(h)
+}
+
+// onOtherGoroutine reports whether a call made with ctrl comes from a
+// goroutine other than the test's: that of a handle passed by Go, or any
+// goroutine without the test's at the bottom of its stack, for a controller
+// created by a test.
+func (ctrl *Controller) onOtherGoroutine() bool {
+	return ctrl.owner != nil || (ctrl.byTest && !onTestGoroutine())
+}
+
+// testRunners are the functions of package testing at the bottom of the
+// stacks of the goroutines running tests, benchmarks and examples.
+var testRunners = map[string]bool{
+	"testing.tRunner":    true,
+	"testing.(*B).runN":  true,
+	"testing.runExample": true,
+}
+
+// onTestGoroutine reports whether the calling goroutine runs a test, a
+// benchmark or an example, rather than being started by one. It only looks
+// up the functions in the stack, as goroutines have no identity in Go.
+func onTestGoroutine() bool {
+	pcs := make([]uintptr, 64)
+	for {
+		n := runtime.Callers(2, pcs)
+		if n < len(pcs) {
+			pcs = pcs[:n]
+			break
+		}
+		pcs = make([]uintptr, 2*len(pcs))
+	}
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		if testRunners[f.Function] {
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
+
+// newCaller returns a new token for a caller, see WithCallerIsolation. It
+// must be called with the lock held, or before the controller is shared.
+func (ctrl *Controller) newCaller() uint64 {
+	ctrl.lastCaller++
+	return ctrl.lastCaller
+}
+
+// failures returns the expected calls which aren't satisfied among those
+// ctrl checks: those recorded with it if it is a scope, all of them
+// otherwise. It must be called with the lock held.
+func (ctrl *Controller) failures() []*Call {
 	failures := ctrl.expectedCalls.Failures()
-	for _, call := range failures {
-		ctrl.t.Errorf("missing call(s) to %v", call)
+	if !ctrl.scoped {
+		return failures
 	}
-	if len(failures) != 0 {
-		ctrl.t.Fatalf("aborting test due to missing call(s)")
+	owned := failures[:0]
+	for _, c := range failures {
+		if c.ctrl == ctrl {
+			owned = append(owned, c)
+		}
+	}
+	return owned
+}
+
+// zeroReturns returns an action returning the zero values of the method's
+// results, for an unexpected call which can't fail the test right away.
+func (ctrl *Controller) zeroReturns(receiver interface{}, method string) func([]interface{}) []interface{} {
+	mt := ctrl.expectedCalls.MethodType(receiver, method)
+	if mt == nil {
+		if m := reflect.ValueOf(receiver).MethodByName(method); m.IsValid() {
+			mt = m.Type()
+		}
+	}
+	return func([]interface{}) []interface{} {
+		if mt == nil {
+			return nil
+		}
+		rets := make([]interface{}, mt.NumOut())
+		for i := range rets {
+			rets[i] = reflect.Zero(mt.Out(i)).Interface()
+		}
+		return rets
 	}
 }
 
@@ -211,7 +1091,49 @@ func callerInfo(skip int) string {
 	return "unknown file"
 }
 
-type testHelper interface {
+// nopTestHelper is the TestHelper used with a TestReporter which isn't one.
+type nopTestHelper struct {
 	TestReporter
-	Helper()
+}
+
+func (nopTestHelper) Helper() {}
+func (h nopTestHelper) Logf(format string, args ...interface{}) {
+	logf(h.TestReporter, format, args...)
+}
+
+// fatalExiter is the TestHelper a controller reports to. Its Fatalf ends the
+// calling goroutine with runtime.Goexit, as the Fatalf of *testing.T does,
+// if the Fatalf of the TestReporter returns, so that the code of gomock
+// after a fatal failure never runs. A TestReporter may panic instead.
+type fatalExiter struct {
+	TestHelper
+}
+
+func newFatalExiter(t TestReporter) fatalExiter {
+	h, ok := t.(TestHelper)
+	if !ok {
+		h = nopTestHelper{t}
+	}
+	return fatalExiter{h}
+}
+
+func (r fatalExiter) Fatalf(format string, args ...interface{}) {
+	r.TestHelper.Helper()
+	r.TestHelper.Fatalf(format, args...)
+	runtime.Goexit()
+}
+func (r fatalExiter) Logf(format string, args ...interface{}) {
+	logf(r.TestHelper, format, args...)
+}
+
+// A testLogger is a TestReporter which can log, as *testing.T does.
+type testLogger interface {
+	Logf(format string, args ...interface{})
+}
+
+// logf logs to t if it can log, and does nothing otherwise.
+func logf(t TestReporter, format string, args ...interface{}) {
+	if l, ok := t.(testLogger); ok {
+		l.Logf(format, args...)
+	}
 }
@@ -15,13 +15,19 @@
 package gomock_test
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"reflect"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 
 	"strings"
 
 	"github.com/golang/mock/gomock"
+	"golang.org/x/net/context"
 )
 
 type ErrorReporter struct {
@@ -135,6 +141,8 @@ func (s *Subject) BarMethod(arg string) int {
 
 func (s *Subject) VariadicMethod(arg int, vararg ...string) {}
 
+func (s *Subject) LogMethod(format string, args ...interface{}) {}
+
 // A type purely for ActOnTestStructMethod
 type TestStruct struct {
 	Number  int
@@ -147,6 +155,26 @@ func (s *Subject) ActOnTestStructMethod(arg TestStruct, arg1 int) int {
 
 func (s *Subject) SetArgMethod(sliceArg []byte, ptrArg *int) {}
 
+func (s *Subject) FuncArgMethod(f func(string) int) {}
+
+func (s *Subject) OutputMethod(cfg *TestStruct, m map[string]int, dst interface{}, out ...*int) {}
+
+func (s *Subject) GetMethod(key int) (string, error) {
+	return "", nil
+}
+
+func (s *Subject) StructMethod() (TestStruct, *TestStruct, []int, fmt.Stringer) {
+	return TestStruct{}, nil, nil, nil
+}
+
+func (s *Subject) DecodeMethod(src string, dst *TestStruct) error {
+	return nil
+}
+
+type notFoundError struct{}
+
+func (*notFoundError) Error() string { return "not found" }
+
 func assertEqual(t *testing.T, expected interface{}, actual interface{}) {
 	if !reflect.DeepEqual(expected, actual) {
 		t.Errorf("Expected %+v, but got %+v", expected, actual)
@@ -162,6 +190,35 @@ func createFixtures(t *testing.T) (reporter *ErrorReporter, ctrl *gomock.Control
 	return
 }
 
+// helperReporter is an ErrorReporter that records the functions which
+// called Helper.
+type helperReporter struct {
+	*ErrorReporter
+	helpers map[string]bool
+}
+
+func (r *helperReporter) Helper() {
+	pc, _, _, _ := runtime.Caller(1)
+	r.helpers[runtime.FuncForPC(pc).Name()] = true
+}
+
+func TestHelperCalls(t *testing.T) {
+	rep := &helperReporter{NewErrorReporter(t), make(map[string]bool)}
+	defer rep.recoverUnexpectedFatal()
+	ctrl := gomock.NewController(rep)
+	s := new(Subject)
+
+	ctrl.RecordCall(s, "FooMethod", "argument").Return(1)
+	ctrl.Call(s, "FooMethod", "argument")
+	ctrl.Finish()
+
+	for _, f := range []string{"RecordCall", "Call", "Finish"} {
+		if name := "github.com/golang/mock/gomock.(*Controller)." + f; !rep.helpers[name] {
+			t.Errorf("%s didn't call Helper, only %v did", name, rep.helpers)
+		}
+	}
+}
+
 func TestNoCalls(t *testing.T) {
 	reporter, ctrl := createFixtures(t)
 	ctrl.Finish()
@@ -231,6 +288,101 @@ func TestRepeatedCall(t *testing.T) {
 	reporter.assertFail("After calling one too many times.")
 }
 
+// int64Matcher is a TypedMatcher for positive int64 values.
+type int64Matcher struct{}
+
+func (int64Matcher) Matches(x interface{}) bool { v, ok := x.(int64); return ok && v > 0 }
+func (int64Matcher) String() string             { return "is a positive int64" }
+func (int64Matcher) ExpectedType() reflect.Type { return reflect.TypeOf(int64(0)) }
+
+func TestRecordCallArgChecks(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	s := new(Subject)
+
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "FooMethod", "a", "b", "c")
+	}, "invalid arguments for *gomock_test.Subject.FooMethod: got 3 arguments, want 1", "controller_test.go:")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "ActOnTestStructMethod", TestStruct{})
+	}, "invalid arguments for *gomock_test.Subject.ActOnTestStructMethod: got 1 arguments, want 2")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "VariadicMethod")
+	}, "invalid arguments for *gomock_test.Subject.VariadicMethod: got 0 arguments, want at least 1")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "FooMethod", 1)
+	}, "argument 0 is a int, which is not assignable to string")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "ActOnTestStructMethod", TestStruct{}, nil)
+	}, "argument 1 is nil, but int is not nillable")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "VariadicMethod", 0, "a", 1)
+	}, "argument 2 is a int, which is not assignable to string")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "VariadicMethod", 0, "a", []string{"b"})
+	}, "argument 2 is a []string, which is not assignable to string")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "FooMethod", int64Matcher{})
+	}, "argument 0 is a matcher for int64, which is not assignable to string")
+	ctrl.Finish()
+}
+
+func TestRecordCallValidArgs(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	s := new(Subject)
+
+	ctrl.RecordCall(s, "VariadicMethod", 0)
+	ctrl.RecordCall(s, "VariadicMethod", 1, "a", "b")
+	ctrl.RecordCall(s, "VariadicMethod", 2, []string{"a", "b"})
+	ctrl.RecordCall(s, "LogMethod", "%v %v", 1, "a")
+	ctrl.RecordCall(s, "LogMethod", "%v", nil)
+	ctrl.RecordCall(s, "SetArgMethod", nil, new(int))
+	ctrl.RecordCall(s, "OutputMethod", &TestStruct{}, map[string]int{}, 1, gomock.Any())
+	ctrl.RecordCall(s, "FuncArgMethod", gomock.Any())
+	ctrl.RecordCall(s, "LogMethod", "%v", int64Matcher{})
+	rep.assertPass("RecordCall with valid arguments")
+
+	ctrl.Call(s, "VariadicMethod", 0)
+	ctrl.Call(s, "VariadicMethod", 1, "a", "b")
+	ctrl.Call(s, "VariadicMethod", 2, "a", "b")
+	ctrl.Call(s, "LogMethod", "%v %v", 1, "a")
+	ctrl.Call(s, "LogMethod", "%v", nil)
+	ctrl.Call(s, "SetArgMethod", []byte(nil), new(int))
+	ctrl.Call(s, "OutputMethod", &TestStruct{}, map[string]int{}, 1, new(int))
+	ctrl.Call(s, "FuncArgMethod", func(string) int { return 0 })
+	ctrl.Call(s, "LogMethod", "%v", int64(1))
+	ctrl.Finish()
+}
+
+func TestRecordCallByFunc(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	s := new(Subject)
+
+	call := ctrl.RecordCallByFunc(s, s.FooMethod, "value").Return(1)
+	if !strings.Contains(call.Origin(), "controller_test.go") {
+		t.Errorf("got origin %s, want the caller of RecordCallByFunc", call.Origin())
+	}
+	ctrl.RecordCallByFunc(s, (*Subject).BarMethod, "expression").Return(2)
+	assertEqual(t, []interface{}{1}, ctrl.Call(s, "FooMethod", "value"))
+	assertEqual(t, []interface{}{2}, ctrl.Call(s, "BarMethod", "expression"))
+
+	rep.assertFatal(func() {
+		ctrl.RecordCallByFunc(s, rep.assertPass, "x")
+	}, "gomock: github.com/golang/mock/gomock_test.(*ErrorReporter).assertPass-fm is not a method of *gomock_test.Subject")
+	rep.assertFatal(func() {
+		ctrl.RecordCallByFunc(s, strings.ToUpper, "x")
+	}, "gomock: strings.ToUpper is not a method of *gomock_test.Subject")
+	rep.assertFatal(func() {
+		ctrl.RecordCallByFunc(s, func(string) int { return 0 }, "x")
+	}, "is not a method of *gomock_test.Subject")
+	rep.assertFatal(func() {
+		ctrl.RecordCallByFunc(s, "FooMethod", "x")
+	}, "gomock: string is not a method of *gomock_test.Subject")
+	ctrl.Finish()
+}
+
 func TestUnexpectedArgCount(t *testing.T) {
 	reporter, ctrl := createFixtures(t)
 	defer reporter.recoverUnexpectedFatal()
@@ -289,6 +441,135 @@ func TestUnexpectedArgValue_FirstArg(t *testing.T) {
 	})
 }
 
+func TestUnexpectedArgValue_GotFormatter(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	defer reporter.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	expectedArg0 := TestStruct{Number: 123, Message: "hello"}
+	ctrl.RecordCall(
+		subject,
+		"ActOnTestStructMethod",
+		gomock.GotFormatterAdapter(
+			gomock.GotFormatterFunc(func(i interface{}) string {
+				return fmt.Sprintf("TestStruct with Number %d", i.(TestStruct).Number)
+			}),
+			gomock.Eq(expectedArg0),
+		),
+		15,
+	)
+
+	reporter.assertFatal(func() {
+		ctrl.Call(subject, "ActOnTestStructMethod", TestStruct{Number: 11, Message: "no message"}, 15)
+	}, "Unexpected call to", "doesn't match the argument at index 0",
+		"Got: TestStruct with Number 11\nWant: is equal to {123 hello}")
+
+	reporter.assertFatal(func() {
+		// The expected call wasn't made.
+		ctrl.Finish()
+	})
+}
+
+func TestUnexpectedArgValue_WantFormatter(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	defer reporter.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	expectedArg0 := TestStruct{Number: 123, Message: "hello"}
+	ctrl.RecordCall(
+		subject,
+		"ActOnTestStructMethod",
+		gomock.WantFormatter(
+			gomock.StringerFunc(func() string { return "a valid TestStruct" }),
+			gomock.Eq(expectedArg0),
+		),
+		15,
+	)
+
+	reporter.assertFatal(func() {
+		ctrl.Call(subject, "ActOnTestStructMethod", TestStruct{Number: 11, Message: "no message"}, 15)
+	}, "Unexpected call to", "doesn't match the argument at index 0",
+		"Got: {11 no message}\nWant: a valid TestStruct")
+
+	reporter.assertFatal(func() {
+		// The expected call wasn't made.
+		ctrl.Finish()
+	})
+}
+
+func TestFieldsMatcherUnknownField(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	subject := new(Subject)
+
+	reporter.assertFatal(func() {
+		ctrl.RecordCall(subject, "ActOnTestStructMethod", gomock.Fields(map[string]interface{}{"Nmber": 1}), 15)
+	}, "invalid matcher for argument 0", "gomock_test.TestStruct has no field Nmber")
+
+	reporter.assertFatal(func() {
+		ctrl.RecordCall(subject, "ActOnTestStructMethod", gomock.Any(), gomock.Fields(map[string]interface{}{"Number": 1}))
+	}, "invalid matcher for argument 1", "not a struct")
+}
+
+func TestUnexpectedArgValue_InvalidJSON(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	defer reporter.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", gomock.JSONEq(`{"a": 1}`))
+	reporter.assertFatal(func() {
+		ctrl.Call(subject, "FooMethod", `{"a": 1`)
+	}, "doesn't match the argument at index 0", "Got: {\"a\": 1 (invalid JSON: unexpected end of JSON input)")
+
+	reporter.assertFatal(func() {
+		// The expected call wasn't made.
+		ctrl.Finish()
+	})
+}
+
+func TestUnexpectedArgValue_MatcherFunc(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	defer reporter.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	isShort := gomock.MatcherFunc{
+		Func:        func(x interface{}) bool { return len(x.(string)) < 4 },
+		Description: "is a string shorter than 4 bytes",
+	}
+	ctrl.RecordCall(subject, "FooMethod", isShort)
+	reporter.assertFatal(func() {
+		ctrl.Call(subject, "FooMethod", "too long")
+	}, "doesn't match the argument at index 0", "Got: too long\nWant: is a string shorter than 4 bytes")
+	ctrl.Call(subject, "FooMethod", "ok")
+	ctrl.Finish()
+}
+
+func TestNilArgMatchesTypedNils(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	defer reporter.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "SetArgMethod", nil, nil)
+	reporter.assertFatal(func() {
+		ctrl.Call(subject, "SetArgMethod", []byte{}, (*int)(nil))
+	}, "doesn't match the argument at index 0", "Want: is nil")
+	ctrl.Call(subject, "SetArgMethod", []byte(nil), (*int)(nil))
+	ctrl.Finish()
+}
+
+func TestFuncArgs(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	defer reporter.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	reporter.assertFatal(func() {
+		ctrl.RecordCall(subject, "FuncArgMethod", subject.FooMethod)
+	}, "invalid matcher for argument 0", "use gomock.SameFunc")
+
+	ctrl.RecordCall(subject, "FuncArgMethod", gomock.SameFunc(subject.FooMethod))
+	ctrl.Call(subject, "FuncArgMethod", subject.FooMethod)
+	ctrl.Finish()
+}
+
 func TestUnexpectedArgValue_SecondtArg(t *testing.T) {
 	reporter, ctrl := createFixtures(t)
 	defer reporter.recoverUnexpectedFatal()
@@ -308,6 +589,49 @@ func TestUnexpectedArgValue_SecondtArg(t *testing.T) {
 	})
 }
 
+func TestUnexpectedCallListsCandidates(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	arg0 := TestStruct{Number: 123, Message: "hello"}
+	ctrl.RecordCall(subject, "ActOnTestStructMethod", arg0, 15).Name("second arg").AnyTimes()
+	ctrl.RecordCall(subject, "ActOnTestStructMethod", TestStruct{}, 3).Name("first arg").AnyTimes()
+
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "ActOnTestStructMethod", arg0, 3)
+	}, `Expected call "second arg" at`, "doesn't match the argument at index 1.\nGot: 3\nWant: is equal to 15",
+		`Expected call "first arg" at`, "doesn't match the argument at index 0.")
+
+	msg := rep.log[len(rep.log)-1]
+	if strings.Index(msg, "second arg") > strings.Index(msg, "first arg") {
+		t.Errorf("candidates aren't listed in recording order: %s", msg)
+	}
+	ctrl.Finish()
+}
+
+func TestUnexpectedCallBlockedByPrereq(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	first := ctrl.RecordCall(subject, "FooMethod", "1").Name("first")
+	ctrl.RecordCall(subject, "BarMethod", "2").Name("second").After(first).AnyTimes()
+
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "BarMethod", "2")
+	}, `Expected call "second" at`, "doesn't have a prerequisite call satisfied",
+		`*gomock_test.Subject.FooMethod(is equal to 1) named "first"`)
+
+	msg := rep.log[len(rep.log)-1]
+	if strings.Contains(msg, "doesn't match the argument") {
+		t.Errorf("prerequisite failure reported as an argument mismatch: %s", msg)
+	}
+	ctrl.Call(subject, "FooMethod", "1")
+	ctrl.Call(subject, "BarMethod", "2")
+	ctrl.Finish()
+}
+
 func TestAnyTimes(t *testing.T) {
 	reporter, ctrl := createFixtures(t)
 	subject := new(Subject)
@@ -371,6 +695,179 @@ func TestMaxTimes1(t *testing.T) {
 	ctrl.Finish()
 }
 
+func TestMissingCallDescription(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "ActOnTestStructMethod", gomock.Any(), 3).MinTimes(2)
+	ctrl.Call(subject, "ActOnTestStructMethod", TestStruct{}, 3)
+	rep.assertFatal(ctrl.Finish, "aborting test due to missing call(s)")
+
+	got := rep.log[len(rep.log)-1]
+	for _, want := range []string{
+		"*gomock_test.Subject.ActOnTestStructMethod:\n\t(is anything, is equal to 3) registered at ",
+		"controller_test.go:",
+		", expected 2..unlimited calls, got 1",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing call error %q doesn't contain %q", got, want)
+		}
+	}
+}
+
+type Other struct{}
+
+func (o *Other) FooMethod(arg string) int { return 0 }
+
+func TestMissingCallsReport(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	subject, other := new(Subject), new(Other)
+
+	// foo1 is recorded after foo2, on a line before foo2's.
+	recordFoo1 := func() *gomock.Call {
+		return ctrl.RecordCall(subject, "FooMethod", "1")
+	}
+	bar := ctrl.RecordCall(subject, "BarMethod", "b")
+	foo2 := ctrl.RecordCall(subject, "FooMethod", "2").Times(2)
+	otherFoo := ctrl.RecordCall(other, "FooMethod", "o").MinTimes(1)
+	foo1 := recordFoo1()
+	ctrl.RecordCall(subject, "FooMethod", "3")
+	ctrl.Call(subject, "FooMethod", "2")
+	ctrl.Call(subject, "FooMethod", "3")
+
+	report := ctrl.Report()
+	want := &gomock.ExpectationReport{Groups: []gomock.MissingCallGroup{
+		{Receiver: "*gomock_test.Other", Method: "FooMethod", Calls: []gomock.MissingCall{
+			{Args: []string{"is equal to o"}, Origin: otherFoo.Origin(), MinCalls: 1, MaxCalls: -1},
+		}},
+		{Receiver: "*gomock_test.Subject", Method: "BarMethod", Calls: []gomock.MissingCall{
+			{Args: []string{"is equal to b"}, Origin: bar.Origin(), MinCalls: 1, MaxCalls: 1},
+		}},
+		{Receiver: "*gomock_test.Subject", Method: "FooMethod", Calls: []gomock.MissingCall{
+			{Args: []string{"is equal to 1"}, Origin: foo1.Origin(), MinCalls: 1, MaxCalls: 1},
+			{Args: []string{"is equal to 2"}, Origin: foo2.Origin(), MinCalls: 2, MaxCalls: 2, NumCalls: 1},
+		}},
+	}}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("got report %+v, want %+v", report, want)
+	}
+
+	rep.assertFatal(ctrl.Finish, "aborting test due to missing call(s):\n"+
+		"*gomock_test.Other.FooMethod:\n"+
+		"\t(is equal to o) registered at "+otherFoo.Origin()+", expected 1..unlimited calls, got 0\n"+
+		"*gomock_test.Subject.BarMethod:\n"+
+		"\t(is equal to b) registered at "+bar.Origin()+", expected 1..1 calls, got 0\n"+
+		"*gomock_test.Subject.FooMethod:\n"+
+		"\t(is equal to 1) registered at "+foo1.Origin()+", expected 1..1 calls, got 0\n"+
+		"\t(is equal to 2) registered at "+foo2.Origin()+", expected 2..2 calls, got 1")
+	if n := len(rep.log); n != 1 {
+		t.Errorf("Finish reported %d failures, want 1: %q", n, rep.log)
+	}
+}
+
+func TestReportOrderIsDeterministic(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		rep := NewErrorReporter(t)
+		// Both mocks are described the same, so only the origins of their
+		// calls tell them apart.
+		ctrl := gomock.NewController(rep, gomock.WithNameForReceiver(func(interface{}) string { return "mock" }))
+		subject, other := new(Subject), new(Other)
+
+		recordOtherFoo := func() *gomock.Call {
+			return ctrl.RecordCall(other, "FooMethod", "b")
+		}
+		subjectFoo := ctrl.RecordCall(subject, "FooMethod", "a").Times(2)
+		bar := ctrl.RecordCall(subject, "BarMethod", "c")
+		otherFoo := recordOtherFoo()
+		ctrl.Call(subject, "FooMethod", "a")
+
+		err := ctrl.WaitForSatisfaction(time.Millisecond)
+		want := "gomock: 3 expected call(s) not satisfied within 1ms:\n" +
+			"mock.BarMethod(is equal to c) registered at " + bar.Origin() + ", expected 1..1 calls, got 0\n" +
+			"mock.FooMethod(is equal to b) registered at " + otherFoo.Origin() + ", expected 1..1 calls, got 0\n" +
+			"mock.FooMethod(is equal to a) registered at " + subjectFoo.Origin() + ", expected 2..2 calls, got 1"
+		if err == nil || err.Error() != want {
+			t.Fatalf("WaitForSatisfaction returned:\n%v\nwant:\n%s", err, want)
+		}
+
+		finishWhilePanicking(ctrl)
+		// The panic itself is reported after the missing calls.
+		wantLog := "missing call(s) when the test panicked:\n" +
+			"mock.BarMethod:\n" +
+			"\t(is equal to c) registered at " + bar.Origin() + ", expected 1..1 calls, got 0\n" +
+			"mock.FooMethod:\n" +
+			"\t(is equal to b) registered at " + otherFoo.Origin() + ", expected 1..1 calls, got 0\n" +
+			"mock.FooMethod:\n" +
+			"\t(is equal to a) registered at " + subjectFoo.Origin() + ", expected 2..2 calls, got 1"
+		if len(rep.log) != 2 || rep.log[0] != wantLog || !strings.HasPrefix(rep.log[1], "the test panicked: boom\n") {
+			t.Fatalf("Finish reported:\n%q\nwant:\n%q\nthen the panic", rep.log, wantLog)
+		}
+	}
+}
+
+func TestNamedCalls(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "a").Name("first lookup")
+	ctrl.RecordCall(subject, "FooMethod", "b").Name("second lookup")
+
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "FooMethod", "c")
+	}, `Expected call "first lookup" at `, `Expected call "second lookup" at `)
+
+	ctrl.Call(subject, "FooMethod", "a")
+	rep.assertFatal(ctrl.Finish, "aborting test due to missing call(s)")
+	got := rep.log[len(rep.log)-1]
+	if want := `*gomock_test.Subject.FooMethod:` + "\n\t" + `(is equal to b) named "second lookup" registered at `; !strings.Contains(got, want) {
+		t.Errorf("missing call error %q doesn't contain %q", got, want)
+	}
+}
+
+func TestExhaustedCallError(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "argument").MaxTimes(2)
+	ctrl.Call(subject, "FooMethod", "argument")
+	ctrl.Call(subject, "FooMethod", "argument")
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "FooMethod", "argument")
+	}, "Unexpected call to *gomock_test.Subject.FooMethod([argument])",
+		"expected call to *gomock_test.Subject.FooMethod has already been made the max allowed number of times (2):",
+		"registered at", "expected 0..2 calls, got 2")
+	ctrl.Finish()
+}
+
+func TestExhaustedCallErrorListsCandidates(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "argument").Times(2)
+	ctrl.RecordCall(subject, "FooMethod", "argument").Name("again")
+	ctrl.RecordCall(subject, "FooMethod", "other").AnyTimes()
+	for i := 0; i < 3; i++ {
+		ctrl.Call(subject, "FooMethod", "argument")
+	}
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "FooMethod", "argument")
+	}, "has already been made the max allowed number of times (3):",
+		"FooMethod(is equal to argument) registered at", "expected 2..2 calls, got 2",
+		`FooMethod(is equal to argument) named "again" registered at`, "expected 1..1 calls, got 1")
+
+	// An argument mismatch is still reported as such.
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "FooMethod", "unknown")
+	}, "doesn't match the argument at index 0")
+	msg := rep.log[len(rep.log)-1]
+	if strings.Contains(msg, "max allowed number of times") {
+		t.Errorf("argument mismatch reported as exhaustion: %s", msg)
+	}
+	ctrl.Finish()
+}
+
 func TestMinMaxTimes(t *testing.T) {
 	// It fails if there are less calls than specified
 	reporter, ctrl := createFixtures(t)
@@ -463,250 +960,3211 @@ func TestDoAndReturn(t *testing.T) {
 	ctrl.Finish()
 }
 
-func TestSetArgSlice(t *testing.T) {
-	_, ctrl := createFixtures(t)
+func TestDoAndReturnEchoesArgument(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
 	subject := new(Subject)
 
-	var in = []byte{4, 5, 6}
-	var set = []byte{1, 2, 3}
-	ctrl.RecordCall(subject, "SetArgMethod", in, nil).SetArg(0, set)
-	ctrl.Call(subject, "SetArgMethod", in, nil)
-
-	if !reflect.DeepEqual(in, set) {
-		t.Error("Expected SetArg() to modify input slice argument")
+	ctrl.RecordCall(subject, "FooMethod", gomock.Any()).DoAndReturn(func(arg string) int {
+		return len(arg)
+	}).Times(2)
+	for _, arg := range []string{"a", "abc"} {
+		rets := ctrl.Call(subject, "FooMethod", arg)
+		if got, want := rets[0].(int), len(arg); got != want {
+			t.Errorf("FooMethod(%q) returned %d, want %d", arg, got, want)
+		}
 	}
-
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "FooMethod", "x")
+	}, "expected call", "has already been made the max allowed number of times (2)")
 	ctrl.Finish()
 }
 
-func TestSetArgPtr(t *testing.T) {
-	_, ctrl := createFixtures(t)
+func TestDoAndReturnSuccessiveValues(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
 	subject := new(Subject)
 
-	var in int = 43
-	const set = 42
-	ctrl.RecordCall(subject, "SetArgMethod", nil, &in).SetArg(1, set)
-	ctrl.Call(subject, "SetArgMethod", nil, &in)
-
-	if in != set {
-		t.Error("Expected SetArg() to modify value pointed to by argument")
+	calls := 0
+	ctrl.RecordCall(subject, "FooMethod", "argument").DoAndReturn(func(string) int {
+		calls++
+		if calls == 3 {
+			return -1
+		}
+		return calls
+	}).MinTimes(3)
+	var got []int
+	for i := 0; i < 4; i++ {
+		got = append(got, ctrl.Call(subject, "FooMethod", "argument")[0].(int))
 	}
-
 	ctrl.Finish()
-}
+
+	if want := []int{1, 2, -1, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DoAndReturn returned %v, want %v", got, want)
+	}
+}
+
+func TestDoAndReturnInvalidReturns(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "FooMethod", "argument").AnyTimes().DoAndReturn(func(string) {})
+	}, "wrong number of return values of the func passed to DoAndReturn for *gomock_test.Subject.FooMethod: got 0, want 1",
+		"controller_test.go:")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "FooMethod", "argument").AnyTimes().DoAndReturn(func(string) string { return "" })
+	}, "wrong type of return value 0 of the func passed to DoAndReturn for *gomock_test.Subject.FooMethod: string is not assignable to int")
+}
+
+func TestDoInvalidFunc(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "ActOnTestStructMethod", TestStruct{}, 1).AnyTimes().Do(func(TestStruct) {})
+	}, "wrong number of arguments of the func passed to Do for *gomock_test.Subject.ActOnTestStructMethod",
+		"got func(gomock_test.TestStruct), want func(gomock_test.TestStruct, int) int",
+		"controller_test.go:")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "FooMethod", "argument").AnyTimes().DoAndReturn(func(int) int { return 0 })
+	}, "wrong type of argument 0 of the func passed to DoAndReturn for *gomock_test.Subject.FooMethod: string is not assignable to int")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "FooMethod", "argument").AnyTimes().Do("not a func")
+	}, "argument to Do for *gomock_test.Subject.FooMethod is a string, not a func")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "FooMethod", "argument").AnyTimes().Do(func(string, ...int) {})
+	}, "wrong number of arguments of the func passed to Do")
+	// A func taking a concrete type can't receive every value of an
+	// interface parameter.
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "OutputMethod", gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
+			Do(func(*TestStruct, map[string]int, *TestStruct, ...*int) {})
+	}, "wrong type of argument 2 of the func passed to Do for *gomock_test.Subject.OutputMethod: interface {} is not assignable to *gomock_test.TestStruct")
+
+	// Parameters the method's arguments are assignable to are fine.
+	ctrl.RecordCall(subject, "FooMethod", "other").Do(func(interface{}) {})
+	ctrl.Call(subject, "FooMethod", "other")
+	ctrl.Finish()
+}
+
+func TestDoVariadic(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	var got [][]string
+	ctrl.RecordCall(subject, "VariadicMethod", 1, gomock.Any()).Do(func(_ int, s ...string) {
+		got = append(got, s)
+	})
+	ctrl.RecordCall(subject, "VariadicMethod", 2, gomock.Any()).Do(func(_ int, s []string) {
+		got = append(got, s)
+	}).Times(2)
+	ctrl.Call(subject, "VariadicMethod", 1, "a", "b")
+	ctrl.Call(subject, "VariadicMethod", 2, "c", "d")
+	ctrl.Call(subject, "VariadicMethod", 2)
+	ctrl.Finish()
+
+	want := [][]string{{"a", "b"}, {"c", "d"}, {}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Do got variadic arguments %q, want %q", got, want)
+	}
+
+	// The controller has finished, the setup errors need another one.
+	ctrl = gomock.NewController(rep)
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "VariadicMethod", 1).Do(func(int, ...int) {})
+	}, "wrong type of argument 1 of the func passed to Do", "string is not assignable to int")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "VariadicMethod", 1).Do(func(int, []int) {})
+	}, "wrong type of argument 1 of the func passed to Do", "[]string is not assignable to []int")
+}
+
+func TestDoVariadicSlice(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	var got [][]string
+	ctrl.RecordCall(subject, "VariadicMethod", 1, gomock.RestAny()).DoVariadicSlice(func(_ int, s []string) {
+		got = append(got, s)
+	}).Times(3)
+	ctrl.Call(subject, "VariadicMethod", 1, "a", "b")
+	ctrl.Call(subject, "VariadicMethod", 1)
+	// The variadic arguments passed as a slice are flattened first.
+	ctrl.Call(subject, "VariadicMethod", 1, []string{"c"})
+	ctrl.Finish()
+
+	want := [][]string{{"a", "b"}, {}, {"c"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DoVariadicSlice got variadic arguments %q, want %q", got, want)
+	}
+
+	// The controller has finished, the setup errors need another one.
+	ctrl = gomock.NewController(rep)
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "VariadicMethod", 1).DoVariadicSlice(func(int, ...string) {})
+	}, "the func passed to DoVariadicSlice for *gomock_test.Subject.VariadicMethod is variadic, want a []string parameter instead")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "FooMethod", "a").DoVariadicSlice(func(string) {})
+	}, "DoVariadicSlice for *gomock_test.Subject.FooMethod, which isn't variadic")
+}
+
+func TestVariadicArgsPassedAsSlice(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	var got [][]string
+	ctrl.RecordCall(subject, "VariadicMethod", 1, "a", "b").Do(func(_ int, s ...string) {
+		got = append(got, s)
+	})
+	ctrl.RecordCall(subject, "VariadicMethod", 2).Do(func(_ int, s ...string) {
+		got = append(got, s)
+	})
+	// A hand-written mock may pass the variadic arguments packed.
+	ctrl.Call(subject, "VariadicMethod", 1, []string{"a", "b"})
+	ctrl.Call(subject, "VariadicMethod", 2, []string(nil))
+	if want := [][]string{{"a", "b"}, {}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Do got variadic arguments %q, want %q", got, want)
+	}
+
+	// An []interface{} could be a single argument for ...interface{}, so it
+	// isn't flattened.
+	args := []interface{}{1, 2}
+	ctrl.RecordCall(subject, "LogMethod", "format", args)
+	ctrl.Call(subject, "LogMethod", "format", args)
+	ctrl.Finish()
+}
+
+func TestSetArgSlice(t *testing.T) {
+	_, ctrl := createFixtures(t)
+	subject := new(Subject)
+
+	var in = []byte{4, 5, 6}
+	var set = []byte{1, 2, 3}
+	ctrl.RecordCall(subject, "SetArgMethod", in, nil).SetArg(0, set)
+	ctrl.Call(subject, "SetArgMethod", in, nil)
+
+	if !reflect.DeepEqual(in, set) {
+		t.Error("Expected SetArg() to modify input slice argument")
+	}
+
+	ctrl.Finish()
+}
+
+func TestSetArgPtr(t *testing.T) {
+	_, ctrl := createFixtures(t)
+	subject := new(Subject)
+
+	var in int = 43
+	const set = 42
+	ctrl.RecordCall(subject, "SetArgMethod", nil, &in).SetArg(1, set)
+	ctrl.Call(subject, "SetArgMethod", nil, &in)
+
+	if in != set {
+		t.Error("Expected SetArg() to modify value pointed to by argument")
+	}
+
+	ctrl.Finish()
+}
+
+func TestSetArgSliceRespectsLength(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	in := []byte{0, 0}
+	ctrl.RecordCall(subject, "SetArgMethod", gomock.Any(), nil).SetArg(0, []byte("abc")).Times(2)
+	ctrl.Call(subject, "SetArgMethod", in, nil)
+	if string(in) != "ab" {
+		t.Errorf("SetArg() set %q, want %q", in, "ab")
+	}
+
+	in = []byte("xyzw")
+	ctrl.Call(subject, "SetArgMethod", in, nil)
+	if string(in) != "abcw" {
+		t.Errorf("SetArg() set %q, want %q", in, "abcw")
+	}
+	ctrl.Finish()
+}
+
+func TestSetArgOutputs(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	var cfg TestStruct
+	m := map[string]int{"a": 1}
+	var dst TestStruct
+	var out0, out1 int
+	ctrl.RecordCall(subject, "OutputMethod", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		SetArg(0, TestStruct{Number: 1, Message: "one"}).
+		SetArg(1, map[string]int{"b": 2}).
+		SetArg(2, TestStruct{Number: 2}).
+		SetArg(4, 4)
+	ctrl.Call(subject, "OutputMethod", &cfg, m, &dst, &out0, &out1)
+	ctrl.Finish()
+
+	if want := (TestStruct{Number: 1, Message: "one"}); cfg != want {
+		t.Errorf("SetArg() through a pointer set %v, want %v", cfg, want)
+	}
+	if want := map[string]int{"a": 1, "b": 2}; !reflect.DeepEqual(m, want) {
+		t.Errorf("SetArg() on a map set %v, want %v", m, want)
+	}
+	if want := (TestStruct{Number: 2}); dst != want {
+		t.Errorf("SetArg() through an interface set %v, want %v", dst, want)
+	}
+	if out0 != 0 || out1 != 4 {
+		t.Errorf("SetArg() on a variadic argument set %d and %d, want 0 and 4", out0, out1)
+	}
+}
+
+func TestSetArgInvalid(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "SetArgMethod", nil, nil).AnyTimes().SetArg(2, 1)
+	}, "SetArg(2, ...) called for a method with 2 args", "controller_test.go:")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "SetArgMethod", nil, nil).AnyTimes().SetArg(-1, 1)
+	}, "SetArg(-1, ...) called for a method with 2 args")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "SetArgMethod", nil, nil).AnyTimes().SetArg(0, "abc")
+	}, "SetArg(0, ...) argument is a string, not a slice or array of elements assignable to uint8")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "OutputMethod", nil, nil, nil).AnyTimes().SetArg(1, map[string]string{})
+	}, "SetArg(1, ...) argument is a map[string]string, not a map assignable to map[string]int")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "OutputMethod", nil, nil, nil).AnyTimes().SetArg(3, "x")
+	}, "SetArg(3, ...) argument is a string, not assignable to int")
+
+	// Arguments of interface type and beyond the variadic ones are checked
+	// when the call is made.
+	ctrl.RecordCall(subject, "OutputMethod", nil, nil, 1).SetArg(2, 2)
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "OutputMethod", nil, nil, 1)
+	}, "SetArg(2, ...) referring to argument of non-pointer non-interface non-slice non-map type int")
+	ctrl.RecordCall(subject, "OutputMethod", nil, nil, 2).SetArg(3, 3)
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "OutputMethod", nil, nil, 2)
+	}, "SetArg(3, ...) called for a call with 3 args")
+}
 
 func TestReturn(t *testing.T) {
 	_, ctrl := createFixtures(t)
 	subject := new(Subject)
 
-	// Unspecified return should produce "zero" result.
-	ctrl.RecordCall(subject, "FooMethod", "zero")
-	ctrl.RecordCall(subject, "FooMethod", "five").Return(5)
+	// Unspecified return should produce "zero" result.
+	ctrl.RecordCall(subject, "FooMethod", "zero")
+	ctrl.RecordCall(subject, "FooMethod", "five").Return(5)
+
+	assertEqual(
+		t,
+		[]interface{}{0},
+		ctrl.Call(subject, "FooMethod", "zero"))
+
+	assertEqual(
+		t,
+		[]interface{}{5},
+		ctrl.Call(subject, "FooMethod", "five"))
+	ctrl.Finish()
+}
+
+func TestPanicAction(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "argument").Panic("dependency failed")
+	ctrl.RecordCall(subject, "BarMethod", "argument").Return(1)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "dependency failed" {
+				t.Errorf("recovered %v, want the value passed to Panic", r)
+			}
+		}()
+		ctrl.Call(subject, "FooMethod", "argument")
+		t.Error("Call should have panicked")
+	}()
+
+	// The controller is still usable, and the panicking call was consumed.
+	assertEqual(t, []interface{}{1}, ctrl.Call(subject, "BarMethod", "argument"))
+	ctrl.Finish()
+	rep.assertPass("Finish after a recovered Panic action")
+}
+
+func TestPanicActionConflicts(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "FooMethod", "a").AnyTimes().Return(1).Panic("boom")
+	}, "Panic for *gomock_test.Subject.FooMethod can't be combined with Return or DoAndReturn", "controller_test.go:")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "FooMethod", "b").AnyTimes().Panic("boom").Return(1)
+	}, "Return for *gomock_test.Subject.FooMethod can't be combined with Panic")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "FooMethod", "c").AnyTimes().Panic("boom").DoAndReturn(func(string) int { return 1 })
+	}, "DoAndReturn for *gomock_test.Subject.FooMethod can't be combined with Panic")
+	ctrl.Finish()
+}
+
+func TestWaitUntilAndNotify(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	ctrl.RecordCall(subject, "FooMethod", "blocking").Notify(started).WaitUntil(release).Return(1)
+	ctrl.RecordCall(subject, "BarMethod", "other").Return(2)
+
+	done := make(chan []interface{})
+	go func() {
+		done <- ctrl.Call(subject, "FooMethod", "blocking")
+	}()
+
+	<-started
+	// The blocked call doesn't hold the controller's lock.
+	assertEqual(t, []interface{}{2}, ctrl.Call(subject, "BarMethod", "other"))
+	select {
+	case <-done:
+		t.Fatal("FooMethod returned before being released")
+	default:
+	}
+
+	close(release)
+	assertEqual(t, []interface{}{1}, <-done)
+	ctrl.Finish()
+}
+
+func TestNotifyDoesNotBlock(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	calls := make(chan struct{}, 1)
+	ctrl.RecordCall(subject, "FooMethod", "argument").Notify(calls).Times(3)
+	for i := 0; i < 3; i++ {
+		ctrl.Call(subject, "FooMethod", "argument")
+	}
+	ctrl.Finish()
+
+	if len(calls) != 1 {
+		t.Errorf("got %d notifications, want 1 as the buffer is full", len(calls))
+	}
+}
+
+func TestConcurrentRecordAndCall(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	s := new(Subject)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			arg := fmt.Sprint(i)
+			for j := 0; j < 20; j++ {
+				ctrl.RecordCall(s, "FooMethod", arg).Return(j)
+				if got := ctrl.Call(s, "FooMethod", arg); !reflect.DeepEqual(got, []interface{}{j}) {
+					t.Errorf("goroutine %d, call %d: got %v, want the expected call just recorded", i, j, got)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	ctrl.Finish()
+	rep.assertPass("all calls made")
+}
+
+func TestPanickingActionLeavesControllerUnlocked(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	s := new(Subject)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	ctrl.RecordCall(s, "FooMethod", "panic").Do(func(string) {
+		close(started)
+		<-release
+		panic("intentional")
+	})
+	ctrl.RecordCall(s, "BarMethod", "concurrent").Times(2)
+
+	done := make(chan interface{})
+	go func() {
+		defer func() { done <- recover() }()
+		ctrl.Call(s, "FooMethod", "panic")
+	}()
+	<-started
+	// The action is running, the controller doesn't wait for it.
+	ctrl.Call(s, "BarMethod", "concurrent")
+	close(release)
+
+	select {
+	case r := <-done:
+		if r != "intentional" {
+			t.Fatalf("got panic %v, want the action's", r)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the panicking call hung")
+	}
+	ctrl.Call(s, "BarMethod", "concurrent")
+	ctrl.Finish()
+	rep.assertPass("the controller is usable after the panic")
+}
+
+func TestUnexpectedCallFromOtherGoroutine(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	s := new(Subject)
+
+	ctrl.RecordCall(s, "FooMethod", "expected").Return(1).AnyTimes()
+	var wg sync.WaitGroup
+	rets := make([][]interface{}, 3)
+	for i := range rets {
+		wg.Add(1)
+		// Like the code under test would, start goroutines of its own.
+		go func(i int) {
+			defer wg.Done()
+			ctrl.Call(s, "FooMethod", "expected")
+			rets[i] = ctrl.Call(s, "GetMethod", i)
+		}(i)
+	}
+	// The goroutines aren't stopped by the failures, so they don't leave
+	// the test waiting.
+	wg.Wait()
+
+	rep.assertFail("unexpected calls from other goroutines")
+	for i, r := range rets {
+		assertEqual(t, []interface{}{"", nil}, r)
+		if !strings.Contains(strings.Join(rep.log, "\n"), fmt.Sprintf("GetMethod([%d])", i)) {
+			t.Errorf("the unexpected call GetMethod(%d) wasn't reported", i)
+		}
+	}
+	rep.assertFatal(ctrl.Finish, "aborting test due to 3 unexpected call(s) from other goroutines:",
+		"Unexpected call to *gomock_test.Subject.GetMethod(", "there are no expected calls of the method \"GetMethod\"")
+}
+
+func TestUnexpectedCallWithGoHandle(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	s := new(Subject)
+
+	done := make(chan []interface{})
+	ctrl.Go(func(ctrl *gomock.Controller) {
+		done <- ctrl.Call(s, "GetMethod", 1)
+	})
+	assertEqual(t, []interface{}{"", nil}, <-done)
+	rep.assertFail("unexpected call with a handle passed by Go")
+	rep.assertFatal(ctrl.Finish, "aborting test due to 1 unexpected call(s) from other goroutines:",
+		"Unexpected call to *gomock_test.Subject.GetMethod([1])")
+}
+
+func TestUnexpectedCallFromTestGoroutine(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	s := new(Subject)
+
+	rep.assertFatal(func() {
+		ctrl.Call(s, "GetMethod", 1)
+	}, "Unexpected call to *gomock_test.Subject.GetMethod([1])")
+	// The failure was fatal already.
+	ctrl.Finish()
+}
+
+// asyncReporter is a TestReporter that collects failures reported from any
+// goroutine.
+type asyncReporter struct {
+	failures chan string
+}
+
+func newAsyncReporter() *asyncReporter {
+	return &asyncReporter{failures: make(chan string, 10)}
+}
+
+func (r *asyncReporter) Errorf(format string, args ...interface{}) {
+	r.failures <- fmt.Sprintf(format, args...)
+}
+
+func (r *asyncReporter) Fatalf(format string, args ...interface{}) {
+	r.failures <- fmt.Sprintf(format, args...)
+}
+
+// assertNone checks that no failure is reported within d.
+func (r *asyncReporter) assertNone(t *testing.T, d time.Duration) {
+	select {
+	case msg := <-r.failures:
+		t.Errorf("unexpected failure: %s", msg)
+	case <-time.After(d):
+	}
+}
+
+func TestWithinDurationSatisfied(t *testing.T) {
+	rep := newAsyncReporter()
+	ctrl := gomock.NewController(rep)
+	subject := new(Subject)
+
+	call := ctrl.RecordCall(subject, "FooMethod", "argument").Times(2).WithinDuration(time.Second)
+	go func() {
+		ctrl.Call(subject, "FooMethod", "argument")
+		ctrl.Call(subject, "FooMethod", "argument")
+	}()
+	if !call.Wait(time.Second) {
+		t.Fatal("Wait: the call wasn't satisfied")
+	}
+	ctrl.Finish()
+	rep.assertNone(t, 10*time.Millisecond)
+}
+
+func TestWithinDurationTimeout(t *testing.T) {
+	rep := newAsyncReporter()
+	ctrl := gomock.NewController(rep)
+	subject := new(Subject)
+
+	call := ctrl.RecordCall(subject, "FooMethod", "argument").Name("late").WithinDuration(10 * time.Millisecond)
+	select {
+	case msg := <-rep.failures:
+		for _, want := range []string{`Expected call "late" at`, "wasn't made within 10ms", "expected 1..1 calls, got 0"} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("failure %q doesn't contain %q", msg, want)
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no failure reported for a call that timed out")
+	}
+	if call.Wait(time.Millisecond) {
+		t.Error("Wait: got satisfied, want a timeout")
+	}
+
+	ctrl.Call(subject, "FooMethod", "argument")
+	if !call.Wait(0) {
+		t.Error("Wait: got a timeout for a satisfied call")
+	}
+	ctrl.Finish()
+	rep.assertNone(t, 10*time.Millisecond)
+}
+
+func TestWithinDurationAfterFinish(t *testing.T) {
+	rep := newAsyncReporter()
+	ctrl := gomock.NewController(rep)
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "argument").WithinDuration(10 * time.Millisecond)
+	// The Fatalf of asyncReporter returns, so Finish ends its goroutine.
+	runUntilExit(ctrl.Finish)
+	if msg, want := <-rep.failures, "aborting test due to missing call(s)"; !strings.Contains(msg, want) {
+		t.Errorf("failure %q doesn't contain %q", msg, want)
+	}
+	// Finish has reported the missing call, the timer doesn't as well.
+	rep.assertNone(t, 50*time.Millisecond)
+}
+
+func TestWithIdleTimeout(t *testing.T) {
+	rep := newAsyncReporter()
+	ctrl := gomock.NewController(rep, gomock.WithIdleTimeout(20*time.Millisecond))
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "a")
+	ctrl.RecordCall(subject, "BarMethod", "b")
+	ctrl.Call(subject, "BarMethod", "b")
+	msg := <-rep.failures
+	for _, want := range []string{
+		"gomock: no call was made to the mocks for 20ms, while expected calls are pending:\n",
+		"*gomock_test.Subject.FooMethod:\n\t(is equal to a) registered at ",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("failure %q doesn't contain %q", msg, want)
+		}
+	}
+	if strings.Contains(msg, "BarMethod") {
+		t.Errorf("failure %q lists a satisfied call", msg)
+	}
+	// The watch stops at the first failure.
+	rep.assertNone(t, 50*time.Millisecond)
+}
+
+func TestWithIdleTimeoutNotFiring(t *testing.T) {
+	rep := newAsyncReporter()
+	ctrl := gomock.NewController(rep, gomock.WithIdleTimeout(50*time.Millisecond))
+	subject := new(Subject)
+
+	// Calls expected any number of times aren't pending.
+	ctrl.RecordCall(subject, "BarMethod", "b").AnyTimes()
+	rep.assertNone(t, 100*time.Millisecond)
+
+	// Calls made keep the watch from firing.
+	ctrl.RecordCall(subject, "FooMethod", "a")
+	for i := 0; i < 10; i++ {
+		time.Sleep(5 * time.Millisecond)
+		ctrl.Call(subject, "BarMethod", "b")
+	}
+	ctrl.Call(subject, "FooMethod", "a")
+	rep.assertNone(t, 50*time.Millisecond)
+	ctrl.Finish()
+}
+
+func TestWithIdleTimeoutRestartedByRecording(t *testing.T) {
+	rep := newAsyncReporter()
+	ctrl := gomock.NewController(rep, gomock.WithIdleTimeout(200*time.Millisecond))
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "a")
+	time.Sleep(150 * time.Millisecond)
+	// Recording a call counts as activity: the controller isn't idle at the
+	// original deadline, 200ms after it was created.
+	ctrl.RecordCall(subject, "BarMethod", "b")
+	rep.assertNone(t, 100*time.Millisecond)
+
+	select {
+	case msg := <-rep.failures:
+		if want := "(is equal to b) registered at "; !strings.Contains(msg, want) {
+			t.Errorf("failure %q doesn't contain %q", msg, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no failure reported once the controller was idle")
+	}
+}
+
+func TestWithIdleTimeoutStoppedByFinish(t *testing.T) {
+	rep := newAsyncReporter()
+	ctrl := gomock.NewController(rep, gomock.WithIdleTimeout(20*time.Millisecond))
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "a")
+	// The Fatalf of asyncReporter returns, so Finish ends its goroutine.
+	runUntilExit(ctrl.Finish)
+	if msg, want := <-rep.failures, "aborting test due to missing call(s)"; !strings.Contains(msg, want) {
+		t.Errorf("failure %q doesn't contain %q", msg, want)
+	}
+	rep.assertNone(t, 50*time.Millisecond)
+}
+
+func TestWithIdleTimeoutCancelsContext(t *testing.T) {
+	rep := newAsyncReporter()
+	ctrl, ctx := gomock.WithContext(context.Background(), rep, gomock.WithIdleTimeout(10*time.Millisecond))
+	ctrl.RecordCall(new(Subject), "FooMethod", "a")
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("the context wasn't cancelled")
+	}
+	<-rep.failures
+}
+
+func TestCallerIsolationParallelSubtests(t *testing.T) {
+	ctrl := gomock.NewController(t, gomock.WithCallerIsolation())
+	subject := new(Subject)
+
+	for _, ret := range []int{1, 2} {
+		ret := ret
+		t.Run(fmt.Sprint(ret), func(t *testing.T) {
+			scope := ctrl.Scope(t)
+			// Both subtests record the same calls before either makes them.
+			scope.RecordCall(subject, "FooMethod", "a").Return(ret)
+			scope.RecordCall(subject, "BarMethod", "b").Return(ret)
+			t.Parallel()
+
+			if got := scope.Call(subject, "FooMethod", "a")[0]; got != ret {
+				t.Errorf("FooMethod returned %v, want %v", got, ret)
+			}
+			rets := make(chan []interface{})
+			scope.Go(func(ctrl *gomock.Controller) {
+				rets <- ctrl.Call(subject, "BarMethod", "b")
+			})
+			if got := (<-rets)[0]; got != ret {
+				t.Errorf("BarMethod returned %v with a handle passed by Go, want %v", got, ret)
+			}
+		})
+	}
+}
+
+func TestCallerIsolationOtherController(t *testing.T) {
+	ctrl := gomock.NewController(t, gomock.WithCallerIsolation())
+	subject := new(Subject)
+	firstRep, secondRep := NewErrorReporter(t), NewErrorReporter(t)
+	first, second := ctrl.Scope(firstRep), ctrl.Scope(secondRep)
+
+	// The call recorded first isn't consumed by the other controller.
+	first.RecordCall(subject, "BarMethod", "b").Return(1)
+	second.RecordCall(subject, "BarMethod", "b").Return(2)
+	if got := second.Call(subject, "BarMethod", "b")[0]; got != 2 {
+		t.Errorf("BarMethod returned %v with the second scope, want 2", got)
+	}
+	if got := first.Call(subject, "BarMethod", "b")[0]; got != 1 {
+		t.Errorf("BarMethod returned %v with the first scope, want 1", got)
+	}
+
+	first.RecordCall(subject, "FooMethod", "a").Return(1)
+	secondRep.assertFatal(func() {
+		second.Call(subject, "FooMethod", "a")
+	}, "was recorded with another controller")
+
+	// The handles passed by the Go of a handle act for the same controller.
+	rets := make(chan []interface{})
+	first.Go(func(ctrl *gomock.Controller) {
+		ctrl.Go(func(ctrl *gomock.Controller) {
+			rets <- ctrl.Call(subject, "FooMethod", "a")
+		})
+	})
+	if got := (<-rets)[0]; got != 1 {
+		t.Errorf("FooMethod returned %v with a handle passed by Go, want 1", got)
+	}
+	first.Finish()
+	firstRep.assertPass("the call was made with a handle acting for the scope")
+	second.Finish()
+}
+
+func TestCheckpoints(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "BarMethod", "before")
+	first := ctrl.Checkpoint()
+	ctrl.RecordCall(subject, "FooMethod", "a")
+	ctrl.RecordCall(subject, "FooMethod", "b")
+	ctrl.Call(subject, "FooMethod", "a")
+	ctrl.Call(subject, "FooMethod", "b")
+	// The call recorded before the checkpoint isn't checked.
+	ctrl.VerifySince(first)
+	rep.assertPass("the calls of the first phase were made")
+
+	_, file, line, _ := runtime.Caller(0)
+	second := ctrl.Checkpoint()
+	ctrl.RecordCall(subject, "FooMethod", "c")
+	ctrl.RecordCall(subject, "FooMethod", "d")
+	ctrl.Call(subject, "FooMethod", "c")
+	rep.assertFatal(func() { ctrl.VerifySince(second) },
+		fmt.Sprintf("missing call(s) since the checkpoint at %s:%d:\n", file, line+1),
+		"*gomock_test.Subject.FooMethod:\n\t(is equal to d) registered at ")
+	if strings.Contains(rep.log[len(rep.log)-1], "before") {
+		t.Errorf("the call recorded before the checkpoint is reported: %q", rep.log)
+	}
+
+	if calls := ctrl.CallsSince(second); len(calls) != 1 || calls[0].Receiver != subject || calls[0].Args[0] != "c" {
+		t.Errorf("got calls since the second checkpoint %v, want FooMethod(c)", calls)
+	}
+	// The records from before the latest checkpoint are dropped.
+	ctrl.Call(subject, "FooMethod", "d")
+	third := ctrl.Checkpoint()
+	if calls := ctrl.CallsSince(first); len(calls) != 0 {
+		t.Errorf("got calls since the first checkpoint %v after the third one, want none", calls)
+	}
+	ctrl.RecordCall(subject, "FooMethod", "e")
+	ctrl.Call(subject, "FooMethod", "e")
+	var got []string
+	for _, r := range ctrl.CallsSince(third) {
+		got = append(got, fmt.Sprintf("%d %s(%v)", r.Seq, r.Method, r.Args[0]))
+	}
+	if want := []string{"5 FooMethod(e)"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got calls since the third checkpoint %q, want %q", got, want)
+	}
+}
+
+func TestErrorReporter(t *testing.T) {
+	r := gomock.NewErrorReporter()
+	ctrl := gomock.NewController(r)
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "a").Return(1)
+	ctrl.RecordCall(subject, "BarMethod", "b")
+	if err := r.Run(func() {
+		if rets := ctrl.Call(subject, "FooMethod", "a"); rets[0] != 1 {
+			t.Errorf("FooMethod returned %v, want 1", rets)
+		}
+	}); err != nil {
+		t.Fatalf("Run returned %v for a call which was expected", err)
+	}
+
+	// The failure stops the function passed to Run only.
+	reached := false
+	err := r.Run(func() {
+		ctrl.Call(subject, "FooMethod", "z")
+		reached = true
+	})
+	if want := "Unexpected call to *gomock_test.Subject.FooMethod([z])"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("Run returned %v, want an error containing %q", err, want)
+	}
+	if reached {
+		t.Error("the function went on after the unexpected call")
+	}
+	if _, err := ctrl.CallErr(subject, "BarMethod", "z"); err == nil || !strings.Contains(err.Error(), "Unexpected call to *gomock_test.Subject.BarMethod([z])") {
+		t.Errorf("CallErr returned %v, want the unexpected call", err)
+	}
+
+	err = ctrl.FinishErr()
+	if err == nil {
+		t.Fatal("FinishErr returned no error")
+	}
+	msgs := strings.Split(err.Error(), "\n")
+	for _, want := range []string{
+		"Unexpected call to *gomock_test.Subject.FooMethod([z])",
+		"Unexpected call to *gomock_test.Subject.BarMethod([z])",
+		"aborting test due to missing call(s):",
+	} {
+		found := false
+		for _, msg := range msgs {
+			found = found || strings.HasPrefix(msg, want)
+		}
+		if !found {
+			t.Errorf("FinishErr returned %q, without the failure %q", err, want)
+		}
+	}
+	if !strings.Contains(err.Error(), "*gomock_test.Subject.BarMethod:\n\t(is equal to b) registered at ") {
+		t.Errorf("FinishErr returned %q, without the missing call", err)
+	}
+	if n := len(r.Errors()); n != 3 {
+		t.Errorf("got %d failures recorded, want 3: %q", n, r.Errors())
+	}
+}
+
+func TestFinishErrPasses(t *testing.T) {
+	r := gomock.NewErrorReporter()
+	ctrl := gomock.NewController(r)
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "a")
+	if _, err := ctrl.CallErr(subject, "FooMethod", "a"); err != nil {
+		t.Errorf("CallErr returned %v", err)
+	}
+	if err := ctrl.FinishErr(); err != nil {
+		t.Errorf("FinishErr returned %v", err)
+	}
+}
+
+func TestFinishErrWhilePanicking(t *testing.T) {
+	r := gomock.NewErrorReporter()
+	ctrl := gomock.NewController(r)
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "a")
+	// The Fatalf of the ErrorReporter stops the function, and its deferred
+	// Finish stops the panic rather than passing it through.
+	err := r.Run(func() {
+		defer ctrl.Finish()
+		ctrl.Call(subject, "BarMethod", "b")
+	})
+	for _, want := range []string{
+		"Unexpected call to *gomock_test.Subject.BarMethod([b])",
+		"missing call(s) when the test panicked:\n*gomock_test.Subject.FooMethod:",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Run returned %v, want an error containing %q", err, want)
+		}
+	}
+	if n := len(r.Errors()); n != 2 {
+		t.Errorf("got %d failures, want the unexpected call and the missing one: %v", n, r.Errors())
+	}
+
+	// Another panic is reported as a failure.
+	r = gomock.NewErrorReporter()
+	ctrl = gomock.NewController(r)
+	err = r.Run(func() {
+		defer ctrl.Finish()
+		panic("boom")
+	})
+	if want := "the test panicked: boom\n"; err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("Run returned %v, want an error starting with %q", err, want)
+	}
+}
+
+// countingArg counts how many times it is formatted.
+type countingArg struct {
+	id        int
+	formatted *int
+}
+
+func (a countingArg) String() string {
+	*a.formatted++
+	return fmt.Sprint(a.id)
+}
+
+func TestMatchedCallDoesNotFormat(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	var formatted int
+	ctrl.RecordCall(subject, "LogMethod", "x", countingArg{1, &formatted})
+	ctrl.RecordCall(subject, "LogMethod", "x", countingArg{2, &formatted})
+	// The first expected call rejects the argument, but no failure is
+	// reported: neither it nor the matcher are formatted.
+	ctrl.Call(subject, "LogMethod", "x", countingArg{2, &formatted})
+	if formatted != 0 {
+		t.Errorf("the arguments were formatted %d times for a call which matched", formatted)
+	}
+}
+
+func TestUnexpectedCallTruncatesArgs(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "a")
+	long := strings.Repeat("z", 100)
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "FooMethod", long)
+	}, "Unexpected call to *gomock_test.Subject.FooMethod(["+long[:64]+"...]) at ",
+		"Got: "+long[:64]+"...\n")
+	ctrl.Call(subject, "FooMethod", "a")
+}
+
+// Streamer is an interface with methods returning channels, and
+// MockStreamer a mock of it in the style of the generated ones.
+type Streamer interface {
+	Values() (<-chan int, error)
+	Errors() <-chan error
+}
+
+type MockStreamer struct {
+	ctrl     *gomock.Controller
+	recorder *MockStreamerMockRecorder
+}
+
+type MockStreamerMockRecorder struct {
+	mock *MockStreamer
+}
+
+func NewMockStreamer(ctrl *gomock.Controller) *MockStreamer {
+	mock := &MockStreamer{ctrl: ctrl}
+	mock.recorder = &MockStreamerMockRecorder{mock}
+	return mock
+}
+
+func (m *MockStreamer) EXPECT() *MockStreamerMockRecorder {
+	return m.recorder
+}
+
+func (m *MockStreamer) Values() (<-chan int, error) {
+	ret := m.ctrl.Call(m, "Values")
+	ret0, _ := ret[0].(<-chan int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+func (mr *MockStreamerMockRecorder) Values() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Values", reflect.TypeOf((*MockStreamer)(nil).Values))
+}
+
+func (m *MockStreamer) Errors() <-chan error {
+	ret := m.ctrl.Call(m, "Errors")
+	ret0, _ := ret[0].(<-chan error)
+	return ret0
+}
+
+func (mr *MockStreamerMockRecorder) Errors() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Errors", reflect.TypeOf((*MockStreamer)(nil).Errors))
+}
+
+// receiveAll receives the values from ch until it is closed.
+func receiveAll(t *testing.T, ch <-chan int) []int {
+	var got []int
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return got
+			}
+			got = append(got, v)
+		case <-timeout:
+			t.Fatalf("the channel wasn't closed, got %v", got)
+		}
+	}
+}
+
+func TestReturnChannelOf(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	m := NewMockStreamer(ctrl)
+
+	m.EXPECT().Values().Return(gomock.ReturnChannelOf(1, 2, 3), nil).Times(2)
+	boom := errors.New("boom")
+	m.EXPECT().Errors().Return(gomock.ReturnChannelOf(boom, nil))
+
+	// Each call gets a channel of its own.
+	for i := 0; i < 2; i++ {
+		ch, err := m.Values()
+		if err != nil {
+			t.Fatalf("Values returned error %v", err)
+		}
+		if got, want := receiveAll(t, ch), []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+			t.Errorf("call %d: got values %v, want %v", i, got, want)
+		}
+	}
+	var errs []error
+	for err := range m.Errors() {
+		errs = append(errs, err)
+	}
+	if len(errs) != 2 || errs[0] != boom || errs[1] != nil {
+		t.Errorf("got errors %v, want [boom <nil>]", errs)
+	}
+	ctrl.Finish()
+}
+
+func TestReturnChannelFrom(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	m := NewMockStreamer(ctrl)
+
+	source := make(chan int)
+	m.EXPECT().Values().Return(gomock.ReturnChannelFrom(source), nil)
+	go func() {
+		for i := 1; i <= 3; i++ {
+			source <- i
+		}
+		close(source)
+	}()
+
+	ch, _ := m.Values()
+	if got, want := receiveAll(t, ch), []int{1, 2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got values %v, want %v", got, want)
+	}
+	ctrl.Finish()
+}
+
+func TestReturnChannelTypeMismatch(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	m := NewMockStreamer(ctrl)
+
+	rep.assertFatal(func() {
+		m.EXPECT().Values().Return(gomock.ReturnChannelOf(1, "two"), nil)
+	}, "wrong channel for argument 0 to Return for *gomock_test.MockStreamer.Values: value 1 of the channel is a string, which is not assignable to int")
+	rep.assertFatal(func() {
+		m.EXPECT().Values().Return(nil, gomock.ReturnChannelOf(errors.New("boom")))
+	}, "wrong channel for argument 1 to Return for *gomock_test.MockStreamer.Values: error is not a channel which can be received from")
+	rep.assertFatal(func() {
+		m.EXPECT().Values().Return(gomock.ReturnChannelFrom(make(chan string)), nil)
+	}, "the values of the source chan string are not assignable to int")
+	rep.assertFatal(func() {
+		m.EXPECT().Values().Return(gomock.ReturnChannelFrom(make(chan<- int)), nil)
+	}, "the source chan<- int is not a channel which can be received from")
+}
+
+func TestReturnChannelWithLazyTypeMismatch(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	m := NewMockStreamer(ctrl)
+
+	// The channels are checked when they are passed to Return, so only the
+	// Lazy value can be of the wrong type when the call is matched.
+	m.EXPECT().Values().Return(gomock.ReturnChannelOf(1), gomock.Lazy(func() interface{} { return "boom" }))
+	if ch, err := m.Values(); ch != nil || err != nil {
+		t.Errorf("Values returned %v, %v, want zero values", ch, err)
+	}
+	want := "wrong type of argument 1 to Return for *gomock_test.MockStreamer.Values: string is not assignable to error, as evaluated by Lazy"
+	if got := rep.log[len(rep.log)-1]; !strings.Contains(got, want) {
+		t.Errorf("got failure %q, want one containing %q", got, want)
+	}
+}
+
+// Lookup is a func type a dependency may be injected as.
+type Lookup func(key string, n int) (string, error)
+
+func TestMockFunction(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+
+	orig := func(string, int) (string, error) { return "orig", nil }
+	lookup := Lookup(orig)
+	m := gomock.MockFunction(ctrl, &lookup)
+	m.EXPECT("a", gomock.Any()).Return("found", nil)
+	m.EXPECT(gomock.Any(), 2).Return("", errors.New("boom"))
+	m.EXPECT("c", 3)
+
+	if got, err := lookup("a", 1); got != "found" || err != nil {
+		t.Errorf(`lookup("a", 1) = %q, %v, want "found", nil`, got, err)
+	}
+	if got, err := lookup("b", 2); got != "" || err == nil || err.Error() != "boom" {
+		t.Errorf(`lookup("b", 2) = %q, %v, want "", boom`, got, err)
+	}
+	// Without Return, the function returns zero values.
+	if got, err := lookup("c", 3); got != "" || err != nil {
+		t.Errorf(`lookup("c", 3) = %q, %v, want "", nil`, got, err)
+	}
+	rep.assertFatal(func() {
+		lookup("d", 4)
+	}, "Unexpected call to gomock_test.Lookup.Call([d 4])")
+	ctrl.Finish()
+
+	if got, _ := lookup("a", 1); got != "orig" {
+		t.Errorf("lookup returned %q after Finish, want the original function back", got)
+	}
+}
+
+func TestMockFunctionMissingCall(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+
+	var lookup Lookup
+	m := gomock.MockFunction(ctrl, &lookup)
+	call := m.EXPECT("a", gomock.Any())
+	rep.assertFatal(ctrl.Finish, "aborting test due to missing call(s):\n"+
+		"gomock_test.Lookup.Call:\n\t(is equal to a, is anything) registered at "+call.Origin())
+	if lookup != nil {
+		t.Error("the function wasn't put back by Finish")
+	}
+}
+
+func TestMockFunctionNotAFunction(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+
+	var n int
+	rep.assertFatal(func() {
+		gomock.MockFunction(ctrl, &n)
+	}, "gomock: MockFunction needs a pointer to a function, got *int")
+	var lookup Lookup
+	rep.assertFatal(func() {
+		gomock.MockFunction(ctrl, lookup)
+	}, "gomock: MockFunction needs a pointer to a function, got gomock_test.Lookup")
+}
+
+func TestMultipleActions(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	var order []string
+	ctrl.RecordCall(subject, "DecodeMethod", "input", gomock.Any()).
+		SetArg(1, TestStruct{Number: 7}).
+		Do(func(src string, dst *TestStruct) {
+			order = append(order, fmt.Sprintf("Do(%s, %v)", src, *dst))
+		}).
+		Return(&notFoundError{}).
+		DoAndReturn(func(string, *TestStruct) error {
+			order = append(order, "DoAndReturn")
+			return nil
+		}).
+		Do(func(string, *TestStruct) {
+			order = append(order, "last Do")
+		})
+
+	var dst TestStruct
+	rets := ctrl.Call(subject, "DecodeMethod", "input", &dst)
+	ctrl.Finish()
+
+	if dst.Number != 7 {
+		t.Errorf("SetArg didn't run: got %v", dst)
+	}
+	// Do sees the effect of the SetArg declared before it.
+	if want := []string{"Do(input, {7 })", "DoAndReturn", "last Do"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("actions ran as %q, want %q", order, want)
+	}
+	// The last action returning values wins; Do doesn't reset them.
+	assertEqual(t, []interface{}{nil}, rets)
+}
+
+func TestMultipleActionsReturn(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	called := false
+	ctrl.RecordCall(subject, "DecodeMethod", "input", gomock.Any()).
+		SetArg(1, TestStruct{Message: "set"}).
+		Do(func(string, *TestStruct) { called = true }).
+		Return(&notFoundError{})
+
+	var dst TestStruct
+	rets := ctrl.Call(subject, "DecodeMethod", "input", &dst)
+	ctrl.Finish()
+
+	if dst.Message != "set" || !called {
+		t.Errorf("SetArg and Do effects missing: dst = %v, Do called = %v", dst, called)
+	}
+	if err, ok := rets[0].(error); !ok || err.Error() != "not found" {
+		t.Errorf("DecodeMethod returned %v, want the Return value", rets[0])
+	}
+}
+
+func TestInvocations(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	one, two := new(Subject), new(Subject)
+
+	fooOne := ctrl.RecordCall(one, "FooMethod", gomock.Any()).AnyTimes()
+	barTwo := ctrl.RecordCall(two, "BarMethod", gomock.Any()).AnyTimes()
+	if n := len(fooOne.Invocations()); n != 0 {
+		t.Fatalf("got %d invocations before any call, want 0", n)
+	}
+
+	start := time.Now()
+	ctrl.Call(one, "FooMethod", "a")
+	ctrl.Call(two, "BarMethod", "b")
+	ctrl.Call(one, "FooMethod", "c")
+	ctrl.Finish()
+
+	foo, bar := fooOne.Invocations(), barTwo.Invocations()
+	if len(foo) != 2 || len(bar) != 1 {
+		t.Fatalf("got %d and %d invocations, want 2 and 1", len(foo), len(bar))
+	}
+	assertEqual(t, []interface{}{"a"}, foo[0].Args)
+	assertEqual(t, []interface{}{"c"}, foo[1].Args)
+	assertEqual(t, []interface{}{"b"}, bar[0].Args)
+	if foo[0].Seq != 1 || bar[0].Seq != 2 || foo[1].Seq != 3 {
+		t.Errorf("got sequence numbers %d, %d, %d, want 1, 2, 3", foo[0].Seq, bar[0].Seq, foo[1].Seq)
+	}
+	if foo[0].Time.Before(start) || foo[1].Time.Before(bar[0].Time) {
+		t.Errorf("invocation times out of order: %v, %v, %v", foo[0].Time, bar[0].Time, foo[1].Time)
+	}
+
+	// The returned slice is a copy.
+	foo[0].Args[0] = "changed"
+	if got := fooOne.Invocations()[0].Args[0]; got != "a" {
+		t.Errorf("modifying the returned records changed them to %v", got)
+	}
+}
+
+func TestKeepInvocations(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	call := ctrl.RecordCall(subject, "FooMethod", gomock.Any()).AnyTimes().KeepInvocations(2)
+	none := ctrl.RecordCall(subject, "BarMethod", gomock.Any()).AnyTimes().KeepInvocations(0)
+	for _, arg := range []string{"a", "b", "c"} {
+		ctrl.Call(subject, "FooMethod", arg)
+		ctrl.Call(subject, "BarMethod", arg)
+	}
+	ctrl.Finish()
+
+	got := call.Invocations()
+	if len(got) != 2 || got[0].Args[0] != "b" || got[1].Args[0] != "c" {
+		t.Errorf("got invocations %v, want the last two", got)
+	}
+	if got[0].Seq != 3 || got[1].Seq != 5 {
+		t.Errorf("got sequence numbers %d, %d, want 3, 5", got[0].Seq, got[1].Seq)
+	}
+	if n := len(none.Invocations()); n != 0 {
+		t.Errorf("got %d invocations with KeepInvocations(0), want 0", n)
+	}
+	if got := call.KeepInvocations(1).Invocations(); len(got) != 1 || got[0].Args[0] != "c" {
+		t.Errorf("got invocations %v after KeepInvocations(1), want the last one", got)
+	}
+}
+
+func TestInvocationsConcurrent(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	call := ctrl.RecordCall(subject, "FooMethod", gomock.Any()).AnyTimes()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				ctrl.Call(subject, "FooMethod", "x")
+				call.Invocations()
+			}
+		}()
+	}
+	wg.Wait()
+	ctrl.Finish()
+
+	seen := make(map[uint64]bool)
+	for _, r := range call.Invocations() {
+		seen[r.Seq] = true
+	}
+	if len(seen) != 100 {
+		t.Errorf("got %d distinct sequence numbers, want 100", len(seen))
+	}
+}
+
+func TestCallAccessors(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	one, two := new(Subject), new(Subject)
+
+	foo := ctrl.RecordCall(one, "FooMethod", "a")
+	bar := ctrl.RecordCall(two, "BarMethod", "b").Times(2)
+	if foo.Receiver() != one || foo.Method() != "FooMethod" || bar.Receiver() != two || bar.Method() != "BarMethod" {
+		t.Errorf("got %v.%v and %v.%v", foo.Receiver(), foo.Method(), bar.Receiver(), bar.Method())
+	}
+	if !strings.Contains(foo.Origin(), "controller_test.go:") {
+		t.Errorf("Origin() == %q, want a location in controller_test.go", foo.Origin())
+	}
+
+	expected := ctrl.ExpectedCalls()
+	if len(expected) != 2 || expected[0] != foo || expected[1] != bar {
+		t.Fatalf("ExpectedCalls() == %v, want [foo bar]", expected)
+	}
+	expected[0] = nil
+	if ctrl.ExpectedCalls()[0] != foo {
+		t.Error("modifying the ExpectedCalls snapshot changed the controller")
+	}
+
+	ctrl.Call(one, "FooMethod", "a")
+	ctrl.Call(two, "BarMethod", "b")
+	if !foo.IsSatisfied() || bar.IsSatisfied() {
+		t.Errorf("IsSatisfied() == %v, %v, want true, false", foo.IsSatisfied(), bar.IsSatisfied())
+	}
+	if expected := ctrl.ExpectedCalls(); len(expected) != 1 || expected[0] != bar {
+		t.Errorf("ExpectedCalls() == %v, want [bar]", expected)
+	}
+
+	ctrl.Call(two, "BarMethod", "b")
+	if !bar.IsSatisfied() || len(ctrl.ExpectedCalls()) != 0 {
+		t.Errorf("got IsSatisfied() == %v and %d expected calls after all calls", bar.IsSatisfied(), len(ctrl.ExpectedCalls()))
+	}
+	ctrl.Finish()
+}
+
+func TestZeroValueReturns(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "GetMethod", 1)
+	ctrl.RecordCall(subject, "StructMethod")
+	ctrl.RecordCall(subject, "FooMethod", "argument").Do(func(string) {})
+
+	rets := ctrl.Call(subject, "GetMethod", 1)
+	assertEqual(t, []interface{}{"", nil}, rets)
+	if _, ok := rets[0].(string); !ok {
+		t.Errorf("zero value for a string return is a %T", rets[0])
+	}
+
+	rets = ctrl.Call(subject, "StructMethod")
+	assertEqual(t, []interface{}{TestStruct{}, (*TestStruct)(nil), []int(nil), nil}, rets)
+	if _, ok := rets[1].(*TestStruct); !ok {
+		t.Errorf("zero value for a pointer return is a %T", rets[1])
+	}
+	if rets[3] != nil {
+		t.Errorf("zero value for an interface return is %#v, want nil", rets[3])
+	}
+
+	// Do doesn't affect the return values.
+	assertEqual(t, []interface{}{0}, ctrl.Call(subject, "FooMethod", "argument"))
+	ctrl.Finish()
+}
+
+func TestReturnLazy(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	var value string
+	var err error
+	ctrl.RecordCall(subject, "GetMethod", 1).Return(
+		gomock.Lazy(func() interface{} { return value }),
+		gomock.Lazy(func() interface{} { return err }),
+	).Times(2)
+
+	value = "assigned later"
+	assertEqual(t, []interface{}{"assigned later", nil}, ctrl.Call(subject, "GetMethod", 1))
+
+	value, err = "", &notFoundError{}
+	rets := ctrl.Call(subject, "GetMethod", 1)
+	if e, ok := rets[1].(error); !ok || e != err {
+		t.Errorf("GetMethod returned %v, want the lazily assigned error", rets[1])
+	}
+	ctrl.Finish()
+}
+
+func TestReturnLazyTypeCheck(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "GetMethod", 1).Return(gomock.Lazy(func() interface{} { return 1 }), nil).Times(2)
+	// The failure is reported with Errorf, as the value may be computed on
+	// any goroutine, and the call returns zero values.
+	assertEqual(t, []interface{}{"", nil}, ctrl.Call(subject, "GetMethod", 1))
+	rep.assertFail("the Lazy value is not a string")
+	want := "wrong type of argument 0 to Return for *gomock_test.Subject.GetMethod: int is not assignable to string, as evaluated by Lazy"
+	if got := rep.log[len(rep.log)-1]; !strings.Contains(got, want) || !strings.Contains(got, "controller_test.go:") {
+		t.Errorf("got failure %q, want one containing %q", got, want)
+	}
+
+	done := make(chan []interface{})
+	go func() { done <- ctrl.Call(subject, "GetMethod", 1) }()
+	assertEqual(t, []interface{}{"", nil}, <-done)
+	if n := len(rep.log); n != 2 {
+		t.Errorf("got %d failures, want one per call: %q", n, rep.log)
+	}
+	ctrl.Finish()
+}
+
+func TestReturnSeq(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	fail := &notFoundError{}
+	ctrl.RecordCall(subject, "GetMethod", 1).ReturnSeq(
+		[]interface{}{"", fail},
+		[]interface{}{"one", nil},
+	)
+	assertEqual(t, []interface{}{"", fail}, ctrl.Call(subject, "GetMethod", 1))
+	assertEqual(t, []interface{}{"one", nil}, ctrl.Call(subject, "GetMethod", 1))
+	// The call is expected once per set.
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "GetMethod", 1)
+	}, "has already been made the max allowed number of times (2)")
+	ctrl.Finish()
+}
+
+func TestReturnSeqRepeatsLastSet(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "a").ReturnSeq([]interface{}{1}, []interface{}{2}).AnyTimes()
+	var got []interface{}
+	for i := 0; i < 4; i++ {
+		got = append(got, ctrl.Call(subject, "FooMethod", "a")...)
+	}
+	assertEqual(t, []interface{}{1, 2, 2, 2}, got)
+
+	// Fewer calls than sets are fine when the count is overridden.
+	ctrl.RecordCall(subject, "FooMethod", "b").Times(1).ReturnSeq([]interface{}{1}, []interface{}{2})
+	assertEqual(t, []interface{}{1}, ctrl.Call(subject, "FooMethod", "b"))
+	ctrl.Finish()
+}
+
+func TestReturnSeqWhileCheckingSatisfaction(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	call := ctrl.RecordCall(subject, "FooMethod", "a")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			ctrl.Satisfied()
+		}
+	}()
+	// ReturnSeq sets the number of calls under the controller's lock, as
+	// Times does, so this doesn't race with Satisfied.
+	call.ReturnSeq([]interface{}{1}, []interface{}{2})
+	<-done
+	assertEqual(t, []interface{}{1}, ctrl.Call(subject, "FooMethod", "a"))
+	assertEqual(t, []interface{}{2}, ctrl.Call(subject, "FooMethod", "a"))
+	ctrl.Finish()
+}
+
+func TestReturnSeqTypeChecks(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "GetMethod", 1).AnyTimes().ReturnSeq([]interface{}{"", nil}, []interface{}{""})
+	}, "wrong number of arguments to ReturnSeq (set 1) for *gomock_test.Subject.GetMethod: got 1, want 2", "controller_test.go:")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "GetMethod", 1).AnyTimes().ReturnSeq([]interface{}{1, nil})
+	}, "wrong type of argument 0 to ReturnSeq (set 0) for *gomock_test.Subject.GetMethod: int is not assignable to string")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "GetMethod", 1).AnyTimes().ReturnSeq()
+	}, "ReturnSeq for *gomock_test.Subject.GetMethod needs at least one set of return values")
+	ctrl.Finish()
+}
+
+func TestReturnTypeChecks(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "GetMethod", 1).AnyTimes().Return("value")
+	}, "wrong number of arguments to Return for *gomock_test.Subject.GetMethod: got 1, want 2",
+		"controller_test.go:")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "GetMethod", 1).AnyTimes().Return("value", "oops")
+	}, "wrong type of argument 1 to Return for *gomock_test.Subject.GetMethod: string is not assignable to error",
+		"controller_test.go:")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(subject, "FooMethod", "argument").AnyTimes().Return(nil)
+	}, "argument 0 to Return for *gomock_test.Subject.FooMethod is nil, but int is not nillable",
+		"controller_test.go:")
+}
+
+func TestReturnNilAndAssignableValues(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "GetMethod", 1).Return("one", nil)
+	ctrl.RecordCall(subject, "GetMethod", 2).Return("", (*notFoundError)(nil))
+	ctrl.RecordCall(subject, "GetMethod", 3).Return("", &notFoundError{})
+
+	rets := ctrl.Call(subject, "GetMethod", 1)
+	if rets[0].(string) != "one" || rets[1] != nil {
+		t.Errorf("GetMethod(1) returned %v, want [one <nil>]", rets)
+	}
+	// Typed values are converted to the return type, so the generated code's
+	// type assertions succeed.
+	if _, ok := ctrl.Call(subject, "GetMethod", 2)[1].(error); !ok {
+		t.Error("a typed nil returned for an error should be an error")
+	}
+	if err, ok := ctrl.Call(subject, "GetMethod", 3)[1].(error); !ok || err == nil {
+		t.Errorf("GetMethod(3) returned error %v, want a *notFoundError", err)
+	}
+	ctrl.Finish()
+}
+
+func TestUnorderedCalls(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	defer reporter.recoverUnexpectedFatal()
+	subjectTwo := new(Subject)
+	subjectOne := new(Subject)
+
+	ctrl.RecordCall(subjectOne, "FooMethod", "1")
+	ctrl.RecordCall(subjectOne, "BarMethod", "2")
+	ctrl.RecordCall(subjectTwo, "FooMethod", "3")
+	ctrl.RecordCall(subjectTwo, "BarMethod", "4")
+
+	// Make the calls in a different order, which should be fine.
+	ctrl.Call(subjectOne, "BarMethod", "2")
+	ctrl.Call(subjectTwo, "FooMethod", "3")
+	ctrl.Call(subjectTwo, "BarMethod", "4")
+	ctrl.Call(subjectOne, "FooMethod", "1")
+
+	reporter.assertPass("After making all calls in different order")
+
+	ctrl.Finish()
+
+	reporter.assertPass("After finish")
+}
+
+func commonTestOrderedCalls(t *testing.T) (reporter *ErrorReporter, ctrl *gomock.Controller, subjectOne, subjectTwo *Subject) {
+	reporter, ctrl = createFixtures(t)
+
+	subjectOne = new(Subject)
+	subjectTwo = new(Subject)
+
+	gomock.InOrder(
+		ctrl.RecordCall(subjectOne, "FooMethod", "1").AnyTimes(),
+		ctrl.RecordCall(subjectTwo, "FooMethod", "2"),
+		ctrl.RecordCall(subjectTwo, "BarMethod", "3"),
+	)
+
+	return
+}
+
+func TestOrderedCallsCorrect(t *testing.T) {
+	reporter, ctrl, subjectOne, subjectTwo := commonTestOrderedCalls(t)
+
+	ctrl.Call(subjectOne, "FooMethod", "1")
+	ctrl.Call(subjectTwo, "FooMethod", "2")
+	ctrl.Call(subjectTwo, "BarMethod", "3")
+
+	ctrl.Finish()
+
+	reporter.assertPass("After finish")
+}
+
+func TestOrderedCallsInCorrect(t *testing.T) {
+	reporter, ctrl, subjectOne, subjectTwo := commonTestOrderedCalls(t)
+
+	ctrl.Call(subjectOne, "FooMethod", "1")
+	reporter.assertFatal(func() {
+		// FooMethod(2) should be called before BarMethod(3)
+		ctrl.Call(subjectTwo, "BarMethod", "3")
+	}, "Unexpected call to", "Subject.BarMethod([3])", "doesn't have a prerequisite call satisfied")
+}
+
+// Test that calls that are prerequisites to other calls but have maxCalls >
+// minCalls are removed from the expected call set.
+func TestOrderedCallsWithPreReqMaxUnbounded(t *testing.T) {
+	reporter, ctrl, subjectOne, subjectTwo := commonTestOrderedCalls(t)
+
+	// Initially we should be able to call FooMethod("1") as many times as we
+	// want.
+	ctrl.Call(subjectOne, "FooMethod", "1")
+	ctrl.Call(subjectOne, "FooMethod", "1")
+
+	// But calling something that has it as a prerequite should remove it from
+	// the expected call set. This allows tests to ensure that FooMethod("1") is
+	// *not* called after FooMethod("2").
+	ctrl.Call(subjectTwo, "FooMethod", "2")
+
+	// Therefore this call should fail:
+	reporter.assertFatal(func() {
+		ctrl.Call(subjectOne, "FooMethod", "1")
+	})
+}
+
+func TestCallAfterLoopPanic(t *testing.T) {
+	_, ctrl := createFixtures(t)
+
+	subject := new(Subject)
+
+	firstCall := ctrl.RecordCall(subject, "FooMethod", "1")
+	secondCall := ctrl.RecordCall(subject, "FooMethod", "2")
+	thirdCall := ctrl.RecordCall(subject, "FooMethod", "3")
+
+	gomock.InOrder(firstCall, secondCall, thirdCall)
+
+	defer func() {
+		err := recover()
+		if err == nil {
+			t.Error("Call.After creation of dependency loop did not panic.")
+		}
+	}()
+
+	// This should panic due to dependency loop.
+	firstCall.After(thirdCall)
+}
+
+func TestCallAfterCycles(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	a := ctrl.RecordCall(subject, "FooMethod", "a")
+	b := ctrl.RecordCall(subject, "FooMethod", "b").After(a)
+	rep.assertFatal(func() {
+		a.After(b)
+	}, "Loop in call order: *gomock_test.Subject.FooMethod(is equal to a) ",
+		"is a prerequisite to *gomock_test.Subject.FooMethod(is equal to b) ",
+		"controller_test.go:")
+
+	c := ctrl.RecordCall(subject, "BarMethod", "c")
+	d := ctrl.RecordCall(subject, "BarMethod", "d")
+	e := ctrl.RecordCall(subject, "BarMethod", "e")
+	gomock.InOrder(b, c, d, e)
+	rep.assertFatal(func() {
+		gomock.InOrder(e, a)
+	}, "Loop in call order", "(is equal to a)", "(is equal to e)")
+	rep.assertFatal(func() {
+		c.After(c)
+	}, "A call isn't allowed to be its own prerequisite: *gomock_test.Subject.BarMethod(is equal to c)")
+
+	for _, arg := range []string{"a", "b"} {
+		ctrl.Call(subject, "FooMethod", arg)
+	}
+	for _, arg := range []string{"c", "d", "e"} {
+		ctrl.Call(subject, "BarMethod", arg)
+	}
+	ctrl.Finish()
+}
+
+func TestCallAfterDifferentController(t *testing.T) {
+	rep1, ctrl1 := createFixtures(t)
+	rep2, ctrl2 := createFixtures(t)
+	defer rep1.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	first := ctrl1.RecordCall(subject, "FooMethod", "first")
+	second := ctrl2.RecordCall(subject, "FooMethod", "second")
+	rep2.assertFatal(func() {
+		second.After(first)
+	}, "*gomock_test.Subject.FooMethod(is equal to second) registered at ",
+		"can't be called after *gomock_test.Subject.FooMethod(is equal to first) registered at ",
+		"which belongs to a different Controller")
+	rep2.assertFatal(func() {
+		gomock.InOrder(first, second)
+	}, "which belongs to a different Controller")
+	rep1.assertPass("the prerequisite's controller isn't affected")
+
+	ctrl1.Call(subject, "FooMethod", "first")
+	ctrl2.Call(subject, "FooMethod", "second")
+	ctrl1.Finish()
+	ctrl2.Finish()
+}
+
+func TestCallAfterDiamond(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	// top -> left, right -> bottom shares prerequisites without a cycle.
+	top := ctrl.RecordCall(subject, "FooMethod", "top")
+	left := ctrl.RecordCall(subject, "FooMethod", "left").After(top)
+	right := ctrl.RecordCall(subject, "FooMethod", "right").After(top)
+	ctrl.RecordCall(subject, "FooMethod", "bottom").After(left).After(right)
+	rep.assertPass("a diamond of prerequisites is not a cycle")
+
+	for _, arg := range []string{"top", "right", "left", "bottom"} {
+		ctrl.Call(subject, "FooMethod", arg)
+	}
+	ctrl.Finish()
+}
+
+func TestWithOverridableExpectations(t *testing.T) {
+	rep := NewErrorReporter(t)
+	defer rep.recoverUnexpectedFatal()
+	ctrl := gomock.NewController(rep, gomock.WithOverridableExpectations())
+	s := new(Subject)
+
+	ctrl.RecordCall(s, "FooMethod", "a").Return(1)
+	ctrl.RecordCall(s, "FooMethod", "b").Return(2)
+	ctrl.RecordCall(s, "FooMethod", "a").Return(3)
+	assertEqual(t, []interface{}{3}, ctrl.Call(s, "FooMethod", "a"))
+	assertEqual(t, []interface{}{2}, ctrl.Call(s, "FooMethod", "b"))
+	// The overridden call isn't missing.
+	ctrl.Finish()
+
+	// Without the option, calls with the same matchers are consumed in turn.
+	rep2, ctrl2 := createFixtures(t)
+	defer rep2.recoverUnexpectedFatal()
+	ctrl2.RecordCall(s, "FooMethod", "a").Return(1)
+	ctrl2.RecordCall(s, "FooMethod", "a").Return(3)
+	assertEqual(t, []interface{}{1}, ctrl2.Call(s, "FooMethod", "a"))
+	assertEqual(t, []interface{}{3}, ctrl2.Call(s, "FooMethod", "a"))
+	ctrl2.Finish()
+}
+
+func TestWithExpectedCallsLogger(t *testing.T) {
+	rep := NewErrorReporter(t)
+	defer rep.recoverUnexpectedFatal()
+	var log bytes.Buffer
+	ctrl := gomock.NewController(rep, gomock.WithExpectedCallsLogger(&log))
+	s := new(Subject)
+
+	call := ctrl.RecordCall(s, "FooMethod", gomock.Any()).Times(2)
+	ctrl.Call(s, "FooMethod", "a")
+	ctrl.Call(s, "FooMethod", "b")
+	ctrl.Finish()
+
+	lines := strings.Split(strings.TrimSuffix(log.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines logged, want 2:\n%s", len(lines), log.String())
+	}
+	for i, arg := range []string{"a", "b"} {
+		prefix := fmt.Sprintf("*gomock_test.Subject.FooMethod([%s]) at ", arg)
+		suffix := " matched expected call at " + call.Origin()
+		if !strings.HasPrefix(lines[i], prefix) || !strings.HasSuffix(lines[i], suffix) {
+			t.Errorf("line %d: got %q, want %q...%q", i, lines[i], prefix, suffix)
+		}
+	}
+}
+
+func TestWithVerbose(t *testing.T) {
+	rep := NewErrorReporter(t)
+	defer rep.recoverUnexpectedFatal()
+	var log bytes.Buffer
+	ctrl := gomock.NewController(rep, gomock.WithVerbose(&log))
+	s := new(Subject)
+
+	call := ctrl.RecordCall(s, "FooMethod", "a")
+	ctrl.Call(s, "FooMethod", "a")
+	rep.assertFatal(func() {
+		ctrl.Call(s, "FooMethod", "b")
+	})
+
+	journal := ctrl.Journal()
+	if len(journal) != 3 {
+		t.Fatalf("got %d journal entries, want 3: %v", len(journal), journal)
+	}
+	recorded, matched, unmatched := journal[0], journal[1], journal[2]
+	if recorded.Kind != gomock.Recorded || recorded.Call != call || recorded.Origin != call.Origin() ||
+		!reflect.DeepEqual(recorded.Args, []string{"is equal to a"}) {
+		t.Errorf("got recording entry %+v", recorded)
+	}
+	if matched.Kind != gomock.Matched || matched.Call != call || matched.Receiver != "*gomock_test.Subject" ||
+		matched.Method != "FooMethod" || !reflect.DeepEqual(matched.Args, []string{"a"}) {
+		t.Errorf("got matched entry %+v", matched)
+	}
+	if unmatched.Kind != gomock.Unmatched || unmatched.Call != nil || !reflect.DeepEqual(unmatched.Args, []string{"b"}) ||
+		!strings.Contains(unmatched.Reason, "doesn't match the argument at index 0") {
+		t.Errorf("got unmatched entry %+v", unmatched)
+	}
+
+	want := "recorded expected call *gomock_test.Subject.FooMethod(is equal to a) at " + call.Origin() + "\n" +
+		"call *gomock_test.Subject.FooMethod(a) at " + matched.Origin + " matched expected call at " + call.Origin() + "\n" +
+		"call *gomock_test.Subject.FooMethod(b) at " + unmatched.Origin + " matched no expected call because: " + unmatched.Reason + "\n"
+	if got := log.String(); got != want {
+		t.Errorf("got log:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestJournalWithoutVerbose(t *testing.T) {
+	_, ctrl := createFixtures(t)
+	s := new(Subject)
+
+	ctrl.RecordCall(s, "FooMethod", "a")
+	ctrl.Call(s, "FooMethod", "a")
+	if journal := ctrl.Journal(); len(journal) != 0 {
+		t.Errorf("got journal %v, want none", journal)
+	}
+}
+
+func TestWithNameForReceiver(t *testing.T) {
+	rep := NewErrorReporter(t)
+	defer rep.recoverUnexpectedFatal()
+	s := new(Subject)
+	ctrl := gomock.NewController(rep, gomock.WithNameForReceiver(func(r interface{}) string {
+		if r == s {
+			return "subject"
+		}
+		return fmt.Sprintf("%T", r)
+	}))
+
+	ctrl.RecordCall(s, "FooMethod", "a")
+	rep.assertFatal(func() {
+		ctrl.Call(s, "FooMethod", "b")
+	}, "Unexpected call to subject.FooMethod([b])", "Want: is equal to a")
+	rep.assertFatal(ctrl.Finish, "aborting test due to missing call(s)")
+	if msg := rep.log[len(rep.log)-1]; !strings.Contains(msg, "subject.FooMethod:\n\t(is equal to a)") {
+		t.Errorf("got %q, want the receiver named subject", msg)
+	}
+}
+
+func TestWithStrictOrder(t *testing.T) {
+	rep := NewErrorReporter(t)
+	defer rep.recoverUnexpectedFatal()
+	ctrl := gomock.NewController(rep, gomock.WithStrictOrder())
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "1")
+	ctrl.RecordCall(subject, "BarMethod", "2")
+	ctrl.RecordCall(subject, "FooMethod", "3")
+
+	ctrl.Call(subject, "FooMethod", "1")
+	ctrl.Call(subject, "BarMethod", "2")
+	ctrl.Call(subject, "FooMethod", "3")
+
+	ctrl.Finish()
+	rep.assertPass("calls made in the recorded order")
+}
+
+func TestWithStrictOrderOutOfOrder(t *testing.T) {
+	rep := NewErrorReporter(t)
+	defer rep.recoverUnexpectedFatal()
+	ctrl := gomock.NewController(rep, gomock.WithStrictOrder())
+	subject := new(Subject)
+
+	first := ctrl.RecordCall(subject, "FooMethod", "1")
+	second := ctrl.RecordCall(subject, "BarMethod", "2")
+
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "BarMethod", "2")
+	}, "Unexpected call to *gomock_test.Subject.BarMethod([2]) at",
+		"Expected call at "+second.Origin()+" is out of order, the call expected next is:\n"+
+			"*gomock_test.Subject.FooMethod(is equal to 1) registered at "+first.Origin())
+}
+
+func TestWithStrictOrderStubsUnordered(t *testing.T) {
+	rep := NewErrorReporter(t)
+	defer rep.recoverUnexpectedFatal()
+	ctrl := gomock.NewController(rep, gomock.WithStrictOrder())
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "1")
+	ctrl.RecordCall(subject, "FooMethod", "stub").AnyTimes()
+	ctrl.Default(subject, "BarMethod", gomock.Any())
+	ctrl.RecordCall(subject, "FooMethod", "2")
+
+	ctrl.Call(subject, "FooMethod", "stub")
+	ctrl.Call(subject, "BarMethod", "x")
+	ctrl.Call(subject, "FooMethod", "1")
+	ctrl.Call(subject, "BarMethod", "y")
+	ctrl.Call(subject, "FooMethod", "2")
+	ctrl.Call(subject, "FooMethod", "stub")
+
+	ctrl.Finish()
+	rep.assertPass("stubs called in any order")
+}
+
+func TestRelaxed(t *testing.T) {
+	rep := NewErrorReporter(t)
+	defer rep.recoverUnexpectedFatal()
+	ctrl := gomock.NewController(rep, gomock.Relaxed())
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "GetMethod", 1).Return("one", nil)
+
+	rets := ctrl.Call(subject, "GetMethod", 2)
+	if want := []interface{}{"", nil}; !reflect.DeepEqual(rets, want) {
+		t.Errorf("got %v, want %v", rets, want)
+	}
+	if msg := rep.log[len(rep.log)-1]; !strings.Contains(msg, "Unexpected call to *gomock_test.Subject.GetMethod([2])") ||
+		!strings.Contains(msg, "returns zero values") {
+		t.Errorf("got log %q, want the unexpected call", msg)
+	}
+	rets = ctrl.Call(subject, "ActOnTestStructMethod", TestStruct{}, 3)
+	if want := []interface{}{0}; !reflect.DeepEqual(rets, want) {
+		t.Errorf("got %v, want %v", rets, want)
+	}
+	rep.assertPass("unexpected calls to a relaxed controller")
+
+	ctrl.Call(subject, "GetMethod", 1)
+	ctrl.Finish()
+	rep.assertPass("expected calls made")
+}
+
+func TestRelaxedMissingCalls(t *testing.T) {
+	rep := NewErrorReporter(t)
+	defer rep.recoverUnexpectedFatal()
+	ctrl := gomock.NewController(rep, gomock.Relaxed())
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "a")
+	ctrl.RecordCall(subject, "BarMethod", "b").Times(0)
+
+	ctrl.Call(subject, "FooMethod", "b")
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "BarMethod", "b")
+	}, "Unexpected call to *gomock_test.Subject.BarMethod([b])", "was explicitly forbidden")
+	rep.assertFatal(ctrl.Finish, "aborting test due to missing call(s)")
+}
+
+func TestNotRelaxed(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "GetMethod", 1).Return("one", nil)
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "GetMethod", 2)
+	}, "Unexpected call to *gomock_test.Subject.GetMethod([2])")
+}
+
+func TestSatisfied(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	subject := new(Subject)
+
+	if !ctrl.Satisfied() {
+		t.Error("got unsatisfied controller without expected calls")
+	}
+	foo := ctrl.RecordCall(subject, "FooMethod", "a").Times(2)
+	bar := ctrl.RecordCall(subject, "BarMethod", "b")
+	ctrl.RecordCall(subject, "FooMethod", "c").AnyTimes()
+	ctrl.Default(subject, "BarMethod", gomock.Any())
+
+	if ctrl.Satisfied() {
+		t.Error("got satisfied controller before the calls")
+	}
+	if got, want := ctrl.PendingCalls(), []*gomock.Call{foo, bar}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got pending calls %v, want %v", got, want)
+	}
+
+	ctrl.Call(subject, "BarMethod", "b")
+	ctrl.Call(subject, "FooMethod", "a")
+	if got, want := ctrl.PendingCalls(), []*gomock.Call{foo}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got pending calls %v, want %v", got, want)
+	}
+
+	ctrl.Call(subject, "FooMethod", "a")
+	if !ctrl.Satisfied() {
+		t.Errorf("got unsatisfied controller, pending calls %v", ctrl.PendingCalls())
+	}
+	ctrl.Finish()
+	rep.assertPass("all calls made")
+}
+
+func TestWaitForSatisfaction(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "a")
+	ctrl.RecordCall(subject, "BarMethod", "b")
+
+	go func() {
+		ctrl.Call(subject, "FooMethod", "a")
+		ctrl.Call(subject, "BarMethod", "b")
+	}()
+	if err := ctrl.WaitForSatisfaction(10 * time.Second); err != nil {
+		t.Fatalf("WaitForSatisfaction: %v", err)
+	}
+	ctrl.Finish()
+	rep.assertPass("all calls made")
+}
+
+func TestWaitForSatisfactionTimeout(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", "a")
+	ctrl.RecordCall(subject, "BarMethod", "b")
+	ctrl.Call(subject, "FooMethod", "a")
+
+	err := ctrl.WaitForSatisfaction(10 * time.Millisecond)
+	if err == nil {
+		t.Fatal("got no error, want a timeout")
+	}
+	for _, want := range []string{
+		"1 expected call(s) not satisfied within 10ms",
+		"*gomock_test.Subject.BarMethod(is equal to b) registered at",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %q, want it to contain %q", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "FooMethod") {
+		t.Errorf("got error %q, want the satisfied call left out", err)
+	}
+	ctrl.Call(subject, "BarMethod", "b")
+	ctrl.Finish()
+	rep.assertPass("all calls made")
+}
+
+func TestRemoveCall(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	def := ctrl.RecordCall(subject, "FooMethod", "default").Return(1)
+	ctrl.RemoveCall(def)
+	// Removing the call again does nothing.
+	ctrl.RemoveCall(def)
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "FooMethod", "default")
+	}, "there are no expected calls of the method \"FooMethod\" for that receiver")
+	// Nor is it missing.
+	ctrl.Finish()
+}
+
+func TestRemoveCallErrors(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	call := ctrl.RecordCall(subject, "FooMethod", "argument").MinTimes(1)
+	ctrl.Call(subject, "FooMethod", "argument")
+	ctrl.Call(subject, "FooMethod", "argument")
+	rep.assertFatal(func() {
+		ctrl.RemoveCall(call)
+	}, "gomock: can't remove *gomock_test.Subject.FooMethod(is equal to argument) registered at",
+		"which has already been made 2 time(s), more than its minimum of 1")
+
+	otherRep, other := createFixtures(t)
+	otherRep.assertFatal(func() {
+		other.RemoveCall(call)
+	}, "which belongs to a different Controller")
+
+	// The call is still expected.
+	ctrl.Call(subject, "FooMethod", "argument")
+	ctrl.Finish()
+}
+
+func TestRemoveCallMadeUpToItsMinimum(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	// A call made fewer times than its minimum is still removable.
+	call := ctrl.RecordCall(subject, "FooMethod", "argument").MinTimes(2)
+	ctrl.Call(subject, "FooMethod", "argument")
+	ctrl.RemoveCall(call)
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "FooMethod", "argument")
+	}, "there are no expected calls of the method \"FooMethod\" for that receiver")
+
+	// As is one made exactly its minimum number of times.
+	call = ctrl.RecordCall(subject, "BarMethod", "argument").Times(1)
+	ctrl.Call(subject, "BarMethod", "argument")
+	ctrl.RemoveCall(call)
+	ctrl.Finish()
+}
+
+func TestRemoveCallInSequence(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	first := ctrl.RecordCall(subject, "FooMethod", "1")
+	second := ctrl.RecordCall(subject, "FooMethod", "2")
+	ctrl.RecordCall(subject, "FooMethod", "3")
+	gomock.InOrder(first, second, ctrl.RecordCall(subject, "BarMethod", "4"))
+	ctrl.RemoveCall(second)
+
+	// The last call now follows the first one.
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "BarMethod", "4")
+	}, "doesn't have a prerequisite call satisfied", "FooMethod(is equal to 1)")
+	ctrl.Call(subject, "FooMethod", "1")
+	ctrl.Call(subject, "BarMethod", "4")
+	ctrl.Call(subject, "FooMethod", "3")
+	ctrl.Finish()
+}
+
+func TestCallPrereqs(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	first := ctrl.RecordCall(subject, "FooMethod", "1")
+	second := ctrl.RecordCall(subject, "FooMethod", "2")
+	third := ctrl.RecordCall(subject, "BarMethod", "3").AfterAll(first, second)
+
+	prereqs := third.Prereqs()
+	assertEqual(t, []*gomock.Call{first, second}, prereqs)
+	prereqs[0] = nil
+	assertEqual(t, []*gomock.Call{first, second}, third.Prereqs())
+	assertEqual(t, []*gomock.Call(nil), first.Prereqs())
+
+	ctrl.Call(subject, "FooMethod", "1")
+	ctrl.Call(subject, "FooMethod", "2")
+	ctrl.Call(subject, "BarMethod", "3")
+	// Once the call is matched, it doesn't wait for anything.
+	assertEqual(t, []*gomock.Call(nil), third.Prereqs())
+	ctrl.Finish()
+}
+
+func TestPendingGraphDOT(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	done := ctrl.RecordCall(subject, "BarMethod", "done")
+	calls := []*gomock.Call{
+		ctrl.RecordCall(subject, "FooMethod", "1"),
+		ctrl.RecordCall(subject, "FooMethod", `"quoted"`).Name("second"),
+		ctrl.RecordCall(subject, "BarMethod", gomock.Any()),
+	}
+	gomock.InOrder(calls...)
+	ctrl.Call(subject, "BarMethod", "done")
+
+	want := `digraph gomock {
+	c2 [label="*gomock_test.Subject.FooMethod(is equal to 1)\n` + calls[0].Origin() + `"];
+	c3 [label="*gomock_test.Subject.FooMethod(is equal to \"quoted\") named \"second\"\n` + calls[1].Origin() + `"];
+	c4 [label="*gomock_test.Subject.BarMethod(is anything)\n` + calls[2].Origin() + `"];
+	c2 -> c3;
+	c3 -> c4;
+}
+`
+	if got := ctrl.PendingGraphDOT(); got != want {
+		t.Errorf("PendingGraphDOT:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if strings.Contains(ctrl.PendingGraphDOT(), done.Origin()) {
+		t.Error("PendingGraphDOT includes a satisfied call")
+	}
+
+	ctrl.Call(subject, "FooMethod", "1")
+	want = `digraph gomock {
+	c3 [label="*gomock_test.Subject.FooMethod(is equal to \"quoted\") named \"second\"\n` + calls[1].Origin() + `"];
+	c4 [label="*gomock_test.Subject.BarMethod(is anything)\n` + calls[2].Origin() + `"];
+	c3 -> c4;
+}
+`
+	if got := ctrl.PendingGraphDOT(); got != want {
+		t.Errorf("PendingGraphDOT after a call:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	ctrl.Call(subject, "FooMethod", `"quoted"`)
+	ctrl.Call(subject, "BarMethod", "x")
+	ctrl.Finish()
+}
+
+func TestCallAfterAll(t *testing.T) {
+	orders := [][]string{{"1", "2", "3"}, {"3", "1", "2"}, {"2", "3", "1"}}
+	for _, order := range orders {
+		t.Run(strings.Join(order, ""), func(t *testing.T) {
+			rep, ctrl := createFixtures(t)
+			defer rep.recoverUnexpectedFatal()
+			subject := new(Subject)
+
+			setup := []*gomock.Call{
+				ctrl.RecordCall(subject, "FooMethod", "1"),
+				ctrl.RecordCall(subject, "FooMethod", "2"),
+				ctrl.RecordCall(subject, "FooMethod", "3"),
+			}
+			ctrl.RecordCall(subject, "BarMethod", "done").AfterAll(setup...)
+
+			for i, arg := range order {
+				if i == 2 {
+					rep.assertFatal(func() {
+						ctrl.Call(subject, "BarMethod", "done")
+					}, "doesn't have a prerequisite call satisfied")
+				}
+				ctrl.Call(subject, "FooMethod", arg)
+			}
+			ctrl.Call(subject, "BarMethod", "done")
+			ctrl.Finish()
+		})
+	}
+}
+
+func TestAnyOrderInSequence(t *testing.T) {
+	sequences := [][]string{
+		{"metric", "open", "send", "close"},
+		{"open", "metric", "send", "close"},
+		{"open", "send", "metric", "metric", "close"},
+		{"open", "send", "close", "metric"},
+		{"open", "send", "close"},
+	}
+	for _, seq := range sequences {
+		t.Run(strings.Join(seq, ","), func(t *testing.T) {
+			rep, ctrl := createFixtures(t)
+			defer rep.recoverUnexpectedFatal()
+			subject := new(Subject)
+
+			gomock.InOrder(
+				ctrl.RecordCall(subject, "FooMethod", "open"),
+				ctrl.RecordCall(subject, "BarMethod", "metric").AnyTimes().AnyOrder(),
+				ctrl.RecordCall(subject, "FooMethod", "send"),
+				ctrl.RecordCall(subject, "FooMethod", "close"),
+			)
+			for _, arg := range seq {
+				if arg == "metric" {
+					ctrl.Call(subject, "BarMethod", arg)
+				} else {
+					ctrl.Call(subject, "FooMethod", arg)
+				}
+			}
+			ctrl.Finish()
+		})
+	}
+}
+
+func TestAnyOrderKeepsSequence(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	gomock.InOrder(
+		ctrl.RecordCall(subject, "FooMethod", "open"),
+		ctrl.RecordCall(subject, "BarMethod", "metric").AnyTimes().AnyOrder(),
+		ctrl.RecordCall(subject, "FooMethod", "close"),
+	)
+	// The calls around the AnyOrder call stay ordered.
+	rep.assertFatal(func() {
+		ctrl.Call(subject, "FooMethod", "close")
+	}, "doesn't have a prerequisite call satisfied", "(is equal to open)")
+	ctrl.Call(subject, "FooMethod", "open")
+	ctrl.Call(subject, "FooMethod", "close")
+	ctrl.Finish()
+}
+
+func TestPanicOverridesExpectationChecks(t *testing.T) {
+	reporter := NewErrorReporter(t)
+	ctrl := gomock.NewController(reporter)
+
+	reporter.assertFatal(func() {
+		ctrl.RecordCall(new(Subject), "FooMethod", "1")
+		defer ctrl.Finish()
+		reporter.Fatalf("Intentional panic")
+	}, "the test panicked: ")
+	if len(reporter.log) != 3 || !strings.Contains(reporter.log[1], "missing call(s) when the test panicked:\n*gomock_test.Subject.FooMethod:") {
+		t.Errorf("got log %q, want the intentional panic, the missing call, then the panic", reporter.log)
+	}
+}
+
+// finishWhilePanicking calls Finish deferred by a function panicking with
+// "boom", and returns what it ends up panicking with: the fatal token of
+// the ErrorReporter, as Finish stops the panic and reports it with Fatalf.
+func finishWhilePanicking(ctrl *gomock.Controller) (recovered interface{}) {
+	defer func() {
+		recovered = recover()
+	}()
+	defer ctrl.Finish()
+	panic("boom")
+}
+
+func TestFinishWhilePanickingWithMissingCalls(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	s := new(Subject)
+
+	call := ctrl.RecordCall(s, "FooMethod", "1")
+	if got := finishWhilePanicking(ctrl); got != &rep.fatalToken {
+		t.Errorf("got panic %v, want the fatal failure reporting boom", got)
+	}
+	// The log shows both the missing call, and the panic with its stack.
+	want := "missing call(s) when the test panicked:\n" +
+		"*gomock_test.Subject.FooMethod:\n" +
+		"\t(is equal to 1) registered at " + call.Origin() + ", expected 1..1 calls, got 0"
+	if len(rep.log) != 2 || rep.log[0] != want {
+		t.Errorf("got log %q, want %q then the panic", rep.log, want)
+	} else if !strings.HasPrefix(rep.log[1], "the test panicked: boom\n") || !strings.Contains(rep.log[1], "finishWhilePanicking") {
+		t.Errorf("got failure %q, want the panic and its stack", rep.log[1])
+	}
+	// The controller isn't left locked.
+	if ctrl.Satisfied() {
+		t.Error("got satisfied controller")
+	}
+}
+
+func TestFinishWhilePanickingWithoutMissingCalls(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	s := new(Subject)
+
+	ctrl.RecordCall(s, "FooMethod", "1")
+	ctrl.Call(s, "FooMethod", "1")
+	if got := finishWhilePanicking(ctrl); got != &rep.fatalToken {
+		t.Errorf("got panic %v, want the fatal failure reporting boom", got)
+	}
+	if len(rep.log) != 1 || !strings.HasPrefix(rep.log[0], "the test panicked: boom\n") {
+		t.Errorf("got log %q, want the panic only", rep.log)
+	}
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "FooMethod", "2")
+	}, "controller already finished")
+}
+
+func TestSetArgWithBadType(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer ctrl.Finish()
+
+	s := new(Subject)
+	// This should catch a type error:
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "FooMethod", "1").SetArg(0, "blah")
+	})
+	ctrl.Call(s, "FooMethod", "1")
+}
+
+func TestMostSpecificCallMatches(t *testing.T) {
+	for _, specificFirst := range []bool{true, false} {
+		t.Run(fmt.Sprintf("specificFirst=%v", specificFirst), func(t *testing.T) {
+			rep, ctrl := createFixtures(t)
+			defer rep.recoverUnexpectedFatal()
+			s := new(Subject)
+
+			record := []func(){
+				func() { ctrl.RecordCall(s, "ActOnTestStructMethod", gomock.Any(), 5).Return(5) },
+				func() { ctrl.RecordCall(s, "ActOnTestStructMethod", gomock.Any(), gomock.Any()).Return(0).AnyTimes() },
+			}
+			if !specificFirst {
+				record[0], record[1] = record[1], record[0]
+			}
+			for _, r := range record {
+				r()
+			}
+
+			assertEqual(t, []interface{}{0}, ctrl.Call(s, "ActOnTestStructMethod", TestStruct{}, 1))
+			assertEqual(t, []interface{}{5}, ctrl.Call(s, "ActOnTestStructMethod", TestStruct{}, 5))
+			// Once the specific call is exhausted, the broad one matches.
+			assertEqual(t, []interface{}{0}, ctrl.Call(s, "ActOnTestStructMethod", TestStruct{}, 5))
+			ctrl.Finish()
+		})
+	}
+}
+
+func TestIdenticalCallsFIFO(t *testing.T) {
+	for run := 0; run < 20; run++ {
+		t.Run(fmt.Sprint(run), func(t *testing.T) {
+			rep, ctrl := createFixtures(t)
+			defer rep.recoverUnexpectedFatal()
+			s := new(Subject)
+
+			for i := 1; i <= 10; i++ {
+				ctrl.RecordCall(s, "FooMethod", "next").Return(i)
+			}
+			for i := 1; i <= 10; i++ {
+				assertEqual(t, []interface{}{i}, ctrl.Call(s, "FooMethod", "next"))
+			}
+			ctrl.Finish()
+		})
+	}
+}
+
+func TestMostSpecificCallCaptor(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	s := new(Subject)
+
+	var arg string
+	ctrl.RecordCall(s, "FooMethod", gomock.Capture(&arg)).Return(0).AnyTimes()
+	ctrl.RecordCall(s, "FooMethod", gomock.CaptureMatching(nil, gomock.Eq("b"))).Return(2)
+	assertEqual(t, []interface{}{2}, ctrl.Call(s, "FooMethod", "b"))
+	assertEqual(t, []interface{}{0}, ctrl.Call(s, "FooMethod", "a"))
+	ctrl.Finish()
+}
+
+func TestDefaultCalls(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	s := new(Subject)
+
+	ctrl.Default(s, "FooMethod", gomock.Any()).Return(-1)
+	ctrl.Default(s, "BarMethod", "x").Return(-1)
+	// A normal expectation wins over a default one, even if it's less
+	// specific.
+	ctrl.RecordCall(s, "FooMethod", "1").Return(1)
+	ctrl.RecordCall(s, "BarMethod", gomock.Any()).Return(1)
+	assertEqual(t, []interface{}{1}, ctrl.Call(s, "FooMethod", "1"))
+	assertEqual(t, []interface{}{-1}, ctrl.Call(s, "FooMethod", "1"))
+	assertEqual(t, []interface{}{-1}, ctrl.Call(s, "FooMethod", "2"))
+	assertEqual(t, []interface{}{1}, ctrl.Call(s, "BarMethod", "x"))
+
+	// BarMethod's default was never matched, which isn't a failure.
+	ctrl.Finish()
+	rep.assertPass("unused defaults aren't missing")
+}
+
+func TestDefaultCallsWithCardinality(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	s := new(Subject)
+
+	call := ctrl.Default(s, "FooMethod", "a").Times(2)
+	if !strings.Contains(call.Origin(), "controller_test.go") {
+		t.Errorf("got origin %s, want the caller of Default", call.Origin())
+	}
+	ctrl.Call(s, "FooMethod", "a")
+	ctrl.Finish()
+	rep.assertPass("defaults aren't missing even when called fewer times than declared")
+}
+
+func TestTimes0(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer ctrl.Finish()
+
+	s := new(Subject)
+	call := ctrl.RecordCall(s, "FooMethod", "arg").Times(0)
+	rep.assertFatal(func() {
+		ctrl.Call(s, "FooMethod", "arg")
+	}, "because: *gomock_test.Subject.FooMethod(is equal to arg) was explicitly forbidden at "+call.Origin())
+}
+
+func TestTimes0NotCalled(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+
+	s := new(Subject)
+	ctrl.RecordCall(s, "FooMethod", "arg").Times(0)
+	ctrl.RecordCall(s, "BarMethod", "arg").MaxTimes(0)
+	ctrl.Finish()
+	rep.assertPass("forbidden calls aren't missing")
+}
+
+func TestTimes0WithBroaderExpectation(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+
+	s := new(Subject)
+	ctrl.RecordCall(s, "FooMethod", gomock.Any()).Return(1).AnyTimes()
+	ctrl.RecordCall(s, "FooMethod", "forbidden").Times(0)
+
+	assertEqual(t, []interface{}{1}, ctrl.Call(s, "FooMethod", "allowed"))
+	rep.assertFatal(func() {
+		ctrl.Call(s, "FooMethod", "forbidden")
+	}, "FooMethod(is equal to forbidden) was explicitly forbidden at")
+	ctrl.Finish()
+}
+
+func TestVariadicMatching(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+
+	s := new(Subject)
+	ctrl.RecordCall(s, "VariadicMethod", 0, "1", "2")
+	ctrl.Call(s, "VariadicMethod", 0, "1", "2")
+	ctrl.Finish()
+	rep.assertPass("variadic matching works")
+}
+
+func TestVariadicNoMatch(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+
+	s := new(Subject)
+	ctrl.RecordCall(s, "VariadicMethod", 0)
+	rep.assertFatal(func() {
+		ctrl.Call(s, "VariadicMethod", 1)
+	}, "Expected call at", "doesn't match the argument at index 0",
+		"Got: 1\nWant: is equal to 0")
+	ctrl.Call(s, "VariadicMethod", 0)
+	ctrl.Finish()
+}
+
+func TestVariadicMatchingWithSlice(t *testing.T) {
+	testCases := [][]string{
+		{"1"},
+		{"1", "2"},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d arguments", len(tc)), func(t *testing.T) {
+			rep, ctrl := createFixtures(t)
+			defer rep.recoverUnexpectedFatal()
+
+			s := new(Subject)
+			ctrl.RecordCall(s, "VariadicMethod", 1, tc)
+			args := make([]interface{}, len(tc)+1)
+			args[0] = 1
+			for i, arg := range tc {
+				args[i+1] = arg
+			}
+			ctrl.Call(s, "VariadicMethod", args...)
+			ctrl.Finish()
+			rep.assertPass("slices can be used as matchers for variadic arguments")
+		})
+	}
+}
+
+func TestVariadicPerElementMatchers(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	s := new(Subject)
+
+	ctrl.RecordCall(s, "LogMethod", "%d %s", 1, gomock.Any())
+	// Every variadic matcher is checked, not just the first one.
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "VariadicMethod", 0, "a", "b")
+		ctrl.Call(s, "VariadicMethod", 0, "a", "c")
+	}, "doesn't match the argument at index 2", "Got: c\nWant: is equal to b")
+	rep.assertFatal(func() {
+		ctrl.Call(s, "LogMethod", "%d %s", 1)
+	}, "has the wrong number of arguments. Got: 2, want: 3")
+	rep.assertFatal(func() {
+		ctrl.Call(s, "LogMethod", "%d %s", 2, "x")
+	}, "doesn't match the argument at index 1")
+	ctrl.Call(s, "LogMethod", "%d %s", 1, "x")
+	ctrl.Call(s, "VariadicMethod", 0, "a", "b")
+	ctrl.Finish()
+}
+
+func TestVariadicPackedSliceMatcher(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	s := new(Subject)
+
+	ctrl.RecordCall(s, "LogMethod", "%d %d", []interface{}{1, 2})
+	ctrl.RecordCall(s, "LogMethod", "%v", gomock.Len(3))
+	ctrl.RecordCall(s, "VariadicMethod", 0, gomock.Contains("b"))
+	rep.assertFatal(func() {
+		ctrl.Call(s, "LogMethod", "%d %d", 1, 3)
+	}, "doesn't match the argument at index 1", "Got: [1 3]\nWant: is equal to [1 2]")
+
+	ctrl.Call(s, "LogMethod", "%d %d", 1, 2)
+	ctrl.Call(s, "LogMethod", "%v", 1, "two", 3.0)
+	ctrl.Call(s, "VariadicMethod", 0, "a", "b", "c")
+	ctrl.Finish()
+}
+
+func TestVariadicZeroArgs(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	s := new(Subject)
+
+	ctrl.RecordCall(s, "LogMethod", "none")
+	ctrl.RecordCall(s, "LogMethod", "empty slice", []interface{}{})
+	ctrl.RecordCall(s, "LogMethod", "any", gomock.Any())
+	rep.assertFatal(func() {
+		ctrl.Call(s, "LogMethod", "none", 1)
+	}, "has the wrong number of arguments. Got: 2, want: 1")
+
+	ctrl.Call(s, "LogMethod", "none")
+	ctrl.Call(s, "LogMethod", "empty slice")
+	ctrl.Call(s, "LogMethod", "any")
+	ctrl.Finish()
+}
+
+func TestVariadicRestAny(t *testing.T) {
+	testCases := [][]interface{}{
+		{},
+		{"1"},
+		{"1", "2", "3", "4"},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d trailing arguments", len(tc)), func(t *testing.T) {
+			rep, ctrl := createFixtures(t)
+			defer rep.recoverUnexpectedFatal()
+
+			s := new(Subject)
+			ctrl.RecordCall(s, "VariadicMethod", 1, gomock.RestAny()).Times(2)
+			ctrl.Call(s, "VariadicMethod", append([]interface{}{1}, tc...)...)
+			rep.assertFatal(func() {
+				ctrl.Call(s, "VariadicMethod", append([]interface{}{2}, tc...)...)
+			}, "doesn't match the argument at index 0")
+			ctrl.Call(s, "VariadicMethod", append([]interface{}{1}, tc...)...)
+			ctrl.Finish()
+		})
+	}
+}
+
+func TestVariadicRestAnyAfterVariadicArgs(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+
+	s := new(Subject)
+	ctrl.RecordCall(s, "VariadicMethod", 1, "a", gomock.RestAny()).Times(2)
+	ctrl.Call(s, "VariadicMethod", 1, "a")
+	ctrl.Call(s, "VariadicMethod", 1, "a", "b", "c")
+	rep.assertFatal(func() {
+		ctrl.Call(s, "VariadicMethod", 1)
+	}, "has the wrong number of arguments. Got: 1, want: greater than or equal to 2")
+	rep.assertFatal(func() {
+		ctrl.Call(s, "VariadicMethod", 1, "b", "a")
+	}, "doesn't match the argument at index 1")
+	ctrl.Finish()
+}
+
+func TestRestAnyOutsideVariadicPosition(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+
+	s := new(Subject)
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "FooMethod", gomock.RestAny())
+	}, "gomock.RestAny() can only be the last argument of a variadic method")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "VariadicMethod", gomock.RestAny())
+	}, "invalid matcher for argument 0")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "VariadicMethod", 1, gomock.RestAny(), "a")
+	}, "invalid matcher for argument 1")
+	ctrl.Finish()
+}
+
+func TestDuplicateFinishCall(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	s := new(Subject)
+
+	ctrl.RecordCall(s, "FooMethod", "argument")
+	rep.assertFatal(ctrl.Finish, "aborting test due to missing call(s)")
+	n := len(rep.log)
+
+	// The missing call isn't reported again.
+	ctrl.Finish()
+	if len(rep.log) != n {
+		t.Errorf("the second Finish call reported %q", rep.log[n:])
+	}
+}
+
+func TestFinishAfterNewExpectations(t *testing.T) {
+	rep := NewErrorReporter(t)
+	ctrl := gomock.NewController(rep, gomock.AllowUseAfterFinish())
+	s := new(Subject)
+
+	ctrl.Finish()
+	rep.assertPass("the first Finish call should succeed")
+
+	ctrl.RecordCall(s, "FooMethod", "argument")
+	ctrl.RecordCall(s, "BarMethod", "argument")
+	rep.assertFatal(ctrl.Finish, "2 call(s) were recorded after Controller.Finish was called")
+}
+
+func TestRecordCallAfterFinish(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	s := new(Subject)
+
+	ctrl.Finish()
+	var call *gomock.Call
+	rep.assertFatal(func() {
+		call = ctrl.RecordCall(s, "FooMethod", "argument")
+	}, "gomock: controller already finished, can't record *gomock_test.Subject.FooMethod(is equal to argument) at ",
+		"controller_test.go:")
+	if call != nil {
+		t.Errorf("got call %v, want none", call)
+	}
+}
+
+func TestCallAfterFinish(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	s := new(Subject)
+
+	ctrl.RecordCall(s, "FooMethod", "argument").AnyTimes()
+	ctrl.Finish()
+	rep.assertFatal(func() {
+		ctrl.Call(s, "FooMethod", "argument")
+	}, "gomock: controller already finished, unexpected call to *gomock_test.Subject.FooMethod([argument]) at ")
+}
+
+func TestAllowUseAfterFinish(t *testing.T) {
+	rep := NewErrorReporter(t)
+	ctrl := gomock.NewController(rep, gomock.AllowUseAfterFinish())
+	s := new(Subject)
+
+	ctrl.RecordCall(s, "FooMethod", "a").AnyTimes()
+	ctrl.Finish()
+	ctrl.Call(s, "FooMethod", "a")
+	ctrl.RecordCall(s, "BarMethod", "b")
+	rep.assertPass("using the controller after Finish")
+}
+
+func TestScopes(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	s := new(Subject)
+
+	ctrl.RecordCall(s, "BarMethod", "parent")
+
+	t.Run("failing", func(t *testing.T) {
+		rep := NewErrorReporter(t)
+		scope := ctrl.Scope(rep)
+		ctrl.RecordCall(s, "FooMethod", "a")
+		scope.RecordCall(s, "FooMethod", "b")
+		ctrl.Call(s, "FooMethod", "a")
+		rep.assertFatal(scope.Finish, "aborting test due to missing call(s):\n"+
+			"*gomock_test.Subject.FooMethod:\n\t(is equal to b)")
+	})
+	t.Run("passing", func(t *testing.T) {
+		rep := NewErrorReporter(t)
+		scope := ctrl.Scope(rep)
+		// The call left by the failing scope doesn't match this one.
+		ctrl.RecordCall(s, "FooMethod", "b")
+		ctrl.Call(s, "FooMethod", "b")
+
+		t.Run("nested", func(t *testing.T) {
+			rep := NewErrorReporter(t)
+			scope := ctrl.Scope(rep)
+			rep.assertFatal(func() {
+				ctrl.Call(s, "FooMethod", "c")
+			}, "Unexpected call to *gomock_test.Subject.FooMethod([c])")
+			scope.Finish()
+		})
+
+		scope.Finish()
+		rep.assertPass("the calls of the scope were made")
+	})
+	t.Run("cleanup", func(t *testing.T) {
+		// Finish is registered with t.Cleanup.
+		ctrl.Scope(t)
+		ctrl.RecordCall(s, "FooMethod", "d")
+		ctrl.Call(s, "FooMethod", "d")
+	})
+
+	if len(rep.log) != 0 {
+		t.Errorf("the failures of the scopes were reported to the parent: %q", rep.log)
+	}
+	ctrl.Call(s, "BarMethod", "parent")
+	ctrl.Finish()
+	rep.assertPass("the parent's calls were made")
+}
 
-	assertEqual(
-		t,
-		[]interface{}{0},
-		ctrl.Call(subject, "FooMethod", "zero"))
+func TestScopeTakesCallsFromAnyGoroutine(t *testing.T) {
+	_, ctrl := createFixtures(t)
+	s := new(Subject)
 
-	assertEqual(
-		t,
-		[]interface{}{5},
-		ctrl.Call(subject, "FooMethod", "five"))
+	rep := NewErrorReporter(t)
+	scope := ctrl.Scope(rep)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// The goroutine wasn't started with Go, but the scope is the
+		// latest one.
+		ctrl.RecordCall(s, "FooMethod", "a")
+	}()
+	<-done
+	rep.assertFatal(scope.Finish, "aborting test due to missing call(s):\n"+
+		"*gomock_test.Subject.FooMethod:\n\t(is equal to a)")
 	ctrl.Finish()
 }
 
-func TestUnorderedCalls(t *testing.T) {
-	reporter, ctrl := createFixtures(t)
-	defer reporter.recoverUnexpectedFatal()
-	subjectTwo := new(Subject)
-	subjectOne := new(Subject)
+func TestScopeFinishChecksOnlyItsCalls(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	s := new(Subject)
 
-	ctrl.RecordCall(subjectOne, "FooMethod", "1")
-	ctrl.RecordCall(subjectOne, "BarMethod", "2")
-	ctrl.RecordCall(subjectTwo, "FooMethod", "3")
-	ctrl.RecordCall(subjectTwo, "BarMethod", "4")
+	ctrl.RecordCall(s, "BarMethod", "parent")
+	scopeRep := NewErrorReporter(t)
+	scope := ctrl.Scope(scopeRep)
+	call := scope.RecordCall(s, "FooMethod", "scope")
+	if got := scope.PendingCalls(); len(got) != 1 || got[0] != call {
+		t.Errorf("got pending calls of the scope %v, want only its own", got)
+	}
+	scopeRep.assertFatal(scope.Finish, "aborting test due to missing call(s)")
 
-	// Make the calls in a different order, which should be fine.
-	ctrl.Call(subjectOne, "BarMethod", "2")
-	ctrl.Call(subjectTwo, "FooMethod", "3")
-	ctrl.Call(subjectTwo, "BarMethod", "4")
-	ctrl.Call(subjectOne, "FooMethod", "1")
+	rep.assertFatal(ctrl.Finish, "aborting test due to missing call(s):\n*gomock_test.Subject.BarMethod:")
+	if msg := rep.log[len(rep.log)-1]; strings.Contains(msg, "FooMethod") {
+		t.Errorf("the parent reported the calls of the finished scope: %q", msg)
+	}
+}
 
-	reporter.assertPass("After making all calls in different order")
+func TestStats(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	s := new(Subject)
 
-	ctrl.Finish()
+	ctrl.RecordCall(s, "FooMethod", "a").Times(2)
+	ctrl.RecordCall(s, "BarMethod", "b")
+	ctrl.RecordCall(s, "BarMethod", "c")
+	ctrl.Call(s, "FooMethod", "a")
+	ctrl.Call(s, "FooMethod", "a")
+	ctrl.Call(s, "BarMethod", "b")
+	rep.assertFatal(func() {
+		ctrl.Call(s, "FooMethod", "a")
+	})
+	rep.assertFatal(ctrl.Finish)
 
-	reporter.assertPass("After finish")
+	want := gomock.ControllerStats{
+		Recorded: 3,
+		Matched:  3,
+		Failures: 2,
+		Methods: map[string]gomock.MethodStats{
+			"*gomock_test.Subject.FooMethod": {Recorded: 1, Matched: 2, Failures: 1},
+			"*gomock_test.Subject.BarMethod": {Recorded: 2, Matched: 1, Failures: 1},
+		},
+	}
+	if got := ctrl.Stats(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got stats %+v, want %+v", got, want)
+	}
 }
 
-func commonTestOrderedCalls(t *testing.T) (reporter *ErrorReporter, ctrl *gomock.Controller, subjectOne, subjectTwo *Subject) {
-	reporter, ctrl = createFixtures(t)
-
-	subjectOne = new(Subject)
-	subjectTwo = new(Subject)
+func TestWithCallObserver(t *testing.T) {
+	rep := NewErrorReporter(t)
+	var infos []gomock.CallInfo
+	ctrl := gomock.NewController(rep, gomock.Relaxed(), gomock.WithCallObserver(func(info gomock.CallInfo) {
+		infos = append(infos, info)
+	}))
+	s := new(Subject)
 
-	gomock.InOrder(
-		ctrl.RecordCall(subjectOne, "FooMethod", "1").AnyTimes(),
-		ctrl.RecordCall(subjectTwo, "FooMethod", "2"),
-		ctrl.RecordCall(subjectTwo, "BarMethod", "3"),
-	)
+	ctrl.RecordCall(s, "FooMethod", "a").Do(func(string) {
+		time.Sleep(10 * time.Millisecond)
+	})
+	ctrl.RecordCall(s, "BarMethod", "b")
+	ctrl.Call(s, "FooMethod", "a")
+	// An unexpected call isn't observed.
+	ctrl.Call(s, "FooMethod", "unexpected")
+	ctrl.Call(s, "BarMethod", "b")
+	ctrl.Finish()
+	rep.assertPass("all calls made")
 
-	return
+	if len(infos) != 2 {
+		t.Fatalf("got %d calls observed, want 2: %+v", len(infos), infos)
+	}
+	for i, method := range []string{"FooMethod", "BarMethod"} {
+		if info := infos[i]; info.Receiver != "*gomock_test.Subject" || info.Method != method || info.Seq != uint64(i+1) {
+			t.Errorf("call %d: got %+v, want the call to %s", i, info, method)
+		}
+	}
+	if d := infos[0].Duration; d < 10*time.Millisecond {
+		t.Errorf("got duration %v, want the duration of the action", d)
+	}
 }
 
-func TestOrderedCallsCorrect(t *testing.T) {
-	reporter, ctrl, subjectOne, subjectTwo := commonTestOrderedCalls(t)
+func TestReceiverOfDifferentController(t *testing.T) {
+	rep, ctrlA := createFixtures(t)
+	repB, ctrlB := createFixtures(t)
+	// The mock was created with ctrlA, but its expected calls are recorded
+	// with ctrlB.
+	mock := &Subject{}
 
-	ctrl.Call(subjectOne, "FooMethod", "1")
-	ctrl.Call(subjectTwo, "FooMethod", "2")
-	ctrl.Call(subjectTwo, "BarMethod", "3")
+	ctrlB.RecordCall(mock, "FooMethod", "a")
+	rep.assertFatal(func() {
+		ctrlA.Call(mock, "FooMethod", "a")
+	}, "Unexpected call to *gomock_test.Subject.FooMethod([a])",
+		"because: receiver *gomock_test.Subject was created with a different gomock.Controller")
 
-	ctrl.Finish()
+	ctrlB.Call(mock, "FooMethod", "a")
+	ctrlB.Finish()
+	repB.assertPass("the call was made with the right controller")
 
-	reporter.assertPass("After finish")
+	// Once ctrlB finished, the receiver is no longer known.
+	ctrlA = gomock.NewController(rep)
+	rep.assertFatal(func() {
+		ctrlA.Call(mock, "FooMethod", "a")
+	}, "there are no expected calls of the method \"FooMethod\" for that receiver")
 }
 
-func TestOrderedCallsInCorrect(t *testing.T) {
-	reporter, ctrl, subjectOne, subjectTwo := commonTestOrderedCalls(t)
+// returningReporter is a TestReporter whose Fatalf returns.
+type returningReporter struct {
+	mu    sync.Mutex
+	fatal []string
+}
 
-	ctrl.Call(subjectOne, "FooMethod", "1")
-	reporter.assertFatal(func() {
-		// FooMethod(2) should be called before BarMethod(3)
-		ctrl.Call(subjectTwo, "BarMethod", "3")
-	}, "Unexpected call to", "Subject.BarMethod([3])", "doesn't have a prerequisite call satisfied")
+func (r *returningReporter) Errorf(format string, args ...interface{}) {}
+func (r *returningReporter) Fatalf(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fatal = append(r.fatal, fmt.Sprintf(format, args...))
 }
 
-// Test that calls that are prerequisites to other calls but have maxCalls >
-// minCalls are removed from the expected call set.
-func TestOrderedCallsWithPreReqMaxUnbounded(t *testing.T) {
-	reporter, ctrl, subjectOne, subjectTwo := commonTestOrderedCalls(t)
+// runUntilExit runs f in a new goroutine, and reports whether it returned
+// rather than ending the goroutine.
+func runUntilExit(f func()) (returned bool) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+		returned = true
+	}()
+	<-done
+	return returned
+}
 
-	// Initially we should be able to call FooMethod("1") as many times as we
-	// want.
-	ctrl.Call(subjectOne, "FooMethod", "1")
-	ctrl.Call(subjectOne, "FooMethod", "1")
+func TestFatalfReturning(t *testing.T) {
+	rep := &returningReporter{}
+	s := new(Subject)
 
-	// But calling something that has it as a prerequite should remove it from
-	// the expected call set. This allows tests to ensure that FooMethod("1") is
-	// *not* called after FooMethod("2").
-	ctrl.Call(subjectTwo, "FooMethod", "2")
+	tests := []struct {
+		name string
+		f    func(ctrl *gomock.Controller)
+	}{
+		{"unexpected call", func(ctrl *gomock.Controller) { ctrl.Call(s, "FooMethod", "unexpected") }},
+		{"unknown method", func(ctrl *gomock.Controller) { ctrl.RecordCall(s, "NotAMethod") }},
+		{"bad return", func(ctrl *gomock.Controller) { ctrl.RecordCall(s, "FooMethod", "a").Return("not an int") }},
+		{"missing call", func(ctrl *gomock.Controller) {
+			ctrl.RecordCall(s, "BarMethod", "b")
+			ctrl.Finish()
+		}},
+		{"call after finish", func(ctrl *gomock.Controller) {
+			ctrl.Finish()
+			ctrl.Call(s, "BarMethod", "b")
+		}},
+	}
+	for _, tt := range tests {
+		n := len(rep.fatal)
+		var ctrl *gomock.Controller
+		// Unexpected calls are fatal from the goroutine of the controller.
+		if runUntilExit(func() {
+			ctrl = gomock.NewController(rep)
+			tt.f(ctrl)
+		}) {
+			t.Errorf("%s: the code after the fatal failure ran", tt.name)
+		}
+		if len(rep.fatal) != n+1 {
+			t.Errorf("%s: got %d fatal failure(s), want 1", tt.name, len(rep.fatal)-n)
+		}
+		// The controller isn't left locked.
+		ctrl.ExpectedCalls()
+	}
+}
 
-	// Therefore this call should fail:
-	reporter.assertFatal(func() {
-		ctrl.Call(subjectOne, "FooMethod", "1")
-	})
+// Fooer is implemented by Subject and Other.
+type Fooer interface {
+	FooMethod(arg string) int
 }
 
-func TestCallAfterLoopPanic(t *testing.T) {
-	_, ctrl := createFixtures(t)
+var fooerType = reflect.TypeOf((*Fooer)(nil)).Elem()
 
-	subject := new(Subject)
+func TestRecordCallOnType(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	subject, other := new(Subject), new(Other)
 
-	firstCall := ctrl.RecordCall(subject, "FooMethod", "1")
-	secondCall := ctrl.RecordCall(subject, "FooMethod", "2")
-	thirdCall := ctrl.RecordCall(subject, "FooMethod", "3")
+	ctrl.RecordCallOnType(fooerType, "FooMethod", "a").Return(1).Times(2)
 
-	gomock.InOrder(firstCall, secondCall, thirdCall)
+	if got := ctrl.Call(subject, "FooMethod", "a"); !reflect.DeepEqual(got, []interface{}{1}) {
+		t.Errorf("got %v from the Subject, want 1", got)
+	}
+	if got := ctrl.Call(other, "FooMethod", "a"); !reflect.DeepEqual(got, []interface{}{1}) {
+		t.Errorf("got %v from the Other, want 1", got)
+	}
+	rep.assertFatal(func() {
+		ctrl.Call(other, "FooMethod", "a")
+	}, "expected call to gomock_test.Fooer.FooMethod has already been made the max allowed number of times (2)")
+	ctrl.Finish()
+}
 
-	defer func() {
-		err := recover()
-		if err == nil {
-			t.Error("Call.After creation of dependency loop did not panic.")
-		}
-	}()
+func TestRecordCallOnTypePriority(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	subject, other := new(Subject), new(Other)
 
-	// This should panic due to dependency loop.
-	firstCall.After(thirdCall)
+	ctrl.RecordCallOnType(fooerType, "FooMethod", gomock.Any()).Return(2).AnyTimes()
+	ctrl.RecordCall(subject, "FooMethod", gomock.Any()).Return(1)
+
+	// The call recorded for the receiver comes first, regardless of the
+	// order of recording.
+	if got := ctrl.Call(subject, "FooMethod", "a"); !reflect.DeepEqual(got, []interface{}{1}) {
+		t.Errorf("got %v, want the call recorded for the receiver", got)
+	}
+	if got := ctrl.Call(subject, "FooMethod", "b"); !reflect.DeepEqual(got, []interface{}{2}) {
+		t.Errorf("got %v, want the call recorded for the type", got)
+	}
+	if got := ctrl.Call(other, "FooMethod", "c"); !reflect.DeepEqual(got, []interface{}{2}) {
+		t.Errorf("got %v, want the call recorded for the type", got)
+	}
+	ctrl.Finish()
+	rep.assertPass("all calls made")
 }
 
-func TestPanicOverridesExpectationChecks(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	reporter := NewErrorReporter(t)
+func TestRecordCallOnTypeErrors(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
 
-	reporter.assertFatal(func() {
-		ctrl.RecordCall(new(Subject), "FooMethod", "1")
-		defer ctrl.Finish()
-		reporter.Fatalf("Intentional panic")
-	})
+	rep.assertFatal(func() {
+		ctrl.RecordCallOnType(reflect.TypeOf(Subject{}), "FooMethod", "a")
+	}, "gomock: RecordCallOnType needs an interface type, got gomock_test.Subject")
+	rep.assertFatal(func() {
+		ctrl.RecordCallOnType(fooerType, "BarMethod", "a")
+	}, "gomock: failed finding method BarMethod on gomock_test.Fooer")
+
+	ctrl.RecordCallOnType(fooerType, "FooMethod", "a")
+	rep.assertFatal(func() {
+		ctrl.Call(new(Subject), "FooMethod", "b")
+	}, "Unexpected call to *gomock_test.Subject.FooMethod([b])", "Want: is equal to a")
+	rep.assertFatal(ctrl.Finish, "aborting test due to missing call(s):\ngomock_test.Fooer.FooMethod:")
 }
 
-func TestSetArgWithBadType(t *testing.T) {
-	rep, ctrl := createFixtures(t)
-	defer ctrl.Finish()
+// cleanupReporter is an ErrorReporter with a Cleanup method, like a
+// *testing.T.
+type cleanupReporter struct {
+	*ErrorReporter
+	cleanups []func()
+}
+
+func (r *cleanupReporter) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
 
+func TestWithContextCancelledOnFatalFailure(t *testing.T) {
+	rep := NewErrorReporter(t)
+	ctrl, ctx := gomock.WithContext(context.Background(), rep)
 	s := new(Subject)
-	// This should catch a type error:
+
+	unblocked := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		close(unblocked)
+	}()
 	rep.assertFatal(func() {
-		ctrl.RecordCall(s, "FooMethod", "1").SetArg(0, "blah")
-	})
-	ctrl.Call(s, "FooMethod", "1")
+		ctrl.Call(s, "FooMethod", "unexpected")
+	}, "Unexpected call to *gomock_test.Subject.FooMethod([unexpected])")
+	select {
+	case <-unblocked:
+	case <-time.After(time.Second):
+		t.Fatal("the context wasn't cancelled by the failure")
+	}
+	ctrl.Finish()
 }
 
-func TestTimes0(t *testing.T) {
-	rep, ctrl := createFixtures(t)
-	defer ctrl.Finish()
+func TestWithContextCancelledOnFailureInGoroutine(t *testing.T) {
+	rep := NewErrorReporter(t)
+	ctrl, ctx := gomock.WithContext(context.Background(), rep)
+	s := new(Subject)
+
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("the context is done before any failure: %v", err)
+	}
+	go ctrl.Call(s, "FooMethod", "unexpected")
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the context wasn't cancelled by the failure")
+	}
+	rep.assertFatal(ctrl.Finish, "aborting test due to 1 unexpected call(s) from other goroutines")
+}
 
+func TestWithContextCancelledOnFatalFailureInGoroutine(t *testing.T) {
+	rep := newAsyncReporter()
+	ctrl, ctx := gomock.WithContext(context.Background(), rep)
 	s := new(Subject)
-	ctrl.RecordCall(s, "FooMethod", "arg").Times(0)
-	rep.assertFatal(func() {
-		ctrl.Call(s, "FooMethod", "arg")
-	})
+
+	// A setup failure is fatal on any goroutine: the context is cancelled
+	// before the goroutine ends with runtime.Goexit.
+	exited := make(chan bool)
+	go func() {
+		reached := false
+		defer func() { exited <- reached }()
+		ctrl.RecordCall(s, "FooMethod")
+		reached = true
+	}()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("the context wasn't cancelled by the failure")
+	}
+	if <-exited {
+		t.Error("the goroutine went on after the fatal failure")
+	}
+	if msg, want := <-rep.failures, "invalid arguments for *gomock_test.Subject.FooMethod"; !strings.Contains(msg, want) {
+		t.Errorf("failure %q doesn't contain %q", msg, want)
+	}
 }
 
-func TestVariadicMatching(t *testing.T) {
-	rep, ctrl := createFixtures(t)
+func TestWithContextCancelledByFinish(t *testing.T) {
+	rep := NewErrorReporter(t)
 	defer rep.recoverUnexpectedFatal()
-
+	ctrl, ctx := gomock.WithContext(context.Background(), rep)
 	s := new(Subject)
-	ctrl.RecordCall(s, "VariadicMethod", 0, "1", "2")
-	ctrl.Call(s, "VariadicMethod", 0, "1", "2")
+
+	ctrl.RecordCall(s, "FooMethod", "argument")
+	ctrl.Call(s, "FooMethod", "argument")
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("the context is done before Finish: %v", err)
+	}
 	ctrl.Finish()
-	rep.assertPass("variadic matching works")
+	rep.assertPass("the expected call was made")
+	if err := ctx.Err(); err != context.Canceled {
+		t.Errorf("the context error after Finish is %v, want %v", err, context.Canceled)
+	}
+
+	// Finish cancels the context when it fails the test too.
+	rep = NewErrorReporter(t)
+	ctrl, ctx = gomock.WithContext(context.Background(), rep)
+	ctrl.RecordCall(s, "FooMethod", "argument")
+	rep.assertFatal(ctrl.Finish, "aborting test due to missing call(s)")
+	if err := ctx.Err(); err != context.Canceled {
+		t.Errorf("the context error after a failed Finish is %v, want %v", err, context.Canceled)
+	}
 }
 
-func TestVariadicNoMatch(t *testing.T) {
-	rep, ctrl := createFixtures(t)
+func TestFinishRegisteredWithCleanup(t *testing.T) {
+	rep := &cleanupReporter{ErrorReporter: NewErrorReporter(t)}
 	defer rep.recoverUnexpectedFatal()
-
+	ctrl := gomock.NewController(rep)
 	s := new(Subject)
-	ctrl.RecordCall(s, "VariadicMethod", 0)
-	rep.assertFatal(func() {
-		ctrl.Call(s, "VariadicMethod", 1)
-	}, "Expected call at", "doesn't match the argument at index 0",
-		"Got: 1\nWant: is equal to 0")
-	ctrl.Call(s, "VariadicMethod", 0)
-	ctrl.Finish()
+
+	if len(rep.cleanups) != 1 {
+		t.Fatalf("NewController registered %d cleanup function(s), want 1", len(rep.cleanups))
+	}
+	ctrl.RecordCall(s, "FooMethod", "argument")
+	rep.assertFatal(rep.cleanups[0], "aborting test due to missing call(s)")
+
+	// A reporter without Cleanup requires calling Finish.
+	rep2, ctrl2 := createFixtures(t)
+	ctrl2.RecordCall(s, "FooMethod", "argument")
+	rep2.assertFatal(ctrl2.Finish, "missing call(s)")
+
+	ctx := &cleanupReporter{ErrorReporter: NewErrorReporter(t)}
+	gomock.WithContext(context.Background(), ctx)
+	if len(ctx.cleanups) != 1 {
+		t.Errorf("WithContext registered %d cleanup function(s), want 1", len(ctx.cleanups))
+	}
 }
 
-func TestVariadicMatchingWithSlice(t *testing.T) {
-	testCases := [][]string{
-		{"1"},
-		{"1", "2"},
+func TestUnexpectedArgValue_Diff(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	defer reporter.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	expectedArg0 := TestStruct{Number: 123, Message: "hello"}
+	ctrl.RecordCall(subject, "ActOnTestStructMethod", expectedArg0, 15)
+
+	reporter.assertFatal(func() {
+		ctrl.Call(subject, "ActOnTestStructMethod", TestStruct{Number: 123, Message: "no message"}, 15)
+	}, "Diff:\n", `.Message: got "no message", want "hello"`)
+	if msg := reporter.log[len(reporter.log)-1]; strings.Contains(msg, ".Number") {
+		t.Errorf("diff mentions a field that matches: %q", msg)
 	}
-	for _, tc := range testCases {
-		t.Run(fmt.Sprintf("%d arguments", len(tc)), func(t *testing.T) {
-			rep, ctrl := createFixtures(t)
-			defer rep.recoverUnexpectedFatal()
 
-			s := new(Subject)
-			ctrl.RecordCall(s, "VariadicMethod", 1, tc)
-			args := make([]interface{}, len(tc)+1)
-			args[0] = 1
-			for i, arg := range tc {
-				args[i+1] = arg
-			}
-			ctrl.Call(s, "VariadicMethod", args...)
-			ctrl.Finish()
-			rep.assertPass("slices can be used as matchers for variadic arguments")
-		})
+	reporter.assertFatal(func() {
+		// The expected call wasn't made.
+		ctrl.Finish()
+	})
+}
+
+func TestMatchedCallDoesNotAllocate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	subject := new(Subject)
+	ctrl.RecordCall(subject, "FooMethod", "a").Return(1).AnyTimes()
+	ctrl.Prepare()
+
+	// The arguments are boxed beforehand, as a mock does before calling.
+	args := []interface{}{"a"}
+	if n := testing.AllocsPerRun(100, func() { ctrl.Call(subject, "FooMethod", args...) }); n != 0 {
+		t.Errorf("a matched call allocates %v times", n)
 	}
 }
 
-func TestDuplicateFinishCallFails(t *testing.T) {
-	rep, ctrl := createFixtures(t)
+func BenchmarkCallMatched(b *testing.B) {
+	ctrl := gomock.NewController(b)
+	subject := new(Subject)
+	ctrl.RecordCall(subject, "FooMethod", strings.Repeat("a", 1<<10)).AnyTimes()
+	arg := strings.Repeat("a", 1<<10)
 
-	ctrl.Finish()
-	rep.assertPass("the first Finish call should succeed")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctrl.Call(subject, "FooMethod", arg)
+	}
+}
+
+// BenchmarkCallMatchedAmongMismatches shows that the expected calls which
+// reject a call don't format it: each of them only allocates a few bytes,
+// however large the argument.
+func BenchmarkCallMatchedAmongMismatches(b *testing.B) {
+	ctrl := gomock.NewController(b)
+	subject := new(Subject)
+	for i := 0; i < 10; i++ {
+		ctrl.RecordCall(subject, "FooMethod", fmt.Sprint(i)+strings.Repeat("a", 1<<10)).AnyTimes()
+	}
+	arg := "9" + strings.Repeat("a", 1<<10)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctrl.Call(subject, "FooMethod", arg)
+	}
+}
+
+func BenchmarkControllerCall(b *testing.B) {
+	ctrl := gomock.NewController(b)
+	subject := new(Subject)
+	ctrl.RecordCall(subject, "FooMethod", "a").Return(1).AnyTimes()
+	ctrl.RecordCall(subject, "BarMethod", "b").AnyTimes()
 
-	rep.assertFatal(ctrl.Finish, "Controller.Finish was called more than once. It has to be called exactly once.")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctrl.Call(subject, "FooMethod", "a")
+		ctrl.Call(subject, "BarMethod", "b")
+	}
 }
@@ -0,0 +1,191 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomock
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxDiffDepth is how deep diffValues descends into nested values.
+	// Differences below it are reported as a difference of the whole value.
+	maxDiffDepth = 8
+	// maxDiffs is the number of differences diffValues reports before
+	// giving up.
+	maxDiffs = 10
+)
+
+// A differ is a Matcher that can explain why it did not match a value.
+type differ interface {
+	// diff returns a description of how got differs from the expectation,
+	// or the empty string if there is nothing to add to the default output.
+	diff(got interface{}) string
+}
+
+// diffValues returns a line per difference between want and got, naming the
+// path to each differing field, element or map entry.
+func diffValues(want, got interface{}) string {
+	d := &valueDiff{}
+	d.diff("", reflect.ValueOf(want), reflect.ValueOf(got), 0)
+	if d.truncated {
+		d.lines = append(d.lines, "  ...")
+	}
+	return strings.Join(d.lines, "\n")
+}
+
+type valueDiff struct {
+	lines     []string
+	truncated bool
+}
+
+func (d *valueDiff) report(path, format string, args ...interface{}) {
+	if len(d.lines) >= maxDiffs {
+		d.truncated = true
+		return
+	}
+	if path == "" {
+		path = "value"
+	}
+	d.lines = append(d.lines, fmt.Sprintf("  %s: ", path)+fmt.Sprintf(format, args...))
+}
+
+func (d *valueDiff) diff(path string, want, got reflect.Value, depth int) {
+	if d.truncated {
+		return
+	}
+	if !want.IsValid() || !got.IsValid() {
+		if want.IsValid() != got.IsValid() {
+			d.report(path, "got %s, want %s", formatDiffValue(got), formatDiffValue(want))
+		}
+		return
+	}
+	if want.Type() != got.Type() {
+		d.report(path, "got type %v, want type %v", got.Type(), want.Type())
+		return
+	}
+	if depth > maxDiffDepth {
+		if !valuesEqual(want, got) {
+			d.report(path, "got %s, want %s", formatDiffValue(got), formatDiffValue(want))
+		}
+		return
+	}
+
+	switch want.Kind() {
+	case reflect.Struct:
+		for i := 0; i < want.NumField(); i++ {
+			d.diff(path+"."+want.Type().Field(i).Name, want.Field(i), got.Field(i), depth+1)
+		}
+	case reflect.Ptr, reflect.Interface:
+		if want.IsNil() || got.IsNil() {
+			if want.IsNil() != got.IsNil() {
+				d.report(path, "got %s, want %s", formatDiffValue(got), formatDiffValue(want))
+			}
+			return
+		}
+		if want.Kind() == reflect.Ptr && want.Pointer() == got.Pointer() {
+			return
+		}
+		d.diff(path, want.Elem(), got.Elem(), depth+1)
+	case reflect.Slice, reflect.Array:
+		if want.Kind() == reflect.Slice && want.IsNil() != got.IsNil() {
+			d.report(path, "got %s, want %s", formatDiffValue(got), formatDiffValue(want))
+			return
+		}
+		if want.Len() != got.Len() {
+			d.report(path, "got length %d, want length %d", got.Len(), want.Len())
+			return
+		}
+		for i := 0; i < want.Len(); i++ {
+			d.diff(fmt.Sprintf("%s[%d]", path, i), want.Index(i), got.Index(i), depth+1)
+		}
+	case reflect.Map:
+		if want.IsNil() != got.IsNil() {
+			d.report(path, "got %s, want %s", formatDiffValue(got), formatDiffValue(want))
+			return
+		}
+		for _, k := range sortedMapKeys(want) {
+			kpath := fmt.Sprintf("%s[%s]", path, formatDiffValue(k))
+			gv := got.MapIndex(k)
+			if !gv.IsValid() {
+				d.report(kpath, "missing, want %s", formatDiffValue(want.MapIndex(k)))
+				continue
+			}
+			d.diff(kpath, want.MapIndex(k), gv, depth+1)
+		}
+		for _, k := range sortedMapKeys(got) {
+			if !want.MapIndex(k).IsValid() {
+				d.report(fmt.Sprintf("%s[%s]", path, formatDiffValue(k)), "got %s, want no entry", formatDiffValue(got.MapIndex(k)))
+			}
+		}
+	default:
+		if !valuesEqual(want, got) {
+			d.report(path, "got %s, want %s", formatDiffValue(got), formatDiffValue(want))
+		}
+	}
+}
+
+// valuesEqual compares two values of the same type. It works for values
+// obtained from unexported struct fields, which can't be converted back to
+// an interface{} for reflect.DeepEqual.
+func valuesEqual(want, got reflect.Value) bool {
+	switch want.Kind() {
+	case reflect.Bool:
+		return want.Bool() == got.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return want.Int() == got.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return want.Uint() == got.Uint()
+	case reflect.Float32, reflect.Float64:
+		return want.Float() == got.Float()
+	case reflect.Complex64, reflect.Complex128:
+		return want.Complex() == got.Complex()
+	case reflect.String:
+		return want.String() == got.String()
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return want.Pointer() == got.Pointer()
+	}
+	if want.CanInterface() && got.CanInterface() {
+		return reflect.DeepEqual(want.Interface(), got.Interface())
+	}
+	return formatDiffValue(want) == formatDiffValue(got)
+}
+
+func formatDiffValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	if v.Kind() == reflect.String {
+		return fmt.Sprintf("%q", v.String())
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// sortedMapKeys returns the keys of the map m in a stable order.
+func sortedMapKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Sort(byFormattedValue(keys))
+	return keys
+}
+
+type byFormattedValue []reflect.Value
+
+func (vs byFormattedValue) Len() int      { return len(vs) }
+func (vs byFormattedValue) Swap(i, j int) { vs[i], vs[j] = vs[j], vs[i] }
+func (vs byFormattedValue) Less(i, j int) bool {
+	return formatDiffValue(vs[i]) < formatDiffValue(vs[j])
+}
@@ -0,0 +1,72 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomock
+
+import (
+	"strings"
+	"testing"
+)
+
+type diffInner struct {
+	ID   int
+	tags []string
+}
+
+type diffOuter struct {
+	Name  string
+	Inner *diffInner
+	Attrs map[string]int
+}
+
+func TestDiffValues(t *testing.T) {
+	want := diffOuter{Name: "a", Inner: &diffInner{ID: 1, tags: []string{"x"}}, Attrs: map[string]int{"k": 1}}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want []string
+	}{
+		{"equal", want, nil},
+		{"top-level field", diffOuter{Name: "b", Inner: &diffInner{ID: 1, tags: []string{"x"}}, Attrs: map[string]int{"k": 1}},
+			[]string{`  .Name: got "b", want "a"`}},
+		{"through pointer", diffOuter{Name: "a", Inner: &diffInner{ID: 2, tags: []string{"y"}}, Attrs: map[string]int{"k": 1}},
+			[]string{`  .Inner.ID: got 2, want 1`, `  .Inner.tags[0]: got "y", want "x"`}},
+		{"map entries", diffOuter{Name: "a", Inner: &diffInner{ID: 1, tags: []string{"x"}}, Attrs: map[string]int{"j": 1}},
+			[]string{`  .Attrs["k"]: missing, want 1`, `  .Attrs["j"]: got 1, want no entry`}},
+		{"nil pointer", diffOuter{Name: "a", Attrs: map[string]int{"k": 1}},
+			[]string{`  .Inner: got <nil>, want &{1 [x]}`}},
+		{"type", 5, []string{"  value: got type int, want type gomock.diffOuter"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := diffValues(want, test.got)
+			if got != strings.Join(test.want, "\n") {
+				t.Errorf("diffValues:\ngot:\n%s\nwant:\n%s", got, strings.Join(test.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestDiffValuesTruncated(t *testing.T) {
+	want := make([]int, 20)
+	got := make([]int, 20)
+	for i := range got {
+		got[i] = i + 1
+	}
+	lines := strings.Split(diffValues(want, got), "\n")
+	if len(lines) != maxDiffs+1 || lines[maxDiffs] != "  ..." {
+		t.Errorf("diffValues reported %d lines, want %d followed by an ellipsis: %q", len(lines), maxDiffs, lines)
+	}
+}
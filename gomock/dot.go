@@ -0,0 +1,69 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomock
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// PendingGraphDOT describes the calls which are still expected and not yet
+// satisfied, and the order they have to be made in, as a Graphviz DOT graph.
+// There is a node for each call, labelled with the call and its origin, and
+// an edge from each call to those declared to be made after it, e.g.
+//
+//	digraph gomock {
+//		c1 [label="*pkg.Mock.Open(is anything)\nfile.go:12"];
+//		c2 [label="*pkg.Mock.Close()\nfile.go:13"];
+//		c1 -> c2;
+//	}
+//
+// This is useful to find out why a test with an InOrder chain hangs.
+func (ctrl *Controller) PendingGraphDOT() string {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	var pending []*Call
+	nodes := make(map[*Call]bool)
+	for _, c := range ctrl.expectedCalls.Expected() {
+		if !c.satisfied() {
+			pending = append(pending, c)
+			nodes[c] = true
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("digraph gomock {\n")
+	for _, c := range pending {
+		fmt.Fprintf(&buf, "\tc%d [label=\"%s\\n%s\"];\n", c.id, dotEscape(c.signature()), dotEscape(c.origin))
+	}
+	for _, c := range pending {
+		for _, preReq := range c.preReqs {
+			if nodes[preReq] {
+				fmt.Fprintf(&buf, "\tc%d -> c%d;\n", preReq.id, c.id)
+			}
+		}
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// dotEscape escapes s for a quoted DOT string.
+func dotEscape(s string) string {
+	return dotEscaper.Replace(s)
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomock
+
+import (
+	"reflect"
+	"sync"
+)
+
+var equality = struct {
+	mu    sync.RWMutex
+	funcs map[reflect.Type]func(x, y interface{}) bool
+}{funcs: make(map[reflect.Type]func(x, y interface{}) bool)}
+
+// RegisterEqualityFor makes Eq use cmp instead of reflect.DeepEqual to
+// compare values whose dynamic type is the type of typ. If typ is a
+// reflect.Type, that type is used. cmp is only called when both values have
+// exactly that type; other comparisons are unaffected. Passing a nil cmp
+// removes the registration.
+//
+// Registrations are global, so they are usually made once, in TestMain or
+// an init function:
+//
+//	gomock.RegisterEqualityFor(&pb.Request{}, func(x, y interface{}) bool {
+//		return proto.Equal(x.(proto.Message), y.(proto.Message))
+//	})
+func RegisterEqualityFor(typ interface{}, cmp func(x, y interface{}) bool) {
+	t, ok := typ.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(typ)
+	}
+	if t == nil {
+		panic("gomock: RegisterEqualityFor requires a non-nil type")
+	}
+	equality.mu.Lock()
+	defer equality.mu.Unlock()
+	if cmp == nil {
+		delete(equality.funcs, t)
+		return
+	}
+	equality.funcs[t] = cmp
+}
+
+// registeredEquality returns the comparison function registered for the
+// dynamic type of x and y, if they have the same one.
+func registeredEquality(x, y interface{}) (func(x, y interface{}) bool, bool) {
+	t := reflect.TypeOf(x)
+	if t == nil || t != reflect.TypeOf(y) {
+		return nil, false
+	}
+	equality.mu.RLock()
+	defer equality.mu.RUnlock()
+	cmp, ok := equality.funcs[t]
+	return cmp, ok
+}
+
+type eqFuncMatcher struct {
+	x   interface{}
+	cmp func(x, y interface{}) bool
+}
+
+func (m eqFuncMatcher) Matches(x interface{}) bool {
+	return m.cmp(m.x, x)
+}
+
+func (m eqFuncMatcher) String() string {
+	return "is equal to " + FormatValue(m.x)
+}
+
+// EqFunc returns a Matcher that uses cmp to compare expected with the
+// actual argument, which is passed as cmp's second argument.
+//
+//	gomock.EqFunc(want, func(x, y interface{}) bool {
+//		return proto.Equal(x.(proto.Message), y.(proto.Message))
+//	})
+func EqFunc(expected interface{}, cmp func(x, y interface{}) bool) Matcher {
+	if cmp == nil {
+		panic("gomock: EqFunc requires a non-nil comparison func")
+	}
+	return eqFuncMatcher{expected, cmp}
+}
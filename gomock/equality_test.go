@@ -0,0 +1,95 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomock_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+// message mimics a generated proto message: sizeCache differs between
+// otherwise equal values, so reflect.DeepEqual can't compare them.
+type message struct {
+	Name      string
+	sizeCache int
+}
+
+func messageEqual(x, y interface{}) bool {
+	return x.(*message).Name == y.(*message).Name
+}
+
+func TestRegisterEqualityFor(t *testing.T) {
+	var calls int
+	gomock.RegisterEqualityFor(&message{}, func(x, y interface{}) bool {
+		calls++
+		return messageEqual(x, y)
+	})
+	defer gomock.RegisterEqualityFor(&message{}, nil)
+
+	m := gomock.Eq(&message{Name: "a", sizeCache: 1})
+	if !m.Matches(&message{Name: "a", sizeCache: 2}) {
+		t.Error("Eq should use the registered equality")
+	}
+	if m.Matches(&message{Name: "b", sizeCache: 1}) {
+		t.Error("Eq should not match when the registered equality fails")
+	}
+	if calls != 2 {
+		t.Errorf("registered equality called %d times, want 2", calls)
+	}
+
+	// Other dynamic types fall back to reflect.DeepEqual.
+	if m.Matches(message{Name: "a", sizeCache: 1}) || m.Matches(nil) || m.Matches("a") {
+		t.Error("Eq should not match values of other types")
+	}
+	if !gomock.Eq(message{Name: "a"}).Matches(message{Name: "a"}) {
+		t.Error("Eq should compare unregistered types with reflect.DeepEqual")
+	}
+	if gomock.Eq(message{Name: "a"}).Matches(message{Name: "a", sizeCache: 1}) {
+		t.Error("Eq should compare unregistered types with reflect.DeepEqual")
+	}
+	if calls != 2 {
+		t.Errorf("registered equality called %d times for other types, want 2", calls)
+	}
+
+	gomock.RegisterEqualityFor(reflect.TypeOf(&message{}), nil)
+	if m.Matches(&message{Name: "a", sizeCache: 2}) {
+		t.Error("Eq should use reflect.DeepEqual after the registration is removed")
+	}
+}
+
+func TestRegisterEqualityForPanicsOnNilType(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "non-nil type") {
+			t.Errorf("RegisterEqualityFor(nil, ...) panicked with %v", r)
+		}
+	}()
+	gomock.RegisterEqualityFor(nil, messageEqual)
+}
+
+func TestEqFunc(t *testing.T) {
+	m := gomock.EqFunc(&message{Name: "a", sizeCache: 1}, messageEqual)
+	if !m.Matches(&message{Name: "a", sizeCache: 2}) {
+		t.Error("EqFunc should use the given comparison")
+	}
+	if m.Matches(&message{Name: "b"}) {
+		t.Error("EqFunc should not match when the comparison fails")
+	}
+	if s := gomock.EqFunc(3, messageEqual).String(); s != "is equal to 3" {
+		t.Errorf(`String() == %q, want "is equal to 3"`, s)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomock
+
+import "reflect"
+
+// A FuncMock is the receiver of the calls of a function mocked with
+// MockFunction, which records its expected calls.
+type FuncMock struct {
+	ctrl *Controller
+	typ  reflect.Type // the type of the function
+}
+
+// MockFunction replaces the function fnPtr points to, e.g. a variable of a
+// func type a dependency is injected as, with one whose calls are checked
+// against the expected calls recorded with the returned FuncMock:
+//
+//	var fetch func(ctx context.Context, url string) ([]byte, error)
+//	m := gomock.MockFunction(ctrl, &fetch)
+//	m.EXPECT(gomock.Any(), "http://example.com").Return([]byte("ok"), nil)
+//	process(fetch)
+//
+// Finish puts the function fnPtr pointed to back, so that it isn't called
+// once the calls can't be checked any longer, and reports the expected
+// calls which weren't made as for any mock.
+func MockFunction(ctrl *Controller, fnPtr interface{}) *FuncMock {
+	ctrl.t.Helper()
+
+	p := reflect.ValueOf(fnPtr)
+	if p.Kind() != reflect.Ptr || p.IsNil() || p.Elem().Kind() != reflect.Func {
+		ctrl.t.Fatalf("gomock: MockFunction needs a pointer to a function, got %T", fnPtr)
+		return nil
+	}
+	fn := p.Elem()
+	m := &FuncMock{ctrl: ctrl, typ: fn.Type()}
+	orig := reflect.ValueOf(fn.Interface())
+	fn.Set(reflect.MakeFunc(m.typ, m.call))
+
+	ctrl.mu.Lock()
+	owner := ctrl
+	if ctrl.owner != nil {
+		owner = ctrl.owner
+	}
+	owner.restoreFuncs = append(owner.restoreFuncs, func() { fn.Set(orig) })
+	ctrl.mu.Unlock()
+	return m
+}
+
+// EXPECT records an expected call of the function, with args matching its
+// arguments as for a method of a mock.
+func (m *FuncMock) EXPECT(args ...interface{}) *Call {
+	m.ctrl.t.Helper()
+	return m.ctrl.RecordCallWithMethodType(m, "Call", m.typ, args...)
+}
+
+// call implements the mocked function.
+func (m *FuncMock) call(in []reflect.Value) []reflect.Value {
+	m.ctrl.t.Helper()
+
+	args := make([]interface{}, len(in))
+	for i, v := range in {
+		args[i] = v.Interface()
+	}
+	rets := m.ctrl.Call(m, "Call", args...)
+	out := make([]reflect.Value, m.typ.NumOut())
+	for i := range out {
+		out[i] = reflect.New(m.typ.Out(i)).Elem()
+		if i < len(rets) && rets[i] != nil {
+			out[i].Set(reflect.ValueOf(rets[i]))
+		}
+	}
+	return out
+}
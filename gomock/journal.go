@@ -0,0 +1,126 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomock
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A JournalEntryKind tells what a JournalEntry records.
+type JournalEntryKind int
+
+const (
+	// Recorded is the recording of an expected call.
+	Recorded JournalEntryKind = iota
+	// Matched is a call matching an expected call.
+	Matched
+	// Unmatched is a call matching no expected call.
+	Unmatched
+)
+
+// A JournalEntry records an expected call being recorded, or a call made
+// to a mock, for WithVerbose.
+type JournalEntry struct {
+	Kind     JournalEntryKind
+	Receiver string // described as in failure messages
+	Method   string
+	// Args describes the argument matchers of a recorded call, or the
+	// arguments of a call made.
+	Args   []string
+	Origin string // where the call was recorded or made, as file:line
+	// Call is the expected call recorded or matched, nil for an unmatched
+	// call.
+	Call *Call
+	// Reason explains why an unmatched call matched no expected call.
+	Reason string
+}
+
+// String describes the entry as WithVerbose logs it.
+func (e JournalEntry) String() string {
+	call := fmt.Sprintf("%s.%s(%s) at %s", e.Receiver, e.Method, strings.Join(e.Args, ", "), e.Origin)
+	switch e.Kind {
+	case Recorded:
+		return "recorded expected call " + call
+	case Matched:
+		return fmt.Sprintf("call %s matched expected call at %s", call, e.Call.origin)
+	default:
+		return fmt.Sprintf("call %s matched no expected call because: %s", call, e.Reason)
+	}
+}
+
+// WithVerbose makes the controller keep a journal of the expected calls
+// recorded and of the calls made to its mocks, which Journal returns, and
+// write a line per entry to w if it isn't nil. This is useful to find out
+// why an expected call doesn't match. Without it, nothing is formatted.
+func WithVerbose(w io.Writer) ControllerOption {
+	return optionFunc(func(ctrl *Controller) {
+		ctrl.verbose = true
+		ctrl.verboseLog = w
+	})
+}
+
+// Journal returns the entries recorded with WithVerbose, oldest first.
+func (ctrl *Controller) Journal() []JournalEntry {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	return append([]JournalEntry(nil), ctrl.journal...)
+}
+
+// journalRecorded journals the recording of call. It must be called with
+// the lock held.
+func (ctrl *Controller) journalRecorded(call *Call) {
+	args := make([]string, len(call.args))
+	for i, m := range call.args {
+		args[i] = m.String()
+	}
+	ctrl.addJournalEntry(JournalEntry{
+		Kind:     Recorded,
+		Receiver: call.receiverName(),
+		Method:   call.method,
+		Args:     args,
+		Origin:   call.origin,
+		Call:     call,
+	})
+}
+
+// journalCall journals a call made at origin, which matched expected or
+// failed to match because of err. It must be called with the lock held.
+func (ctrl *Controller) journalCall(receiver interface{}, method string, args []interface{}, origin string, expected *Call, err error) {
+	e := JournalEntry{
+		Kind:     Matched,
+		Receiver: ctrl.receiverName(receiver),
+		Method:   method,
+		Args:     make([]string, len(args)),
+		Origin:   origin,
+		Call:     expected,
+	}
+	for i, arg := range args {
+		e.Args[i] = FormatValue(arg)
+	}
+	if err != nil {
+		e.Kind = Unmatched
+		e.Reason = err.Error()
+	}
+	ctrl.addJournalEntry(e)
+}
+
+func (ctrl *Controller) addJournalEntry(e JournalEntry) {
+	ctrl.journal = append(ctrl.journal, e)
+	if ctrl.verboseLog != nil {
+		fmt.Fprintln(ctrl.verboseLog, e)
+	}
+}
@@ -17,8 +17,15 @@
 package gomock
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // A Matcher is a representation of a class of values.
@@ -31,6 +38,170 @@ type Matcher interface {
 	String() string
 }
 
+// A TypedMatcher is a Matcher that only matches values of one type. When
+// an expectation is recorded, a TypedMatcher given for an argument whose
+// type ExpectedType isn't assignable to is reported as an error, rather
+// than silently never matching.
+type TypedMatcher interface {
+	Matcher
+
+	// ExpectedType returns the type of the values the matcher matches.
+	ExpectedType() reflect.Type
+}
+
+// argTypeValidator is implemented by matchers that can tell, when an
+// expectation is recorded, that they won't ever match arguments of the
+// method's parameter type.
+type argTypeValidator interface {
+	validateArgType(t reflect.Type) error
+}
+
+// GotFormatter is used to better print failure messages. If a matcher
+// implements GotFormatter, the result of Got is used in place of the
+// argument's default %v rendering when the matcher fails to match.
+type GotFormatter interface {
+	// Got is invoked with the received value. The result is used when
+	// printing the failure message.
+	Got(got interface{}) string
+}
+
+// GotFormatterFunc is an adapter to allow the use of ordinary functions as
+// a GotFormatter. If f is a function with the appropriate signature,
+// GotFormatterFunc(f) is a GotFormatter that calls f.
+type GotFormatterFunc func(got interface{}) string
+
+// Got implements GotFormatter.
+func (f GotFormatterFunc) Got(got interface{}) string {
+	return f(got)
+}
+
+// GotFormatterAdapter attaches a GotFormatter to a Matcher.
+func GotFormatterAdapter(gf GotFormatter, m Matcher) Matcher {
+	return struct {
+		GotFormatter
+		Matcher
+	}{
+		GotFormatter: gf,
+		Matcher:      m,
+	}
+}
+
+// WantFormatter modifies the given Matcher's String() method to the given
+// Stringer. This allows for control over how the "Want" is formatted when
+// printing failure messages.
+func WantFormatter(s fmt.Stringer, m Matcher) Matcher {
+	type matcher interface {
+		Matches(x interface{}) bool
+	}
+
+	return struct {
+		matcher
+		fmt.Stringer
+	}{
+		matcher:  m,
+		Stringer: s,
+	}
+}
+
+// StringerFunc is an adapter to allow the use of ordinary functions as a
+// fmt.Stringer. If f is a function with the appropriate signature,
+// StringerFunc(f) is a fmt.Stringer that calls f.
+type StringerFunc func() string
+
+// String implements fmt.Stringer.
+func (f StringerFunc) String() string {
+	return f()
+}
+
+// MatcherFunc is a Matcher built from a function and a description, for
+// matchers that don't need a type of their own.
+//
+//	isEven := gomock.MatcherFunc{
+//		Func:        func(x interface{}) bool { return x.(int)%2 == 0 },
+//		Description: "is an even number",
+//	}
+type MatcherFunc struct {
+	// Func reports whether x is a match.
+	Func func(x interface{}) bool
+	// Description describes what Func matches, and is used as the "Want"
+	// of failure messages.
+	Description string
+}
+
+// Matches implements Matcher.
+func (m MatcherFunc) Matches(x interface{}) bool {
+	return m.Func(x)
+}
+
+// String implements Matcher.
+func (m MatcherFunc) String() string {
+	return m.Description
+}
+
+const (
+	// maxFormattedElems is the number of bytes of a string, or elements of
+	// a slice, FormatValue renders before eliding the rest.
+	maxFormattedElems = 64
+	// maxFormattedLen is the length FormatValue truncates its output to.
+	maxFormattedLen = 1024
+)
+
+// FormatValue renders a value the way gomock's built-in matchers and
+// failure messages do. Matchers defined outside gomock can use it in their
+// String and Got methods to produce consistent output.
+//
+// The output is bounded, so that a huge argument doesn't drown a failure
+// message: only the first 64 bytes of a string, or elements of a slice, are
+// rendered, followed by "...", unless the value formats itself with a
+// String, Error or Format method, and the whole output is truncated to 1024
+// bytes, followed by "...".
+func FormatValue(x interface{}) string {
+	s := formatElided(x)
+	if len(s) > maxFormattedLen {
+		n := maxFormattedLen
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		s = s[:n] + "..."
+	}
+	return s
+}
+
+// formatElided formats x, eliding the elements of a long string or slice.
+func formatElided(x interface{}) string {
+	switch x.(type) {
+	case fmt.Stringer, error, fmt.Formatter:
+		return fmt.Sprintf("%v", x)
+	}
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.String:
+		if s := v.String(); len(s) > maxFormattedElems {
+			n := maxFormattedElems
+			for n > 0 && !utf8.RuneStart(s[n]) {
+				n--
+			}
+			return s[:n] + "..."
+		}
+	case reflect.Slice:
+		if v.Len() > maxFormattedElems {
+			s := fmt.Sprintf("%v", v.Slice(0, maxFormattedElems).Interface())
+			return strings.TrimSuffix(s, "]") + " ...]"
+		}
+	}
+	return fmt.Sprintf("%v", x)
+}
+
+// formatArgs renders the arguments of a call for failure messages, like
+// %v does a slice, with FormatValue.
+func formatArgs(args []interface{}) string {
+	s := make([]string, len(args))
+	for i, arg := range args {
+		s[i] = FormatValue(arg)
+	}
+	return "[" + strings.Join(s, " ") + "]"
+}
+
 type anyMatcher struct{}
 
 func (anyMatcher) Matches(x interface{}) bool {
@@ -46,11 +217,62 @@ type eqMatcher struct {
 }
 
 func (e eqMatcher) Matches(x interface{}) bool {
+	if cmp, ok := registeredEquality(e.x, x); ok {
+		return cmp(e.x, x)
+	}
+	if equal, ok := callEqualMethod(e.x, x); ok {
+		return equal
+	}
 	return reflect.DeepEqual(e.x, x)
 }
 
+// callEqualMethod calls want.Equal(got) if want has an Equal method that
+// accepts got and returns a bool. ok is false if there is no such method or
+// if it panics.
+func callEqualMethod(want, got interface{}) (equal, ok bool) {
+	if want == nil || got == nil {
+		return false, false
+	}
+	m := reflect.ValueOf(want).MethodByName("Equal")
+	if !m.IsValid() {
+		return false, false
+	}
+	mt := m.Type()
+	if mt.NumIn() != 1 || mt.IsVariadic() || mt.NumOut() != 1 || mt.Out(0).Kind() != reflect.Bool ||
+		!reflect.TypeOf(got).AssignableTo(mt.In(0)) {
+		return false, false
+	}
+	defer func() {
+		if recover() != nil {
+			equal, ok = false, false
+		}
+	}()
+	return m.Call([]reflect.Value{reflect.ValueOf(got)})[0].Bool(), true
+}
+
 func (e eqMatcher) String() string {
-	return fmt.Sprintf("is equal to %v", e.x)
+	return "is equal to " + FormatValue(e.x)
+}
+
+func (e eqMatcher) diff(x interface{}) string {
+	return diffValues(e.x, x)
+}
+
+func (e eqMatcher) validateArgType(reflect.Type) error {
+	if v := reflect.ValueOf(e.x); v.Kind() == reflect.Func && !v.IsNil() {
+		return fmt.Errorf("can't compare non-nil funcs for equality; use gomock.SameFunc or gomock.AssignableToTypeOf instead")
+	}
+	return nil
+}
+
+type restAnyMatcher struct{}
+
+func (restAnyMatcher) Matches(interface{}) bool {
+	return true
+}
+
+func (restAnyMatcher) String() string {
+	return "is any number of further arguments"
 }
 
 type nilMatcher struct{}
@@ -87,13 +309,890 @@ func (n notMatcher) String() string {
 	return "not(" + n.m.String() + ")"
 }
 
+type allMatcher struct {
+	matchers []Matcher
+}
+
+func (am allMatcher) Matches(x interface{}) bool {
+	for _, m := range am.matchers {
+		if !m.Matches(x) {
+			return false
+		}
+	}
+	return true
+}
+
+func (am allMatcher) String() string {
+	if len(am.matchers) == 0 {
+		return "is anything"
+	}
+	ss := make([]string, 0, len(am.matchers))
+	for _, matcher := range am.matchers {
+		ss = append(ss, matcher.String())
+	}
+	return strings.Join(ss, " and ")
+}
+
+type anyOfMatcher struct {
+	matchers []Matcher
+}
+
+func (am anyOfMatcher) Matches(x interface{}) bool {
+	for _, m := range am.matchers {
+		if m.Matches(x) {
+			return true
+		}
+	}
+	return false
+}
+
+func (am anyOfMatcher) String() string {
+	if len(am.matchers) == 0 {
+		return "is nothing"
+	}
+	ss := make([]string, 0, len(am.matchers))
+	for _, matcher := range am.matchers {
+		ss = append(ss, matcher.String())
+	}
+	return strings.Join(ss, " or ")
+}
+
+type regexMatcher struct {
+	re *regexp.Regexp
+}
+
+func (m regexMatcher) Matches(x interface{}) bool {
+	switch v := x.(type) {
+	case string:
+		return m.re.MatchString(v)
+	case []byte:
+		return m.re.Match(v)
+	case fmt.Stringer:
+		return m.re.MatchString(v.String())
+	}
+	return false
+}
+
+func (m regexMatcher) String() string {
+	return fmt.Sprintf("matches regex %q", m.re.String())
+}
+
+type condMatcher struct {
+	fn func(x interface{}) bool
+}
+
+func (c condMatcher) Matches(x interface{}) (ok bool) {
+	// A predicate that panics, e.g. on a failed type assertion, does not
+	// match.
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return c.fn(x)
+}
+
+func (condMatcher) String() string {
+	return "satisfies custom condition"
+}
+
+type approxMatcher struct {
+	value, tolerance float64
+}
+
+func (m approxMatcher) Matches(x interface{}) bool {
+	var f float64
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f = v.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		f = float64(v.Uint())
+	default:
+		return false
+	}
+	// Equal infinities match, though their difference is NaN. NaN compares
+	// false with everything, so it never matches.
+	return f == m.value || math.Abs(f-m.value) <= m.tolerance
+}
+
+func (m approxMatcher) String() string {
+	return fmt.Sprintf("is approximately %v (+/- %v)", m.value, m.tolerance)
+}
+
+type fieldsMatcher struct {
+	names  []string // sorted keys of fields
+	fields map[string]Matcher
+}
+
+func (m fieldsMatcher) Matches(x interface{}) bool {
+	v := reflect.ValueOf(x)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+	for _, name := range m.names {
+		sf, ok := v.Type().FieldByName(name)
+		if !ok || sf.PkgPath != "" {
+			return false
+		}
+		f, ok := fieldByIndex(v, sf.Index)
+		if !ok || !m.fields[name].Matches(f.Interface()) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m fieldsMatcher) String() string {
+	ss := make([]string, len(m.names))
+	for i, name := range m.names {
+		ss[i] = name + ": " + m.fields[name].String()
+	}
+	return "has fields {" + strings.Join(ss, ", ") + "}"
+}
+
+func (m fieldsMatcher) validateArgType(t reflect.Type) error {
+	if t.Kind() == reflect.Interface {
+		// Any struct could be passed.
+		return nil
+	}
+	st := t
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		return fmt.Errorf("Fields matcher used for %v, which is not a struct or a pointer to a struct", t)
+	}
+	for _, name := range m.names {
+		sf, ok := st.FieldByName(name)
+		if !ok {
+			return fmt.Errorf("%v has no field %s", st, name)
+		}
+		if sf.PkgPath != "" {
+			return fmt.Errorf("field %s of %v is unexported", name, st)
+		}
+	}
+	return nil
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but reports false
+// instead of panicking when it has to go through a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+type hasKeyMatcher struct {
+	key Matcher
+}
+
+func (m hasKeyMatcher) Matches(x interface{}) bool {
+	v := reflect.ValueOf(x)
+	if v.Kind() != reflect.Map {
+		return false
+	}
+	for _, k := range v.MapKeys() {
+		if m.key.Matches(k.Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m hasKeyMatcher) String() string {
+	return "has a key that " + m.key.String()
+}
+
+type subsetOfMapMatcher struct {
+	entries map[interface{}]Matcher
+}
+
+func (m subsetOfMapMatcher) Matches(x interface{}) bool {
+	v := reflect.ValueOf(x)
+	if v.Kind() != reflect.Map {
+		return false
+	}
+	keyType := v.Type().Key()
+	for k, vm := range m.entries {
+		kv := reflect.ValueOf(k)
+		if !kv.IsValid() || !kv.Type().AssignableTo(keyType) {
+			return false
+		}
+		ev := v.MapIndex(kv)
+		if !ev.IsValid() || !vm.Matches(ev.Interface()) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m subsetOfMapMatcher) String() string {
+	ss := make([]string, 0, len(m.entries))
+	for k, vm := range m.entries {
+		ss = append(ss, FormatValue(k)+": "+vm.String())
+	}
+	// Map iteration order is random; keep the description stable.
+	sort.Strings(ss)
+	return "contains entries {" + strings.Join(ss, ", ") + "}"
+}
+
+type derefMatcher struct {
+	m Matcher
+}
+
+func (d derefMatcher) Matches(x interface{}) bool {
+	v, ok := derefValue(reflect.ValueOf(x))
+	if !ok {
+		return false
+	}
+	if !v.IsValid() {
+		return d.m.Matches(nil)
+	}
+	return d.m.Matches(v.Interface())
+}
+
+func (d derefMatcher) String() string {
+	return "(dereferenced) " + d.m.String()
+}
+
+// derefValue follows pointers until it reaches a value that is not a
+// pointer. It reports false if it encounters a nil pointer.
+func derefValue(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+type withinDurationMatcher struct {
+	expected time.Time
+	delta    time.Duration
+}
+
+func (m withinDurationMatcher) Matches(x interface{}) bool {
+	var t time.Time
+	switch v := x.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return false
+		}
+		t = *v
+	default:
+		return false
+	}
+	// Compare wall clock readings, like time.Time.Equal, independent of
+	// location and of monotonic clock readings only one side may have.
+	t, expected := t.Round(0), m.expected.Round(0)
+	return !t.Before(expected.Add(-m.delta)) && !t.After(expected.Add(m.delta))
+}
+
+func (m withinDurationMatcher) String() string {
+	return fmt.Sprintf("is within %v of %s", m.delta, FormatValue(m.expected))
+}
+
+type jsonEqMatcher struct {
+	expected string
+	want     interface{}
+	err      error // error decoding expected
+}
+
+func (m jsonEqMatcher) Matches(x interface{}) bool {
+	if m.err != nil {
+		return false
+	}
+	got, err := decodeJSONArg(x)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(m.want, got)
+}
+
+func (m jsonEqMatcher) String() string {
+	if m.err != nil {
+		return fmt.Sprintf("is JSON equal to %s (which is invalid JSON: %v)", m.expected, m.err)
+	}
+	return "is JSON equal to " + m.expected
+}
+
+// Got implements GotFormatter so that failures explain why an argument
+// couldn't be decoded.
+func (m jsonEqMatcher) Got(got interface{}) string {
+	if _, err := decodeJSONArg(got); err != nil {
+		return fmt.Sprintf("%s (%v)", formatJSONArg(got), err)
+	}
+	return formatJSONArg(got)
+}
+
+func decodeJSONArg(x interface{}) (interface{}, error) {
+	var data []byte
+	switch v := x.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case json.RawMessage:
+		data = v
+	default:
+		return nil, fmt.Errorf("%T is not a string, []byte or json.RawMessage", x)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	return decoded, nil
+}
+
+func formatJSONArg(x interface{}) string {
+	switch v := x.(type) {
+	case []byte:
+		return string(v)
+	case json.RawMessage:
+		return string(v)
+	}
+	return FormatValue(x)
+}
+
+type stringMatcher struct {
+	desc   string // e.g. "has prefix"
+	needle string
+	match  func(s, needle string) bool
+}
+
+func (m stringMatcher) Matches(x interface{}) bool {
+	switch v := x.(type) {
+	case string:
+		return m.match(v, m.needle)
+	case []byte:
+		return m.match(string(v), m.needle)
+	}
+	return false
+}
+
+func (m stringMatcher) String() string {
+	return fmt.Sprintf("%s %q", m.desc, m.needle)
+}
+
+type stringPredicateMatcher struct {
+	desc string // e.g. "is a non-empty string"
+	fn   func(string) bool
+}
+
+func (m stringPredicateMatcher) Matches(x interface{}) (ok bool) {
+	v := reflect.ValueOf(x)
+	if v.Kind() != reflect.String {
+		return false
+	}
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return m.fn(v.String())
+}
+
+func (m stringPredicateMatcher) String() string {
+	return m.desc
+}
+
+type sameFuncMatcher struct {
+	f reflect.Value
+}
+
+func (m sameFuncMatcher) Matches(x interface{}) bool {
+	v := reflect.ValueOf(x)
+	if v.Kind() != reflect.Func || v.Type() != m.f.Type() {
+		return false
+	}
+	return v.Pointer() == m.f.Pointer()
+}
+
+func (m sameFuncMatcher) String() string {
+	return fmt.Sprintf("is the same %v as %#x", m.f.Type(), m.f.Pointer())
+}
+
+type chanOfTypeMatcher struct {
+	elemType reflect.Type
+}
+
+func (m chanOfTypeMatcher) Matches(x interface{}) bool {
+	t := reflect.TypeOf(x)
+	return t != nil && t.Kind() == reflect.Chan && t.Elem() == m.elemType
+}
+
+func (m chanOfTypeMatcher) String() string {
+	return fmt.Sprintf("is a channel of %v", m.elemType)
+}
+
+type assignableToTypeOfMatcher struct {
+	targetType reflect.Type
+}
+
+func (m assignableToTypeOfMatcher) Matches(x interface{}) bool {
+	if x == nil {
+		// A nil interface has no type; it can only stand in for a type that
+		// is able to hold nil.
+		if m.targetType == nil {
+			return true
+		}
+		switch m.targetType.Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
+			reflect.Ptr, reflect.Slice:
+			return true
+		}
+		return false
+	}
+	if m.targetType == nil {
+		return false
+	}
+	return reflect.TypeOf(x).AssignableTo(m.targetType)
+}
+
+func (m assignableToTypeOfMatcher) String() string {
+	if m.targetType == nil {
+		return "is assignable to nil"
+	}
+	return "is assignable to " + m.targetType.String()
+}
+
+type lenMatcher struct {
+	i int
+}
+
+func (m lenMatcher) Matches(x interface{}) bool {
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Array, reflect.Chan, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == m.i
+	}
+	return false
+}
+
+func (m lenMatcher) String() string {
+	return fmt.Sprintf("has length %d", m.i)
+}
+
+type inAnyOrderMatcher struct {
+	x interface{}
+}
+
+func (m inAnyOrderMatcher) Matches(x interface{}) bool {
+	given, ok := sliceValue(x)
+	if !ok {
+		return false
+	}
+	wanted, ok := sliceValue(m.x)
+	if !ok || given.Len() != wanted.Len() {
+		return false
+	}
+
+	// Every wanted element has to be paired with a distinct given element,
+	// so that duplicates are counted correctly.
+	used := make([]bool, given.Len())
+	for i := 0; i < wanted.Len(); i++ {
+		found := false
+		for j := 0; j < given.Len(); j++ {
+			if used[j] {
+				continue
+			}
+			if reflect.DeepEqual(wanted.Index(i).Interface(), given.Index(j).Interface()) {
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (m inAnyOrderMatcher) String() string {
+	return "has the same elements as " + FormatValue(m.x)
+}
+
+type containsMatcher struct {
+	x interface{}
+	m Matcher
+}
+
+func (c containsMatcher) Matches(x interface{}) bool {
+	if s, ok := x.(string); ok {
+		if sub, ok := c.x.(string); ok {
+			return strings.Contains(s, sub)
+		}
+	}
+	elems, ok := elementsOf(x)
+	if !ok {
+		return false
+	}
+	for _, e := range elems {
+		if c.m.Matches(e) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c containsMatcher) String() string {
+	return "contains an element that " + c.m.String()
+}
+
+type eachMatcher struct {
+	m Matcher
+}
+
+func (e eachMatcher) Matches(x interface{}) bool {
+	elems, ok := elementsOf(x)
+	if !ok {
+		return false
+	}
+	for _, elem := range elems {
+		if !e.m.Matches(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+func (e eachMatcher) String() string {
+	return "has elements that all " + e.m.String()
+}
+
+// elementsOf returns the elements of a slice or array, or the runes of a
+// string.
+func elementsOf(x interface{}) ([]interface{}, bool) {
+	if s, ok := x.(string); ok {
+		var elems []interface{}
+		for _, r := range s {
+			elems = append(elems, r)
+		}
+		return elems, true
+	}
+	v, ok := sliceValue(x)
+	if !ok {
+		return nil, false
+	}
+	elems := make([]interface{}, v.Len())
+	for i := range elems {
+		elems[i] = v.Index(i).Interface()
+	}
+	return elems, true
+}
+
+// sliceValue returns the reflect.Value of x if x is a slice or an array.
+func sliceValue(x interface{}) (reflect.Value, bool) {
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		return v, true
+	}
+	return reflect.Value{}, false
+}
+
 // Constructors
-func Any() Matcher             { return anyMatcher{} }
+func Any() Matcher { return anyMatcher{} }
+
+// Eq returns a Matcher that compares its argument with x. It uses the
+// function registered for the type of x with RegisterEqualityFor if there is
+// one, otherwise x's Equal method if it has one that accepts the argument
+// and returns a bool, like time.Time's, and reflect.DeepEqual otherwise.
 func Eq(x interface{}) Matcher { return eqMatcher{x} }
-func Nil() Matcher             { return nilMatcher{} }
+
+// Nil returns a Matcher that matches nil and the nil values of channel,
+// func, interface, map, pointer and slice types. Values of other kinds never
+// match. A nil expected argument is matched with Nil rather than Eq.
+func Nil() Matcher { return nilMatcher{} }
+
+// Not reverses the results of its given child matcher. If x is not a
+// Matcher it is compared for equality, i.e. Not(x) is the same as
+// Not(Eq(x)).
+//
+//	Not(Eq(5)).Matches(4) // returns true
+//	Not(5).Matches(5)     // returns false
+//	Not(Not(Nil()))       // same as Nil()
 func Not(x interface{}) Matcher {
 	if m, ok := x.(Matcher); ok {
 		return notMatcher{m}
 	}
 	return notMatcher{Eq(x)}
 }
+
+// AssignableToTypeOf is a Matcher that matches if the parameter to the mock
+// function is assignable to the type of x. If x is a reflect.Type, that type
+// is used directly, which allows matching against interface types:
+//
+//	AssignableToTypeOf(&bytes.Buffer{})                              // any *bytes.Buffer
+//	AssignableToTypeOf(reflect.TypeOf((*io.Reader)(nil)).Elem())     // any io.Reader
+func AssignableToTypeOf(x interface{}) Matcher {
+	if xt, ok := x.(reflect.Type); ok {
+		return assignableToTypeOfMatcher{xt}
+	}
+	return assignableToTypeOfMatcher{reflect.TypeOf(x)}
+}
+
+// Len returns a matcher that matches on the length of strings, arrays,
+// slices, maps and channels. Values of any other kind never match.
+func Len(i int) Matcher { return lenMatcher{i} }
+
+// InAnyOrder is a Matcher that matches a slice or array with the same
+// elements as x, regardless of their order. Elements are compared with
+// reflect.DeepEqual and duplicates must appear the same number of times on
+// both sides.
+//
+//	InAnyOrder([]string{"a", "b", "a"}).Matches([]string{"a", "a", "b"}) // returns true
+//	InAnyOrder([]string{"a", "b", "a"}).Matches([]string{"a", "b", "b"}) // returns false
+func InAnyOrder(x interface{}) Matcher { return inAnyOrderMatcher{x} }
+
+// All returns a composite Matcher that matches only if all of the given
+// matchers match. All() with no matchers matches everything, like Any().
+//
+//	All(Len(2), Not(Eq([]int{0, 0}))) // any two-element slice except [0 0]
+func All(ms ...Matcher) Matcher { return allMatcher{ms} }
+
+// AnyOf returns a composite Matcher that matches if at least one of the
+// given values matches. Values that are not Matchers are wrapped the same
+// way arguments to an expected call are: nil becomes Nil() and anything else
+// becomes Eq(x). AnyOf() with no values matches nothing.
+//
+//	AnyOf(1, 2, Nil()).Matches(2)   // returns true
+//	AnyOf(1, 2, Nil()).Matches(nil) // returns true
+//	AnyOf(1, 2, Nil()).Matches(3)   // returns false
+func AnyOf(xs ...interface{}) Matcher {
+	ms := make([]Matcher, len(xs))
+	for i, x := range xs {
+		ms[i] = wrapMatcher(x)
+	}
+	return anyOfMatcher{ms}
+}
+
+// wrapMatcher returns x if it is a Matcher, and otherwise a Matcher that
+// tests for equality with x.
+func wrapMatcher(x interface{}) Matcher {
+	if m, ok := x.(Matcher); ok {
+		return m
+	}
+	if x == nil {
+		// Handle nil specially so that passing a nil interface value
+		// will match the typed nils of concrete args.
+		return Nil()
+	}
+	return Eq(x)
+}
+
+// Regex returns a Matcher that matches strings, byte slices and
+// fmt.Stringers against the regular expression pattern. The pattern is
+// compiled once, when the matcher is created; Regex panics if it is invalid.
+//
+//	Regex("^SELECT .* FROM users").Matches("SELECT id FROM users") // returns true
+//	Regex("^SELECT").Matches([]byte("DELETE FROM users"))          // returns false
+func Regex(pattern string) Matcher {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		panic(fmt.Sprintf("gomock: invalid pattern passed to Regex: %v", err))
+	}
+	return regexMatcher{re}
+}
+
+// Cond returns a Matcher that matches when fn returns true for the argument.
+// A panic inside fn is treated as a non-match.
+//
+//	Cond(func(x interface{}) bool { return x.(*Msg).ID > 0 })
+func Cond(fn func(x interface{}) bool) Matcher { return condMatcher{fn} }
+
+// Approx returns a Matcher that matches floating point and integer values
+// within tolerance of value, inclusive, or equal to it, which includes the
+// infinities. NaN never matches, even when value is itself NaN.
+//
+//	Approx(0.3, 1e-9).Matches(0.1 + 0.2) // returns true
+//	Approx(3, 0.5).Matches(int64(3))     // returns true
+func Approx(value, tolerance float64) Matcher { return approxMatcher{value, tolerance} }
+
+// Fields returns a Matcher that matches a struct, or a pointer to a struct,
+// whose named exported fields match the given values. Fields not mentioned
+// are ignored. Values that are not Matchers are compared for equality, and
+// fields promoted from embedded structs can be named directly.
+//
+// Naming a field that the mocked method's parameter type doesn't have fails
+// the test when the expectation is recorded.
+//
+//	Fields(map[string]interface{}{"ID": 5, "Name": Regex("^a")})
+func Fields(fields map[string]interface{}) Matcher {
+	m := fieldsMatcher{fields: make(map[string]Matcher, len(fields))}
+	for name, x := range fields {
+		m.names = append(m.names, name)
+		m.fields[name] = wrapMatcher(x)
+	}
+	sort.Strings(m.names)
+	return m
+}
+
+// HasKey returns a Matcher that matches maps with a key equal to k, or
+// matching k if it is a Matcher. Arguments that are not maps never match.
+//
+//	HasKey("id").Matches(map[string]int{"id": 1}) // returns true
+func HasKey(k interface{}) Matcher { return hasKeyMatcher{wrapMatcher(k)} }
+
+// SubsetOfMap returns a Matcher that matches maps containing every key in m
+// with a value matching the one in m. Values in m may be Matchers. Entries
+// of the argument that are not in m are ignored, so an empty m matches any
+// map, including a nil one. Keys must be of a type assignable to the
+// argument's key type to ever match.
+//
+//	SubsetOfMap(map[interface{}]interface{}{"a": 1, "b": Any()})
+func SubsetOfMap(m map[interface{}]interface{}) Matcher {
+	entries := make(map[interface{}]Matcher, len(m))
+	for k, v := range m {
+		entries[k] = wrapMatcher(v)
+	}
+	return subsetOfMapMatcher{entries}
+}
+
+// Contains returns a Matcher that matches slices and arrays with at least
+// one element equal to x, or matching x if it is a Matcher. A string
+// argument matches if it contains x as a substring when x is a string, and
+// otherwise if one of its runes matches.
+//
+//	Contains(2).Matches([]int{1, 2, 3})           // returns true
+//	Contains("ell").Matches("hello")              // returns true
+//	Contains(Len(2)).Matches([]string{"a", "bc"}) // returns true
+func Contains(x interface{}) Matcher { return containsMatcher{x, wrapMatcher(x)} }
+
+// Each returns a Matcher that matches slices and arrays, or the runes of a
+// string, when every element matches m. Empty values always match.
+//
+//	Each(Not(Nil())).Matches([]*int{new(int)}) // returns true
+func Each(m Matcher) Matcher { return eachMatcher{m} }
+
+// Deref returns a Matcher that follows pointer arguments, through any number
+// of levels, before matching the value they point to. A nil pointer never
+// matches. If x is not a Matcher, it is dereferenced in the same way and
+// compared for equality, so Deref(foo) and Deref(&foo) both match foo, &foo
+// and a **Foo pointing to it.
+func Deref(x interface{}) Matcher {
+	if m, ok := x.(Matcher); ok {
+		return derefMatcher{m}
+	}
+	if v, ok := derefValue(reflect.ValueOf(x)); ok && v.IsValid() {
+		x = v.Interface()
+	}
+	return derefMatcher{wrapMatcher(x)}
+}
+
+// WithinDuration returns a Matcher that matches a time.Time, or a non-nil
+// *time.Time, no more than delta before or after expected.
+//
+//	WithinDuration(time.Now(), time.Second)
+func WithinDuration(expected time.Time, delta time.Duration) Matcher {
+	return withinDurationMatcher{expected, delta}
+}
+
+// JSONEq returns a Matcher that matches strings, byte slices and
+// json.RawMessages holding JSON that is semantically equal to expected: key
+// order, whitespace and number formatting don't matter. Invalid JSON on
+// either side never matches, and the decoding error is included in the
+// failure message.
+//
+//	JSONEq(`{"a": 1, "b": [true]}`).Matches([]byte(`{"b":[true],"a":1.0}`)) // returns true
+func JSONEq(expected string) Matcher {
+	m := jsonEqMatcher{expected: expected}
+	if err := json.Unmarshal([]byte(expected), &m.want); err != nil {
+		m.err = err
+	}
+	return m
+}
+
+// HasPrefix returns a Matcher that matches strings and byte slices that
+// begin with prefix.
+func HasPrefix(prefix string) Matcher {
+	return stringMatcher{"has prefix", prefix, strings.HasPrefix}
+}
+
+// HasSuffix returns a Matcher that matches strings and byte slices that end
+// with suffix.
+func HasSuffix(suffix string) Matcher {
+	return stringMatcher{"has suffix", suffix, strings.HasSuffix}
+}
+
+// ContainsSubstring returns a Matcher that matches strings and byte slices
+// that contain substr.
+func ContainsSubstring(substr string) Matcher {
+	return stringMatcher{"contains substring", substr, strings.Contains}
+}
+
+// NonEmptyString returns a Matcher that matches strings, including values of
+// named string types, that are not empty.
+//
+//	mockStore.EXPECT().Save(gomock.NonEmptyString(), gomock.Any())
+func NonEmptyString() Matcher {
+	return stringPredicateMatcher{"is a non-empty string", func(s string) bool { return s != "" }}
+}
+
+// StringMatching returns a Matcher that matches strings, including values of
+// named string types, for which verify returns true. Other arguments never
+// match and are not passed to verify. A panic inside verify is treated as a
+// non-match.
+//
+//	isUUID := func(s string) bool { _, err := uuid.Parse(s); return err == nil }
+//	mockStore.EXPECT().Load(gomock.StringMatching(isUUID))
+func StringMatching(verify func(string) bool) Matcher {
+	return stringPredicateMatcher{"is a string that satisfies custom condition", verify}
+}
+
+// SameFunc returns a Matcher that matches funcs of the same type as f that
+// have the same code pointer. Since funcs can't be compared for equality,
+// Eq can't be used for func arguments.
+//
+// Closures created by the same function literal share a code pointer, and so
+// do method values of the same method, regardless of their receiver.
+func SameFunc(f interface{}) Matcher {
+	v := reflect.ValueOf(f)
+	if v.Kind() != reflect.Func {
+		panic(fmt.Sprintf("gomock: SameFunc requires a func, got %T", f))
+	}
+	return sameFuncMatcher{v}
+}
+
+// ChanOfType returns a Matcher that matches channels, of any direction and
+// capacity, whose element type is the type of x. If x is a reflect.Type,
+// that type is used as the element type.
+//
+//	ChanOfType("").Matches(make(chan string, 1)) // returns true
+//	ChanOfType(0).Matches(make(<-chan string))   // returns false
+func ChanOfType(x interface{}) Matcher {
+	if xt, ok := x.(reflect.Type); ok {
+		return chanOfTypeMatcher{xt}
+	}
+	return chanOfTypeMatcher{reflect.TypeOf(x)}
+}
+
+// RestAny returns a Matcher that, as the last expected argument of a
+// variadic method, matches any number of remaining actual arguments,
+// including none. The arguments before it are matched one by one.
+//
+//	// Matches Printf("%d", 1), Printf("%d"), Printf("%d", 1, 2), ...
+//	mockLogger.EXPECT().Printf("%d", gomock.RestAny())
+//	// Matches Printf("%d %s", 1), Printf("%d %s", 1, "a"), ...
+//	mockLogger.EXPECT().Printf("%d %s", 1, gomock.RestAny())
+func RestAny() Matcher { return restAnyMatcher{} }
@@ -0,0 +1,109 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.13
+// +build go1.13
+
+package gomock
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// This file contains matchers that rely on the error wrapping and
+// reflect.Value.IsZero support added in Go 1.13.
+
+type errorIsMatcher struct {
+	target error
+}
+
+func (m errorIsMatcher) Matches(x interface{}) bool {
+	if x == nil {
+		return m.target == nil
+	}
+	err, ok := x.(error)
+	if !ok {
+		return false
+	}
+	return errors.Is(err, m.target)
+}
+
+func (m errorIsMatcher) String() string {
+	if m.target == nil {
+		return "is a nil error"
+	}
+	return fmt.Sprintf("is or wraps error %q", m.target.Error())
+}
+
+type errorAsMatcher struct {
+	targetType reflect.Type // the type pointed to by the target passed to ErrorAs
+}
+
+func (m errorAsMatcher) Matches(x interface{}) bool {
+	err, ok := x.(error)
+	if !ok {
+		return false
+	}
+	// Use a fresh target so that matching doesn't write to the caller's.
+	return errors.As(err, reflect.New(m.targetType).Interface())
+}
+
+func (m errorAsMatcher) String() string {
+	return fmt.Sprintf("is or wraps an error of type %v", m.targetType)
+}
+
+type isZeroMatcher struct{}
+
+func (isZeroMatcher) Matches(x interface{}) bool {
+	if x == nil {
+		return true
+	}
+	return reflect.ValueOf(x).IsZero()
+}
+
+func (isZeroMatcher) String() string {
+	return "is the zero value"
+}
+
+// IsZero returns a Matcher that matches the zero value of any type: 0, "",
+// false, nil, and structs and arrays whose elements are all zero. A non-nil
+// pointer is not zero, even if it points to a zero value.
+func IsZero() Matcher { return isZeroMatcher{} }
+
+// ErrorIs returns a Matcher that matches errors for which errors.Is(err,
+// target) is true. Arguments that are not errors never match.
+//
+//	ErrorIs(io.EOF).Matches(fmt.Errorf("reading: %w", io.EOF)) // returns true
+func ErrorIs(target error) Matcher { return errorIsMatcher{target} }
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// ErrorAs returns a Matcher that matches errors for which errors.As(err,
+// target) would succeed. target must be a non-nil pointer to an interface
+// type or to a type implementing error; ErrorAs panics otherwise. target
+// itself is never written to.
+//
+//	ErrorAs(new(*os.PathError)).Matches(fmt.Errorf("open: %w", &os.PathError{})) // returns true
+func ErrorAs(target interface{}) Matcher {
+	tt := reflect.TypeOf(target)
+	if tt == nil || tt.Kind() != reflect.Ptr || reflect.ValueOf(target).IsNil() {
+		panic("gomock: target passed to ErrorAs must be a non-nil pointer")
+	}
+	if e := tt.Elem(); e.Kind() != reflect.Interface && !e.Implements(errorType) {
+		panic(fmt.Sprintf("gomock: target passed to ErrorAs must be a pointer to an interface or to a type implementing error, not %v", tt))
+	}
+	return errorAsMatcher{tt.Elem()}
+}
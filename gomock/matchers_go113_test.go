@@ -0,0 +1,114 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.13
+// +build go1.13
+
+package gomock_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestErrorMatchers(t *testing.T) {
+	type e interface{}
+	pathErr := &os.PathError{Op: "open", Path: "/x", Err: os.ErrNotExist}
+	tests := []struct {
+		matcher gomock.Matcher
+		yes, no []e
+	}{
+		{gomock.ErrorIs(io.EOF),
+			[]e{io.EOF, fmt.Errorf("reading: %w", io.EOF), fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", io.EOF))},
+			[]e{nil, errors.New("EOF"), fmt.Errorf("reading: %v", io.EOF), "EOF", io.ErrUnexpectedEOF}},
+		{gomock.ErrorIs(nil), []e{nil}, []e{io.EOF, 0}},
+		{gomock.ErrorAs(new(*os.PathError)),
+			[]e{pathErr, fmt.Errorf("wrapped: %w", pathErr)},
+			[]e{nil, io.EOF, "an error", fmt.Errorf("wrapped: %v", pathErr)}},
+		{gomock.ErrorAs(new(interface{ Timeout() bool })),
+			[]e{&timeoutError{}, fmt.Errorf("wrapped: %w", &timeoutError{})},
+			[]e{nil, io.EOF}},
+	}
+	for i, test := range tests {
+		for _, x := range test.yes {
+			if !test.matcher.Matches(x) {
+				t.Errorf(`test %d: "%v %s" should be true.`, i, x, test.matcher)
+			}
+		}
+		for _, x := range test.no {
+			if test.matcher.Matches(x) {
+				t.Errorf(`test %d: "%v %s" should be false.`, i, x, test.matcher)
+			}
+		}
+	}
+}
+
+type timeoutError struct{}
+
+func (*timeoutError) Error() string { return "timeout" }
+func (*timeoutError) Timeout() bool { return true }
+
+func TestErrorMatchersString(t *testing.T) {
+	tests := []struct {
+		matcher gomock.Matcher
+		want    string
+	}{
+		{gomock.ErrorIs(io.EOF), `is or wraps error "EOF"`},
+		{gomock.ErrorIs(nil), "is a nil error"},
+		{gomock.ErrorAs(new(*timeoutError)), "is or wraps an error of type *gomock_test.timeoutError"},
+	}
+	for _, test := range tests {
+		if got := test.matcher.String(); got != test.want {
+			t.Errorf("String() == %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestErrorAsInvalidTarget(t *testing.T) {
+	for _, target := range []interface{}{nil, (*error)(nil), os.PathError{}, new(int)} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("ErrorAs(%#v) did not panic", target)
+				}
+			}()
+			gomock.ErrorAs(target)
+		}()
+	}
+}
+
+func TestIsZeroMatcher(t *testing.T) {
+	type e interface{}
+	m := gomock.IsZero()
+	yes := []e{nil, 0, 0.0, uint8(0), "", false, TestStruct{}, [2]int{}, (*TestStruct)(nil), []int(nil), error(nil)}
+	no := []e{1, -0.5, "a", true, TestStruct{Number: 1}, &TestStruct{}, new(int), []int{}, [2]int{0, 1}, io.EOF}
+	for _, x := range yes {
+		if !m.Matches(x) {
+			t.Errorf(`"%#v %s" should be true.`, x, m)
+		}
+	}
+	for _, x := range no {
+		if m.Matches(x) {
+			t.Errorf(`"%#v %s" should be false.`, x, m)
+		}
+	}
+	if s := m.String(); s != "is the zero value" {
+		t.Errorf(`String() == %q, want "is the zero value"`, s)
+	}
+}
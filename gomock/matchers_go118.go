@@ -0,0 +1,108 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package gomock
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// This file contains matchers that use type parameters, which were added in
+// Go 1.18.
+
+// typedArg converts x to T. It reports false if x's dynamic type isn't T.
+// An untyped nil converts to the zero value of T if T can hold nil.
+func typedArg[T any](x interface{}) (T, bool) {
+	if v, ok := x.(T); ok {
+		return v, true
+	}
+	var zero T
+	if x == nil {
+		switch reflect.TypeOf((*T)(nil)).Elem().Kind() {
+		case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
+			reflect.Ptr, reflect.Slice:
+			return zero, true
+		}
+	}
+	return zero, false
+}
+
+type condTMatcher[T any] struct {
+	fn func(T) bool
+}
+
+func (c condTMatcher[T]) Matches(x interface{}) (ok bool) {
+	v, ok := typedArg[T](x)
+	if !ok {
+		return false
+	}
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return c.fn(v)
+}
+
+func (c condTMatcher[T]) String() string {
+	return fmt.Sprintf("is a %v that satisfies custom condition", reflect.TypeOf((*T)(nil)).Elem())
+}
+
+func (condTMatcher[T]) ExpectedType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+type eqTMatcher[T comparable] struct {
+	x T
+}
+
+func (e eqTMatcher[T]) Matches(x interface{}) (ok bool) {
+	v, ok := typedArg[T](x)
+	if !ok {
+		return false
+	}
+	// Comparing interface values holding uncomparable types panics.
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return v == e.x
+}
+
+func (e eqTMatcher[T]) String() string {
+	return fmt.Sprintf("is equal to %s (%v)", FormatValue(e.x), reflect.TypeOf((*T)(nil)).Elem())
+}
+
+func (eqTMatcher[T]) ExpectedType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// CondT is like Cond, but fn receives the argument as a T. Arguments whose
+// dynamic type isn't T don't match, rather than making a type assertion in
+// fn panic.
+//
+//	CondT(func(m *Msg) bool { return m.ID > 0 })
+func CondT[T any](fn func(T) bool) Matcher { return condTMatcher[T]{fn} }
+
+// EqT is like Eq, but compares with == and only matches arguments whose
+// dynamic type is T.
+//
+//	EqT[int64](5).Matches(int64(5)) // returns true
+//	EqT[int64](5).Matches(5)        // returns false
+func EqT[T comparable](x T) Matcher { return eqTMatcher[T]{x} }
@@ -0,0 +1,101 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package gomock_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestTypedMatchers(t *testing.T) {
+	type e interface{}
+	tests := []struct {
+		matcher gomock.Matcher
+		desc    string
+		yes, no []e
+	}{
+		{gomock.CondT(func(s TestStruct) bool { return s.Number > 0 }),
+			"is a gomock_test.TestStruct that satisfies custom condition",
+			[]e{TestStruct{Number: 1}},
+			[]e{TestStruct{}, &TestStruct{Number: 1}, 1, nil}},
+		{gomock.CondT(func(s *TestStruct) bool { return s.Number > 0 }),
+			"is a *gomock_test.TestStruct that satisfies custom condition",
+			[]e{&TestStruct{Number: 1}},
+			// A nil pointer makes the predicate panic.
+			[]e{&TestStruct{}, (*TestStruct)(nil), nil, TestStruct{Number: 1}}},
+		{gomock.CondT(func(s fmt.Stringer) bool { return s == nil }),
+			"is a fmt.Stringer that satisfies custom condition",
+			[]e{nil},
+			[]e{gomock.StringerFunc(func() string { return "" }), "not a Stringer"}},
+		{gomock.EqT[int64](5), "is equal to 5 (int64)", []e{int64(5)}, []e{5, int32(5), int64(4), nil}},
+		{gomock.EqT("s"), "is equal to s (string)", []e{"s"}, []e{[]byte("s"), 's', nil}},
+		{gomock.EqT[interface{}](1), "is equal to 1 (interface {})", []e{1}, []e{"1", []int{1}, nil}},
+	}
+	for _, test := range tests {
+		if got := test.matcher.String(); got != test.desc {
+			t.Errorf("String() == %q, want %q", got, test.desc)
+		}
+		for _, x := range test.yes {
+			if !test.matcher.Matches(x) {
+				t.Errorf(`"%v %s" should be true.`, x, test.matcher)
+			}
+		}
+		for _, x := range test.no {
+			if test.matcher.Matches(x) {
+				t.Errorf(`"%v %s" should be false.`, x, test.matcher)
+			}
+		}
+	}
+}
+
+func TestTypedMatchersInExpectations(t *testing.T) {
+	reporter, ctrl := createFixtures(t)
+	defer reporter.recoverUnexpectedFatal()
+	subject := new(Subject)
+
+	ctrl.RecordCall(subject, "FooMethod", gomock.CondT(func(s string) bool { return len(s) == 3 }))
+	reporter.assertFatal(func() {
+		ctrl.Call(subject, "FooMethod", 3)
+	}, "doesn't match the argument at index 0")
+	ctrl.Call(subject, "FooMethod", "abc")
+	ctrl.Finish()
+}
+
+func TestTypedMatchersExpectedType(t *testing.T) {
+	rep, ctrl := createFixtures(t)
+	defer rep.recoverUnexpectedFatal()
+	s := new(Subject)
+
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "FooMethod", gomock.EqT[int64](1))
+	}, "argument 0 is a matcher for int64, which is not assignable to string")
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "FooMethod", gomock.CondT(func(int) bool { return true }))
+	}, "argument 0 is a matcher for int, which is not assignable to string")
+
+	ctrl.RecordCall(s, "FooMethod", gomock.EqT("a"))
+	ctrl.RecordCall(s, "LogMethod", "%v", gomock.CondT(func(error) bool { return true }))
+	rep.assertFatal(func() {
+		ctrl.RecordCall(s, "GetMethod", gomock.CondT(func(fmt.Stringer) bool { return true }))
+	}, "argument 0 is a matcher for fmt.Stringer, which is not assignable to int")
+	ctrl.Call(s, "FooMethod", "a")
+	ctrl.Call(s, "LogMethod", "%v", &notFoundError{})
+	ctrl.Finish()
+}
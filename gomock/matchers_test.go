@@ -15,8 +15,16 @@
 package gomock_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	mock_matcher "github.com/golang/mock/gomock/mock_matcher"
@@ -32,9 +40,98 @@ func TestMatchers(t *testing.T) {
 		testCase{gomock.Any(), []e{3, nil, "foo"}, nil},
 		testCase{gomock.Eq(4), []e{4}, []e{3, "blah", nil, int64(4)}},
 		testCase{gomock.Nil(),
-			[]e{nil, (error)(nil), (chan bool)(nil), (*int)(nil)},
-			[]e{"", 0, make(chan bool), errors.New("err"), new(int)}},
+			[]e{nil, (error)(nil), (chan bool)(nil), (*int)(nil), []byte(nil), map[string]int(nil), (func())(nil)},
+			[]e{"", 0, make(chan bool), errors.New("err"), new(int), []byte{}, map[string]int{}, func() {},
+				false, 0.0, struct{}{}, [0]int{}, TestStruct{}}},
 		testCase{gomock.Not(gomock.Eq(4)), []e{3, "blah", nil, int64(4)}, []e{4}},
+		testCase{gomock.Not(4), []e{3, "blah", nil, int64(4)}, []e{4}},
+		testCase{gomock.Not(gomock.Not(4)), []e{4}, []e{3, "blah", nil, int64(4)}},
+		testCase{gomock.Not(gomock.Nil()), []e{"", 0, new(int)}, []e{nil, (*int)(nil)}},
+		testCase{gomock.All(), []e{3, nil, "foo"}, nil},
+		testCase{gomock.All(gomock.Len(2), gomock.Not(gomock.Eq([]int{0, 0}))),
+			[]e{[]int{1, 2}, []int{0, 1}, "ab"},
+			[]e{[]int{0, 0}, []int{1}, nil, 2}},
+		testCase{gomock.All(gomock.Not(gomock.Nil()), gomock.Eq(4)), []e{4}, []e{nil, 3, int64(4)}},
+		testCase{gomock.AnyOf(), nil, []e{3, nil, "foo"}},
+		testCase{gomock.AnyOf(1, 2, gomock.Nil()),
+			[]e{1, 2, nil, (*int)(nil)},
+			[]e{3, int64(1), "1", new(int)}},
+		testCase{gomock.AnyOf(gomock.Len(1), "foo"), []e{"a", []int{1}, "foo"}, []e{"", "bar", 1}},
+		testCase{gomock.Regex("^SELECT .* FROM users$"),
+			[]e{"SELECT id FROM users", []byte("SELECT * FROM users"), bytes.NewBufferString("SELECT a FROM users")},
+			[]e{"DELETE FROM users", " SELECT id FROM users", "SELECT id FROM users2", 42, nil}},
+		testCase{gomock.Regex("id=[0-9]+"), []e{"user id=42 logged in", []byte("id=1")}, []e{"id=", []int{1}}},
+		testCase{gomock.Cond(func(x interface{}) bool { return x.(int) > 2 }),
+			[]e{3, 100},
+			[]e{2, -1, "blah", nil, int64(4)}},
+		testCase{gomock.Approx(0.3, 1e-9),
+			[]e{0.1 + 0.2, 0.3, 0.3 + 1e-10},
+			[]e{0.31, float32(0.30001), math.NaN(), "0.3", nil}},
+		testCase{gomock.Approx(3, 0.5),
+			[]e{3, int64(3), uint8(3), 3.5, float32(2.5)},
+			[]e{1, 4, 3.51, math.Inf(1)}},
+		testCase{gomock.Approx(math.NaN(), 1), nil, []e{math.NaN(), 0.0, 1}},
+		testCase{gomock.Approx(math.Inf(1), 1e-9), []e{math.Inf(1), float32(math.Inf(1))}, []e{math.Inf(-1), math.MaxFloat64, math.NaN()}},
+		testCase{gomock.Approx(math.Inf(-1), 0), []e{math.Inf(-1)}, []e{math.Inf(1), -math.MaxFloat64, math.NaN()}},
+		testCase{gomock.Fields(map[string]interface{}{"Number": 1}),
+			[]e{TestStruct{Number: 1}, &TestStruct{Number: 1, Message: "a"}},
+			[]e{TestStruct{Number: 2}, (*TestStruct)(nil), 1, nil}},
+		testCase{gomock.Fields(map[string]interface{}{"Number": 1, "Message": gomock.Regex("^h")}),
+			[]e{TestStruct{Number: 1, Message: "hello"}},
+			[]e{TestStruct{Number: 1, Message: "bye"}, TestStruct{Number: 2, Message: "hello"}}},
+		testCase{gomock.Fields(map[string]interface{}{"Number": 1, "Extra": true}),
+			[]e{embeddingStruct{TestStruct{Number: 1}, true}, &embeddingStruct{TestStruct{Number: 1}, true}},
+			[]e{embeddingStruct{TestStruct{Number: 1}, false}, TestStruct{Number: 1}}},
+		testCase{gomock.Fields(map[string]interface{}{"Number": 1}),
+			[]e{embeddingPtrStruct{&TestStruct{Number: 1}}},
+			[]e{embeddingPtrStruct{}}},
+		testCase{gomock.Contains(2),
+			[]e{[]int{1, 2, 3}, [2]int{2, 2}, []interface{}{"a", 2}},
+			[]e{[]int{}, []int(nil), []int{1, 3}, []int64{2}, 2, nil}},
+		testCase{gomock.Contains("ell"), []e{"hello", []string{"a", "ell"}}, []e{"help", "", []string{"hello"}}},
+		testCase{gomock.Contains('l'), []e{"hello"}, []e{"hi", ""}},
+		testCase{gomock.Contains(gomock.Len(2)), []e{[]string{"a", "bc"}}, []e{[]string{"a", "bcd"}, []string{}}},
+		testCase{gomock.Each(gomock.Not(gomock.Nil())),
+			[]e{[]*int{new(int), new(int)}, []*int{}, [0]error{}, []int(nil)},
+			[]e{[]*int{new(int), nil}, []error{nil}, new(int), nil}},
+		testCase{gomock.Each(gomock.Eq('a')), []e{"aaa", ""}, []e{"aab", []string{"a"}}},
+		testCase{gomock.Deref(TestStruct{Number: 1}),
+			func() []e {
+				v := &TestStruct{Number: 1}
+				return []e{TestStruct{Number: 1}, v, &v}
+			}(),
+			[]e{TestStruct{Number: 2}, &TestStruct{Number: 2}, (*TestStruct)(nil), (**TestStruct)(nil), nil}},
+		testCase{gomock.Deref(&TestStruct{Number: 1}), []e{TestStruct{Number: 1}, &TestStruct{Number: 1}}, []e{&TestStruct{}}},
+		testCase{gomock.Deref(gomock.Fields(map[string]interface{}{"Number": 1})),
+			func() []e {
+				v := &TestStruct{Number: 1}
+				return []e{v, &v}
+			}(),
+			[]e{(**TestStruct)(nil), new(*TestStruct)}},
+		testCase{gomock.JSONEq(`{"a": 1, "b": [true, null], "c": {"d": "e"}}`),
+			[]e{`{"c":{"d":"e"},"b":[true,null],"a":1}`, []byte(`{"a": 1.0, "b": [true, null], "c": {"d": "e"}}`),
+				json.RawMessage(`{"a": 1e0, "c": {"d": "e"}, "b": [true, null]}`)},
+			[]e{`{"a": 1, "b": [null, true], "c": {"d": "e"}}`, `{"a": 1}`, `{"a": 1,`, "", 1, nil}},
+		testCase{gomock.JSONEq(`{"a": `), nil, []e{`{"a": `, `{"a": 1}`}},
+		testCase{gomock.AssignableToTypeOf(0), []e{4, 0}, []e{"blah", int64(4), nil}},
+		testCase{gomock.AssignableToTypeOf(reflect.TypeOf((*io.Reader)(nil)).Elem()),
+			[]e{&bytes.Buffer{}, (*bytes.Buffer)(nil), nil},
+			[]e{3, "blah", bytes.Buffer{}}},
+		testCase{gomock.Len(0),
+			[]e{"", []int(nil), []int{}, map[string]int{}, [0]int{}, make(chan int)},
+			[]e{"a", []int{1}, 0, nil, struct{}{}}},
+		testCase{gomock.Len(2),
+			[]e{"ab", []int{1, 2}, map[int]bool{1: true, 2: false}, [2]string{}},
+			[]e{"a", []int{1, 2, 3}, 2, int64(2)}},
+		testCase{gomock.InAnyOrder([]int{1, 2, 2}),
+			[]e{[]int{1, 2, 2}, []int{2, 1, 2}, []int{2, 2, 1}, [3]int{2, 1, 2}},
+			[]e{[]int{1, 2}, []int{1, 1, 2}, []int{1, 2, 2, 2}, 1, "122", nil}},
+		testCase{gomock.InAnyOrder([]int{}),
+			[]e{[]int{}, []int(nil), [0]int{}},
+			[]e{[]int{1}, nil, 0}},
+		testCase{gomock.InAnyOrder([]TestStruct{{1, "a"}, {2, "b"}}),
+			[]e{[]TestStruct{{2, "b"}, {1, "a"}}},
+			[]e{[]TestStruct{{2, "b"}, {1, "b"}}, []TestStruct{{1, "a"}}}},
 	}
 	for i, test := range tests {
 		for _, x := range test.yes {
@@ -50,6 +147,15 @@ func TestMatchers(t *testing.T) {
 	}
 }
 
+type embeddingStruct struct {
+	TestStruct
+	Extra bool
+}
+
+type embeddingPtrStruct struct {
+	*TestStruct
+}
+
 // A more thorough test of notMatcher
 func TestNotMatcher(t *testing.T) {
 	ctrl := gomock.NewController(t)
@@ -68,3 +174,424 @@ func TestNotMatcher(t *testing.T) {
 		t.Errorf("notMatcher should match 5")
 	}
 }
+
+func TestAllMatcherString(t *testing.T) {
+	tests := []struct {
+		matcher gomock.Matcher
+		want    string
+	}{
+		{gomock.All(), "is anything"},
+		{gomock.All(gomock.Eq(3)), "is equal to 3"},
+		{gomock.All(gomock.Len(2), gomock.Not(gomock.Nil())), "has length 2 and not(is nil)"},
+	}
+	for _, test := range tests {
+		if got := test.matcher.String(); got != test.want {
+			t.Errorf("String() == %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestAnyOfMatcherString(t *testing.T) {
+	tests := []struct {
+		matcher gomock.Matcher
+		want    string
+	}{
+		{gomock.AnyOf(), "is nothing"},
+		{gomock.AnyOf(1, 2, nil), "is equal to 1 or is equal to 2 or is nil"},
+		{gomock.AnyOf(gomock.Len(1), "foo"), "has length 1 or is equal to foo"},
+	}
+	for _, test := range tests {
+		if got := test.matcher.String(); got != test.want {
+			t.Errorf("String() == %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestRegexMatcherInvalidPattern(t *testing.T) {
+	defer func() {
+		err := recover()
+		if err == nil {
+			t.Fatal("Regex with an invalid pattern did not panic")
+		}
+		if msg, ok := err.(string); !ok || !strings.Contains(msg, "invalid pattern passed to Regex") {
+			t.Errorf("unexpected panic message: %v", err)
+		}
+	}()
+	gomock.Regex("a(b")
+}
+
+func TestCondMatcher(t *testing.T) {
+	m := gomock.Cond(func(x interface{}) bool { return x.(TestStruct).Number > 0 })
+	if !m.Matches(TestStruct{Number: 1}) {
+		t.Error("Cond should match TestStruct{Number: 1}")
+	}
+	if m.Matches(TestStruct{Number: 0}) {
+		t.Error("Cond should not match TestStruct{Number: 0}")
+	}
+	if m.Matches("not a TestStruct") {
+		t.Error("Cond should not match when the predicate panics")
+	}
+	if s := m.String(); s != "satisfies custom condition" {
+		t.Errorf(`String() == %q, want "satisfies custom condition"`, s)
+	}
+}
+
+func TestApproxMatcherString(t *testing.T) {
+	if s := gomock.Approx(3.14, 0.01).String(); s != "is approximately 3.14 (+/- 0.01)" {
+		t.Errorf(`String() == %q, want "is approximately 3.14 (+/- 0.01)"`, s)
+	}
+}
+
+func TestMapMatchers(t *testing.T) {
+	type key struct {
+		A int
+		B string
+	}
+	tests := []struct {
+		name    string
+		matcher gomock.Matcher
+		arg     interface{}
+		want    bool
+	}{
+		{"HasKey string", gomock.HasKey("a"), map[string]int{"a": 1}, true},
+		{"HasKey string missing", gomock.HasKey("b"), map[string]int{"a": 1}, false},
+		{"HasKey int", gomock.HasKey(2), map[int]bool{1: true, 2: false}, true},
+		{"HasKey struct", gomock.HasKey(key{1, "x"}), map[key]int{{1, "x"}: 0}, true},
+		{"HasKey struct missing", gomock.HasKey(key{1, "y"}), map[key]int{{1, "x"}: 0}, false},
+		{"HasKey matcher", gomock.HasKey(gomock.Regex("^id")), map[string]int{"identifier": 0}, true},
+		{"HasKey key type mismatch", gomock.HasKey(1), map[int64]int{1: 0}, false},
+		{"HasKey nil map", gomock.HasKey("a"), map[string]int(nil), false},
+		{"HasKey not a map", gomock.HasKey("a"), []string{"a"}, false},
+		{"SubsetOfMap empty", gomock.SubsetOfMap(nil), map[string]int{"a": 1}, true},
+		{"SubsetOfMap empty nil map", gomock.SubsetOfMap(map[interface{}]interface{}{}), map[string]int(nil), true},
+		{"SubsetOfMap empty not a map", gomock.SubsetOfMap(nil), "a", false},
+		{"SubsetOfMap subset", gomock.SubsetOfMap(map[interface{}]interface{}{"a": 1}), map[string]int{"a": 1, "b": 2}, true},
+		{"SubsetOfMap wrong value", gomock.SubsetOfMap(map[interface{}]interface{}{"a": 2}), map[string]int{"a": 1, "b": 2}, false},
+		{"SubsetOfMap missing key", gomock.SubsetOfMap(map[interface{}]interface{}{"c": 1}), map[string]int{"a": 1}, false},
+		{"SubsetOfMap nested matcher", gomock.SubsetOfMap(map[interface{}]interface{}{"a": gomock.Not(0), "b": gomock.Any()}), map[string]int{"a": 1, "b": 0}, true},
+		{"SubsetOfMap struct keys", gomock.SubsetOfMap(map[interface{}]interface{}{key{1, "x"}: "v"}), map[key]string{{1, "x"}: "v", {2, "y"}: "w"}, true},
+		{"SubsetOfMap key type mismatch", gomock.SubsetOfMap(map[interface{}]interface{}{1: 1}), map[string]int{"1": 1}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.matcher.Matches(test.arg); got != test.want {
+				t.Errorf("%s Matches(%v) == %v, want %v", test.matcher, test.arg, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSliceElementMatchersString(t *testing.T) {
+	tests := []struct {
+		matcher gomock.Matcher
+		want    string
+	}{
+		{gomock.Contains(2), "contains an element that is equal to 2"},
+		{gomock.Each(gomock.Not(gomock.Nil())), "has elements that all not(is nil)"},
+	}
+	for _, test := range tests {
+		if got := test.matcher.String(); got != test.want {
+			t.Errorf("String() == %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestWithinDurationMatcher(t *testing.T) {
+	now := time.Now()
+	m := gomock.WithinDuration(now, time.Second)
+
+	justInside := now.Add(time.Second)
+	justOutside := now.Add(-time.Second - time.Nanosecond)
+	yes := []interface{}{now, justInside, now.Add(-time.Second), &justInside, now.UTC(), now.Round(0)}
+	no := []interface{}{justOutside, &justOutside, (*time.Time)(nil), time.Time{}, now.Unix(), nil}
+	for _, x := range yes {
+		if !m.Matches(x) {
+			t.Errorf(`"%v %s" should be true.`, x, m)
+		}
+	}
+	for _, x := range no {
+		if m.Matches(x) {
+			t.Errorf(`"%v %s" should be false.`, x, m)
+		}
+	}
+}
+
+func TestStringMatchers(t *testing.T) {
+	type e interface{}
+	tests := []struct {
+		matcher gomock.Matcher
+		desc    string
+		yes, no []e
+	}{
+		{gomock.HasPrefix("foo"), `has prefix "foo"`,
+			[]e{"foo", "foobar", []byte("foo bar")},
+			[]e{"fo", "barfoo", "", []byte("bar"), 1, nil, bytes.NewBufferString("foo")}},
+		{gomock.HasSuffix("bar"), `has suffix "bar"`,
+			[]e{"bar", "foobar", []byte("foo bar")},
+			[]e{"ba", "barfoo", "", []int{}, nil}},
+		{gomock.ContainsSubstring("ob"), `contains substring "ob"`,
+			[]e{"ob", "foobar", []byte("bob")},
+			[]e{"o b", "", 'o', nil}},
+		{gomock.HasPrefix(""), `has prefix ""`, []e{"", "anything", []byte(nil)}, []e{0, nil}},
+		{gomock.HasSuffix(""), `has suffix ""`, []e{"", "anything"}, []e{0, nil}},
+		{gomock.ContainsSubstring(""), `contains substring ""`, []e{"", "anything"}, []e{0, nil}},
+	}
+	for _, test := range tests {
+		if got := test.matcher.String(); got != test.desc {
+			t.Errorf("String() == %q, want %q", got, test.desc)
+		}
+		for _, x := range test.yes {
+			if !test.matcher.Matches(x) {
+				t.Errorf(`"%v %s" should be true.`, x, test.matcher)
+			}
+		}
+		for _, x := range test.no {
+			if test.matcher.Matches(x) {
+				t.Errorf(`"%v %s" should be false.`, x, test.matcher)
+			}
+		}
+	}
+}
+
+func TestSameFuncMatcher(t *testing.T) {
+	s1, s2 := new(Subject), new(Subject)
+	m := gomock.SameFunc(s1.FooMethod)
+
+	if !m.Matches(s1.FooMethod) {
+		t.Error("SameFunc should match the same method value")
+	}
+	if !m.Matches(s2.FooMethod) {
+		t.Error("SameFunc should match method values of the same method")
+	}
+	if m.Matches(s1.BarMethod) {
+		t.Error("SameFunc should not match a different method with the same signature")
+	}
+	if m.Matches(func(string) int { return 0 }) || m.Matches("FooMethod") || m.Matches(nil) {
+		t.Error("SameFunc should not match other funcs or non-funcs")
+	}
+	if gomock.SameFunc(TestSameFuncMatcher).Matches(TestFieldsMatcherString) {
+		t.Error("SameFunc should not match a different func of the same type")
+	}
+	if !gomock.SameFunc(TestSameFuncMatcher).Matches(TestSameFuncMatcher) {
+		t.Error("SameFunc should match the same func")
+	}
+}
+
+func TestChanOfTypeMatcher(t *testing.T) {
+	type e interface{}
+	m := gomock.ChanOfType("")
+	yes := []e{make(chan string), make(chan string, 10), make(<-chan string), make(chan<- string), (chan string)(nil)}
+	no := []e{make(chan int), make(chan []string), "", []string{}, nil}
+	for _, x := range yes {
+		if !m.Matches(x) {
+			t.Errorf(`"%T %s" should be true.`, x, m)
+		}
+	}
+	for _, x := range no {
+		if m.Matches(x) {
+			t.Errorf(`"%T %s" should be false.`, x, m)
+		}
+	}
+	if !gomock.ChanOfType(reflect.TypeOf((*io.Reader)(nil)).Elem()).Matches(make(chan io.Reader)) {
+		t.Error("ChanOfType should accept a reflect.Type for the element type")
+	}
+	if s := m.String(); s != "is a channel of string" {
+		t.Errorf(`String() == %q, want "is a channel of string"`, s)
+	}
+}
+
+type money struct {
+	Cents    int64
+	Currency string
+	label    string // cached display string, not part of the value
+}
+
+func (m money) Equal(o money) bool {
+	return m.Cents == o.Cents && m.Currency == o.Currency
+}
+
+type panickyEqual struct {
+	n *int
+}
+
+func (p panickyEqual) Equal(o panickyEqual) bool {
+	return *p.n == *o.n
+}
+
+type otherEqual int
+
+// Equal doesn't take an otherEqual, so Eq must not use it.
+func (otherEqual) Equal(string) bool { return true }
+
+func TestEqMatcherEqualMethod(t *testing.T) {
+	now := time.Now()
+	if !gomock.Eq(now).Matches(now.Round(0)) {
+		t.Error("Eq should use time.Time.Equal, ignoring the monotonic clock reading")
+	}
+	if !gomock.Eq(now.UTC()).Matches(now.In(time.FixedZone("X", 3600))) {
+		t.Error("Eq should use time.Time.Equal, ignoring the location")
+	}
+	if gomock.Eq(now).Matches(now.Add(time.Nanosecond)) {
+		t.Error("Eq should not match different instants")
+	}
+
+	usd := money{Cents: 100, Currency: "USD"}
+	if !gomock.Eq(usd).Matches(money{Cents: 100, Currency: "USD", label: "$1.00"}) {
+		t.Error("Eq should use the Equal method of a custom type")
+	}
+	if gomock.Eq(usd).Matches(money{Cents: 100, Currency: "EUR"}) {
+		t.Error("Eq should not match when Equal returns false")
+	}
+	if gomock.Eq(usd).Matches(usd.Cents) || gomock.Eq(usd).Matches(nil) {
+		t.Error("Eq should not call Equal with values it doesn't accept")
+	}
+
+	// A panicking Equal falls back to reflect.DeepEqual.
+	if !gomock.Eq(panickyEqual{}).Matches(panickyEqual{}) {
+		t.Error("Eq should fall back to reflect.DeepEqual when Equal panics")
+	}
+	if gomock.Eq(panickyEqual{}).Matches(panickyEqual{new(int)}) {
+		t.Error("Eq should fall back to reflect.DeepEqual when Equal panics")
+	}
+
+	if gomock.Eq(otherEqual(1)).Matches(otherEqual(2)) {
+		t.Error("Eq should ignore Equal methods with a different parameter type")
+	}
+}
+
+func TestStringPredicateMatchers(t *testing.T) {
+	type id string
+	type e interface{}
+	testCases := []struct {
+		matcher gomock.Matcher
+		desc    string
+		yes, no []e
+	}{
+		{gomock.NonEmptyString(), "is a non-empty string",
+			[]e{"a", " ", id("x")},
+			[]e{"", id(""), []byte("a"), 1, nil, (*string)(nil)}},
+		{gomock.StringMatching(func(s string) bool { return len(s) == 3 }), "is a string that satisfies custom condition",
+			[]e{"abc", id("abc")},
+			[]e{"", "ab", []byte("abc"), 123, nil}},
+		{gomock.StringMatching(func(s string) bool { return s[1] == 'b' }), "is a string that satisfies custom condition",
+			[]e{"ab"},
+			[]e{"", "a"}},
+	}
+	for i, tc := range testCases {
+		for _, x := range tc.yes {
+			if !tc.matcher.Matches(x) {
+				t.Errorf(`test %d: "%v %s" should be true.`, i, x, tc.matcher)
+			}
+		}
+		for _, x := range tc.no {
+			if tc.matcher.Matches(x) {
+				t.Errorf(`test %d: "%v %s" should be false.`, i, x, tc.matcher)
+			}
+		}
+		if s := tc.matcher.String(); s != tc.desc {
+			t.Errorf("test %d: String() == %q, want %q", i, s, tc.desc)
+		}
+	}
+
+	called := false
+	gomock.StringMatching(func(string) bool { called = true; return true }).Matches(1)
+	if called {
+		t.Error("StringMatching should not call verify for non-string arguments")
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	testCases := []struct {
+		x    interface{}
+		want string
+	}{
+		{nil, "<nil>"},
+		{3, "3"},
+		{"a", "a"},
+		{[]int{1, 2}, "[1 2]"},
+		{TestStruct{1, "x"}, "{1 x}"},
+		{errors.New("boom"), "boom"},
+	}
+	for _, tc := range testCases {
+		if got := gomock.FormatValue(tc.x); got != tc.want {
+			t.Errorf("FormatValue(%#v) == %q, want %q", tc.x, got, tc.want)
+		}
+	}
+	if got, want := gomock.Eq([]int{1, 2}).String(), "is equal to "+gomock.FormatValue([]int{1, 2}); got != want {
+		t.Errorf("Eq String() == %q, want %q", got, want)
+	}
+}
+
+func TestFormatValueTruncates(t *testing.T) {
+	ints := make([]int, 100)
+	for i := range ints {
+		ints[i] = i
+	}
+	long := []string{strings.Repeat("x", 600), strings.Repeat("y", 600)}
+	testCases := []struct {
+		name string
+		x    interface{}
+		want string
+	}{
+		{"long string", strings.Repeat("a", 100), strings.Repeat("a", 64) + "..."},
+		{"string cut within a rune", "a" + strings.Repeat("é", 40), "a" + strings.Repeat("é", 31) + "..."},
+		{"short string", strings.Repeat("a", 64), strings.Repeat("a", 64)},
+		{"long slice", ints, strings.TrimSuffix(fmt.Sprint(ints[:64]), "]") + " ...]"},
+		{"bytes", bytes.Repeat([]byte{1}, 65), strings.TrimSuffix(fmt.Sprint(bytes.Repeat([]byte{1}, 64)), "]") + " ...]"},
+		{"long output", long, "[" + long[0] + " " + long[1][:1024-602] + "..."},
+		{"stringer", stringerFunc(func() string { return strings.Repeat("s", 100) }), strings.Repeat("s", 100)},
+	}
+	for _, tc := range testCases {
+		if got := gomock.FormatValue(tc.x); got != tc.want {
+			t.Errorf("%s: FormatValue() == %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+type stringerFunc func() string
+
+func (f stringerFunc) String() string { return f() }
+
+func TestFieldsMatcherString(t *testing.T) {
+	m := gomock.Fields(map[string]interface{}{"Number": 1, "Message": gomock.Nil()})
+	if s, want := m.String(), "has fields {Message: is nil, Number: is equal to 1}"; s != want {
+		t.Errorf("String() == %q, want %q", s, want)
+	}
+}
+
+func TestNotMatcherString(t *testing.T) {
+	tests := []struct {
+		matcher gomock.Matcher
+		want    string
+	}{
+		{gomock.Not(3), "not(is equal to 3)"},
+		{gomock.Not(gomock.Eq(3)), "not(is equal to 3)"},
+		{gomock.Not(gomock.Not(gomock.Nil())), "not(not(is nil))"},
+	}
+	for _, test := range tests {
+		if got := test.matcher.String(); got != test.want {
+			t.Errorf("String() == %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestAssignableToTypeOfMatcher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMatcher := mock_matcher.NewMockMatcher(ctrl)
+	mockMatcher.EXPECT().Matches(gomock.AssignableToTypeOf(&bytes.Buffer{})).Return(true).Times(2)
+
+	mockMatcher.Matches(&bytes.Buffer{})
+	mockMatcher.Matches(bytes.NewBufferString("contents"))
+
+	if s := gomock.AssignableToTypeOf(&bytes.Buffer{}).String(); s != "is assignable to *bytes.Buffer" {
+		t.Errorf(`String() == %q, want "is assignable to *bytes.Buffer"`, s)
+	}
+}
+
+func TestLenMatcherString(t *testing.T) {
+	if s := gomock.Len(5).String(); s != "has length 5" {
+		t.Errorf(`String() == %q, want "has length 5"`, s)
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomock
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// An ExpectationReport describes the expected calls which haven't been made
+// as many times as they have to be, grouped by receiver and method. It is
+// what Finish reports, in a form suitable for tools.
+type ExpectationReport struct {
+	// Groups are sorted by receiver and method, then by the origin of their
+	// first call, for receivers described the same.
+	Groups []MissingCallGroup
+}
+
+// A MissingCallGroup is the missing calls of a method of a receiver.
+type MissingCallGroup struct {
+	Receiver string // described as in failure messages
+	Method   string
+	// Calls are sorted by origin.
+	Calls []MissingCall
+}
+
+// A MissingCall describes an expected call which hasn't been made as many
+// times as it has to be.
+type MissingCall struct {
+	Args     []string // the descriptions of the argument matchers
+	Name     string   // the name given with Call.Name, if any
+	Origin   string   // where the call was recorded, as file:line
+	MinCalls int
+	MaxCalls int // -1 if unlimited
+	NumCalls int // the number of calls made
+}
+
+// Report describes the expected calls which haven't been made as many times
+// as they have to be yet.
+func (ctrl *Controller) Report() *ExpectationReport {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	return newExpectationReport(ctrl.failures())
+}
+
+type callGroupKey struct {
+	receiver interface{}
+	method   string
+}
+
+func newExpectationReport(failures []*Call) *ExpectationReport {
+	r := &ExpectationReport{}
+	groups := make(map[callGroupKey]int)
+	for _, c := range failures {
+		key := callGroupKey{c.receiver, c.method}
+		i, ok := groups[key]
+		if !ok {
+			i = len(r.Groups)
+			groups[key] = i
+			r.Groups = append(r.Groups, MissingCallGroup{Receiver: c.receiverName(), Method: c.method})
+		}
+		r.Groups[i].Calls = append(r.Groups[i].Calls, newMissingCall(c))
+	}
+	for _, g := range r.Groups {
+		sort.Stable(byOrigin(g.Calls))
+	}
+	sort.Stable(byReceiverAndMethod(r.Groups))
+	return r
+}
+
+func newMissingCall(c *Call) MissingCall {
+	m := MissingCall{
+		Args:     make([]string, len(c.args)),
+		Name:     c.name,
+		Origin:   c.origin,
+		MinCalls: c.minCalls,
+		MaxCalls: c.maxCalls,
+		NumCalls: c.numCalls,
+	}
+	for i, arg := range c.args {
+		m.Args[i] = arg.String()
+	}
+	if m.MaxCalls >= 1e8 {
+		m.MaxCalls = -1
+	}
+	return m
+}
+
+// String describes the missing calls, a line per group followed by an
+// indented line per call.
+func (r *ExpectationReport) String() string {
+	var lines []string
+	for _, g := range r.Groups {
+		lines = append(lines, fmt.Sprintf("%s.%s:", g.Receiver, g.Method))
+		for _, c := range g.Calls {
+			lines = append(lines, fmt.Sprintf("\t%v", c))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// String describes the call like Call.String does, without the receiver
+// and method, e.g. `(is equal to 1) registered at file.go:12, expected
+// 1..1 calls, got 0`.
+func (c MissingCall) String() string {
+	name := ""
+	if c.Name != "" {
+		name = fmt.Sprintf(" named %q", c.Name)
+	}
+	max := "unlimited"
+	if c.MaxCalls >= 0 {
+		max = strconv.Itoa(c.MaxCalls)
+	}
+	return fmt.Sprintf("(%s)%s registered at %s, expected %d..%s calls, got %d",
+		strings.Join(c.Args, ", "), name, c.Origin, c.MinCalls, max, c.NumCalls)
+}
+
+type byReceiverAndMethod []MissingCallGroup
+
+func (gs byReceiverAndMethod) Len() int      { return len(gs) }
+func (gs byReceiverAndMethod) Swap(i, j int) { gs[i], gs[j] = gs[j], gs[i] }
+func (gs byReceiverAndMethod) Less(i, j int) bool {
+	if gs[i].Receiver != gs[j].Receiver {
+		return gs[i].Receiver < gs[j].Receiver
+	}
+	if gs[i].Method != gs[j].Method {
+		return gs[i].Method < gs[j].Method
+	}
+	return originLess(gs[i].Calls[0].Origin, gs[j].Calls[0].Origin)
+}
+
+// byReportOrder sorts calls the way an ExpectationReport does: by
+// receiver, method and origin.
+type byReportOrder []*Call
+
+func (cs byReportOrder) Len() int      { return len(cs) }
+func (cs byReportOrder) Swap(i, j int) { cs[i], cs[j] = cs[j], cs[i] }
+func (cs byReportOrder) Less(i, j int) bool {
+	if ri, rj := cs[i].receiverName(), cs[j].receiverName(); ri != rj {
+		return ri < rj
+	}
+	if cs[i].method != cs[j].method {
+		return cs[i].method < cs[j].method
+	}
+	return originLess(cs[i].origin, cs[j].origin)
+}
+
+type byOrigin []MissingCall
+
+func (cs byOrigin) Len() int           { return len(cs) }
+func (cs byOrigin) Swap(i, j int)      { cs[i], cs[j] = cs[j], cs[i] }
+func (cs byOrigin) Less(i, j int) bool { return originLess(cs[i].Origin, cs[j].Origin) }
+
+// originLess orders origins by file, then by line number.
+func originLess(a, b string) bool {
+	af, al := splitOrigin(a)
+	bf, bl := splitOrigin(b)
+	if af != bf {
+		return af < bf
+	}
+	return al < bl
+}
+
+// splitOrigin splits an origin into its file and line number.
+func splitOrigin(origin string) (string, int) {
+	i := strings.LastIndexByte(origin, ':')
+	if i < 0 {
+		return origin, 0
+	}
+	line, err := strconv.Atoi(origin[i+1:])
+	if err != nil {
+		return origin, 0
+	}
+	return origin[:i], line
+}
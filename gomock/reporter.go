@@ -0,0 +1,175 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomock
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// An ErrorReporter is a TestReporter which collects the failures reported
+// to it as errors, to use gomock outside of go test, where there is no
+// *testing.T. Its Fatalf panics to stop the operation which failed; use
+// Controller.CallErr, Controller.FinishErr or Run to stop the panic and get
+// the failures as an error:
+//
+//	r := gomock.NewErrorReporter()
+//	ctrl := gomock.NewController(r)
+//	m := NewMockFoo(ctrl)
+//	m.EXPECT().Get("a").Return(1)
+//	if err := r.Run(func() { verify(m) }); err != nil {
+//		...
+//	}
+//	if err := ctrl.FinishErr(); err != nil {
+//		...
+//	}
+//
+// It is safe to use from multiple goroutines.
+type ErrorReporter struct {
+	mu     sync.Mutex
+	errors []error
+}
+
+// NewErrorReporter returns a new ErrorReporter.
+func NewErrorReporter() *ErrorReporter {
+	return &ErrorReporter{}
+}
+
+// Errorf records a failure.
+func (r *ErrorReporter) Errorf(format string, args ...interface{}) {
+	r.record(format, args...)
+}
+
+// Fatalf records a failure, and panics to stop the operation which failed.
+func (r *ErrorReporter) Fatalf(format string, args ...interface{}) {
+	panic(fatalFailure{r.record(format, args...)})
+}
+
+// Helper does nothing. It makes ErrorReporter a TestHelper.
+func (r *ErrorReporter) Helper() {}
+
+func (r *ErrorReporter) record(format string, args ...interface{}) error {
+	err := fmt.Errorf(format, args...)
+	r.mu.Lock()
+	r.errors = append(r.errors, err)
+	r.mu.Unlock()
+	return err
+}
+
+// Errors returns the failures recorded so far, oldest first.
+func (r *ErrorReporter) Errors() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]error(nil), r.errors...)
+}
+
+// Err returns an error aggregating the failures recorded so far, one per
+// line, or nil if there is none.
+func (r *ErrorReporter) Err() error {
+	if errs := r.Errors(); len(errs) != 0 {
+		return reportedErrors(errs)
+	}
+	return nil
+}
+
+// Run calls f, stopping the panic of Fatalf if a failure stops it, and
+// returns an error aggregating the failures recorded meanwhile, or nil if
+// there is none. The failures are still recorded.
+func (r *ErrorReporter) Run(f func()) error {
+	r.mu.Lock()
+	n := len(r.errors)
+	r.mu.Unlock()
+
+	recoverFatal(f)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.errors) == n {
+		return nil
+	}
+	return append(reportedErrors(nil), r.errors[n:]...)
+}
+
+// reportedErrors is the error aggregating the failures of an ErrorReporter.
+type reportedErrors []error
+
+func (es reportedErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, err := range es {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// fatalFailure is the value the Fatalf of ErrorReporter panics with.
+type fatalFailure struct {
+	err error
+}
+
+// recoverFatal calls f, stopping the panic of the Fatalf of an
+// ErrorReporter, and returns the failure which stopped f, if any. Other
+// panics pass through.
+func recoverFatal(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			ff, ok := r.(fatalFailure)
+			if !ok {
+				panic(r)
+			}
+			err = ff.err
+		}
+	}()
+	f()
+	return nil
+}
+
+// CallErr is like Call, for mocks of a controller reporting to an
+// ErrorReporter: if the call fails the test with Fatalf, it returns the
+// failure instead of panicking. Failures reported with Errorf, like those of
+// calls made from goroutines other than the test's, are only recorded.
+func (ctrl *Controller) CallErr(receiver interface{}, method string, args ...interface{}) (rets []interface{}, err error) {
+	ctrl.t.Helper()
+
+	err = recoverFatal(func() {
+		rets = ctrl.Call(receiver, method, args...)
+	})
+	return rets, err
+}
+
+// FinishErr is like Finish, for a controller reporting to an ErrorReporter:
+// it returns an error aggregating all of the failures recorded by the
+// reporter, including those of Finish, or nil if there is none, instead of
+// panicking.
+func (ctrl *Controller) FinishErr() error {
+	ctrl.t.Helper()
+
+	err := recoverFatal(ctrl.Finish)
+	if r, ok := ctrl.reporter().(*ErrorReporter); ok {
+		return r.Err()
+	}
+	return err
+}
+
+// reporter returns the TestReporter the controller was created with.
+func (ctrl *Controller) reporter() TestReporter {
+	if r, ok := ctrl.t.(fatalExiter); ok {
+		if h, ok := r.TestHelper.(nopTestHelper); ok {
+			return h.TestReporter
+		}
+		return r.TestHelper
+	}
+	return ctrl.t
+}
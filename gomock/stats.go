@@ -0,0 +1,130 @@
+// Copyright 2026 Google Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gomock
+
+import (
+	"fmt"
+	"time"
+)
+
+// ControllerStats counts the expected calls recorded with a controller and
+// the calls made to its mocks.
+type ControllerStats struct {
+	Recorded uint64 // the expected calls recorded
+	Matched  uint64 // the calls which matched an expected call
+	// Failures counts the calls which matched no expected call, and the
+	// expected calls Finish reported missing.
+	Failures uint64
+	// Methods has the counts for each method, by receiver and method, e.g.
+	// "*mock_foo.MockFoo.Get".
+	Methods map[string]MethodStats
+}
+
+// MethodStats counts the expected calls and the calls of a method.
+type MethodStats struct {
+	Recorded uint64
+	Matched  uint64
+	Failures uint64
+}
+
+// Stats returns the counts of the expected calls recorded so far and of
+// the calls made.
+func (ctrl *Controller) Stats() ControllerStats {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+
+	stats := ControllerStats{
+		Recorded: ctrl.stats.total.Recorded,
+		Matched:  ctrl.stats.total.Matched,
+		Failures: ctrl.stats.total.Failures,
+		Methods:  make(map[string]MethodStats, len(ctrl.stats.methods)),
+	}
+	for key, s := range ctrl.stats.methods {
+		// Receivers with the same name are counted together.
+		name := ctrl.receiverName(key.receiver) + "." + key.fname
+		m := stats.Methods[name]
+		m.Recorded += s.Recorded
+		m.Matched += s.Matched
+		m.Failures += s.Failures
+		stats.Methods[name] = m
+	}
+	return stats
+}
+
+// callStats collects the ControllerStats of a controller, under its lock.
+type callStats struct {
+	total   MethodStats
+	methods map[callSetKey]*MethodStats
+}
+
+func (s *callStats) of(receiver interface{}, method string) *MethodStats {
+	key := callSetKey{receiver, method}
+	m := s.methods[key]
+	if m == nil {
+		if s.methods == nil {
+			s.methods = make(map[callSetKey]*MethodStats)
+		}
+		m = &MethodStats{}
+		s.methods[key] = m
+	}
+	return m
+}
+
+func (s *callStats) recorded(receiver interface{}, method string) {
+	s.total.Recorded++
+	s.of(receiver, method).Recorded++
+}
+
+func (s *callStats) matched(receiver interface{}, method string) {
+	s.total.Matched++
+	s.of(receiver, method).Matched++
+}
+
+func (s *callStats) failed(receiver interface{}, method string) {
+	s.total.Failures++
+	s.of(receiver, method).Failures++
+}
+
+// CallInfo describes a call which matched an expected call, for the
+// observer set with WithCallObserver.
+type CallInfo struct {
+	Receiver string // the type of the receiver, e.g. *mock_foo.MockFoo
+	Method   string
+	// Duration is how long the actions of the expected call took.
+	Duration time.Duration
+	// Seq is the number of the call among the calls matched by the
+	// controller, starting at 1.
+	Seq uint64
+}
+
+// WithCallObserver makes the controller call observe after each call which
+// matched an expected call, once its actions are done. It is called
+// without the controller's lock held, from the goroutine which made the
+// call.
+func WithCallObserver(observe func(CallInfo)) ControllerOption {
+	return optionFunc(func(ctrl *Controller) {
+		ctrl.callObserver = observe
+	})
+}
+
+// observeCall passes the call to the observer set with WithCallObserver.
+func (ctrl *Controller) observeCall(receiver interface{}, method string, start time.Time, seq uint64) {
+	ctrl.callObserver(CallInfo{
+		Receiver: fmt.Sprintf("%T", receiver),
+		Method:   method,
+		Duration: time.Since(start),
+		Seq:      seq,
+	})
+}
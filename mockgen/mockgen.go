@@ -22,12 +22,15 @@ import (
 	"bytes"
 	"flag"
 	"fmt"
+	"go/build"
 	"go/format"
 	"go/token"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"path"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -45,26 +48,66 @@ var (
 	destination     = flag.String("destination", "", "Output file; defaults to stdout.")
 	mockNames       = flag.String("mock_names", "", "Comma-separated interfaceName=mockName pairs of explicit mock names to use. Mock names default to 'Mock'+ interfaceName suffix.")
 	packageOut      = flag.String("package", "", "Package of the generated code; defaults to the package of the input with a 'mock_' prefix.")
-	selfPackage     = flag.String("self_package", "", "If set, the package this mock will be part of.")
+	selfPackage     = flag.String("self_package", "", "The full import path of the package the mocks will be part of; defaults to that of the -destination directory, if it can be determined and the package there has the name set by -package.")
 	writePkgComment = flag.Bool("write_package_comment", true, "Writes package documentation comment (godoc) if true.")
+	typed           = flag.Bool("typed", false, "Generate typed wrappers of *gomock.Call, with Return, Do and DoAndReturn taking the types of each method, for the recorders to return.")
+	copyrightFile   = flag.String("copyright_file", "", "File whose contents are written as a comment at the top of the generated code, such as a copyright notice.")
 
 	debugParser = flag.Bool("debug_parser", false, "Print out parser results only.")
 )
 
+var (
+	destinationPerInterface = flag.String("destination_per_interface", "", "Comma-separated interfaceName=file pairs of output files for some of the interfaces; the others are written to -destination.")
+	all                     = flag.Bool("all", false, "Mock every exported interface of the package, in reflect mode, or of the source file, in source mode.")
+	includeUnexported       = flag.Bool("include_unexported", false, "(source mode) With -all, mock unexported interfaces too.")
+)
+
 func main() {
 	flag.Usage = usage
 	flag.Parse()
 
+	if *includeUnexported && !*all {
+		log.Fatal("-include_unexported requires -all")
+	}
+
 	var pkg *model.Package
 	var err error
 	if *source != "" {
-		pkg, err = ParseFile(*source)
+		var names []string
+		switch {
+		case *all && *ifaces != "":
+			log.Fatal("-all and -interfaces can't be combined")
+		case *all:
+			names, err = fileInterfaces(*source, *includeUnexported)
+			if err == nil && len(names) == 0 {
+				err = fmt.Errorf("no interfaces to mock in %s", *source)
+			}
+		case *ifaces != "":
+			names = strings.Split(*ifaces, ",")
+		}
+		if err == nil {
+			pkg, err = ParseFile(*source, names)
+		}
 	} else {
-		if flag.NArg() != 2 {
+		var symbols []string
+		switch {
+		case *all && flag.NArg() == 1:
+			if *includeUnexported {
+				log.Fatal("-include_unexported requires source mode, as unexported interfaces can't be reflected on")
+			}
+			symbols, err = packageInterfaces(flag.Arg(0))
+			if err == nil && len(symbols) == 0 {
+				err = fmt.Errorf("no interfaces to mock in %s", flag.Arg(0))
+			}
+		case !*all && flag.NArg() == 2:
+			symbols = strings.Split(flag.Arg(1), ",")
+		default:
 			usage()
-			log.Fatal("Expected exactly two arguments")
+			log.Fatal("Expected exactly two arguments, or one with -all")
+		}
+		if err == nil {
+			pkg, err = Reflect(flag.Arg(0), symbols)
 		}
-		pkg, err = Reflect(flag.Arg(0), strings.Split(flag.Arg(1), ","))
 	}
 	if err != nil {
 		log.Fatalf("Loading input failed: %v", err)
@@ -75,16 +118,6 @@ func main() {
 		return
 	}
 
-	dst := os.Stdout
-	if len(*destination) > 0 {
-		f, err := os.Create(*destination)
-		if err != nil {
-			log.Fatalf("Failed opening destination file: %v", err)
-		}
-		defer f.Close()
-		dst = f
-	}
-
 	packageName := *packageOut
 	if packageName == "" {
 		// pkg.Name in reflect mode is the base name of the import path,
@@ -92,34 +125,199 @@ func main() {
 		packageName = "mock_" + sanitize(pkg.Name)
 	}
 
-	g := new(generator)
-	if *source != "" {
-		g.filename = *source
-	} else {
-		g.srcPackage = flag.Arg(0)
-		g.srcInterfaces = flag.Arg(1)
+	perInterface, err := parseDestinations(*destinationPerInterface)
+	if err != nil {
+		log.Fatalf("Bad -destination_per_interface: %v", err)
+	}
+	outputs, err := splitByDestination(pkg, *destination, perInterface)
+	if err != nil {
+		log.Fatalf("Bad -destination_per_interface: %v", err)
 	}
 
+	importAliases, err := parseImportAliases(*imports)
+	if err != nil {
+		log.Fatalf("Bad -imports: %v", err)
+	}
+	var copyrightHeader string
+	if *copyrightFile != "" {
+		header, err := ioutil.ReadFile(*copyrightFile)
+		if err != nil {
+			log.Fatalf("Failed reading copyright file: %v", err)
+		}
+		copyrightHeader = string(header)
+	}
+	var names map[string]string
 	if *mockNames != "" {
-		g.mockNames = parseMockNames(*mockNames)
+		names, err = parseMockNames(*mockNames)
+		if err != nil {
+			log.Fatalf("Bad -mock_names: %v", err)
+		}
 	}
-	if err := g.Generate(pkg, packageName); err != nil {
+	// Each output only knows of its own interfaces, so the mock names are
+	// checked against all of them first.
+	if err := (&generator{mockNames: names}).checkMockNames(pkg); err != nil {
 		log.Fatalf("Failed generating mock: %v", err)
 	}
-	if _, err := dst.Write(g.Output()); err != nil {
-		log.Fatalf("Failed writing to destination: %v", err)
+
+	newGenerator := func(out output) *generator {
+		g := new(generator)
+		if *source != "" {
+			g.filename = *source
+		} else {
+			g.srcPackage = flag.Arg(0)
+			g.srcInterfaces = strings.Join(out.interfaceNames(), ",")
+		}
+		g.selfPackage = *selfPackage
+		if g.selfPackage == "" && out.destination != "" {
+			// Mocks written into the package of the interfaces must not import it.
+			dir, err := filepath.Abs(filepath.Dir(out.destination))
+			if err != nil {
+				log.Fatalf("Failed getting destination directory: %v", err)
+			}
+			g.selfPackage = inferSelfPackage(dir, packageName)
+		}
+		g.typed = *typed
+		g.importAliases = importAliases
+		g.copyrightHeader = copyrightHeader
+		g.mockNames = make(map[string]string)
+		for _, name := range out.interfaceNames() {
+			if mockName, ok := names[name]; ok {
+				g.mockNames[name] = mockName
+			}
+		}
+		return g
+	}
+	if err := writeOutputs(outputs, packageName, newGenerator, os.Stdout); err != nil {
+		log.Fatalf("Failed generating mock: %v", err)
 	}
 }
-func parseMockNames(names string) map[string]string {
+
+// An output is a file the mocks of some of the interfaces are written to.
+type output struct {
+	destination string // empty for stdout
+	pkg         *model.Package
+}
+
+func (out output) interfaceNames() []string {
+	names := make([]string, len(out.pkg.Interfaces))
+	for i, intf := range out.pkg.Interfaces {
+		names[i] = intf.Name
+	}
+	return names
+}
+
+// parseDestinations parses the interfaceName=file pairs of
+// -destination_per_interface into a map.
+func parseDestinations(spec string) (map[string]string, error) {
+	destinations := make(map[string]string)
+	if spec == "" {
+		return destinations, nil
+	}
+	for _, kv := range strings.Split(spec, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("bad destination spec: %v", kv)
+		}
+		if _, ok := destinations[parts[0]]; ok {
+			return nil, fmt.Errorf("interface %s is given two destinations", parts[0])
+		}
+		destinations[parts[0]] = parts[1]
+	}
+	return destinations, nil
+}
+
+// splitByDestination returns the outputs the interfaces of pkg are written
+// to: that of perInterface for those it names, and destination for the
+// others. The outputs are ordered by their first interface.
+func splitByDestination(pkg *model.Package, destination string, perInterface map[string]string) ([]output, error) {
+	known := make(map[string]bool, len(pkg.Interfaces))
+	for _, intf := range pkg.Interfaces {
+		known[intf.Name] = true
+	}
+	names := make([]string, 0, len(perInterface))
+	for name := range perInterface {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !known[name] {
+			return nil, fmt.Errorf("no interface %s to write to %s", name, perInterface[name])
+		}
+	}
+
+	var outputs []output
+	index := make(map[string]int)
+	for _, intf := range pkg.Interfaces {
+		dst := destination
+		if d, ok := perInterface[intf.Name]; ok {
+			dst = filepath.Clean(d)
+		} else if dst != "" {
+			dst = filepath.Clean(dst)
+		}
+		i, ok := index[dst]
+		if !ok {
+			i = len(outputs)
+			index[dst] = i
+			outputs = append(outputs, output{
+				destination: dst,
+				pkg:         &model.Package{Name: pkg.Name, DotImports: pkg.DotImports},
+			})
+		}
+		outputs[i].pkg.Interfaces = append(outputs[i].pkg.Interfaces, intf)
+	}
+	return outputs, nil
+}
+
+// writeOutputs generates the mocks of each output into package packageName,
+// with a generator made for it by newGenerator, and writes them to its
+// destination, or to stdout if it has none.
+func writeOutputs(outputs []output, packageName string, newGenerator func(output) *generator, stdout io.Writer) error {
+	for _, out := range outputs {
+		g := newGenerator(out)
+		if err := g.Generate(out.pkg, packageName); err != nil {
+			return err
+		}
+		if out.destination == "" {
+			if _, err := stdout.Write(g.Output()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := ioutil.WriteFile(out.destination, g.Output(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inferSelfPackage returns the import path of the package in dir if it is
+// named packageName, i.e. if mocks of that package written into dir are part
+// of it, and "" otherwise. An external test package, named like the package
+// with _test appended, is another package, which must import it.
+func inferSelfPackage(dir, packageName string) string {
+	bp, err := build.ImportDir(dir, 0)
+	if err != nil || bp.Name != packageName || build.IsLocalImport(bp.ImportPath) {
+		return ""
+	}
+	return bp.ImportPath
+}
+
+func parseMockNames(names string) (map[string]string, error) {
 	mocksMap := make(map[string]string)
 	for _, kv := range strings.Split(names, ",") {
 		parts := strings.SplitN(kv, "=", 2)
-		if len(parts) != 2 || parts[1] == "" {
-			log.Fatalf("bad mock names spec: %v", kv)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("bad mock names spec: %v", kv)
+		}
+		if !token.IsIdentifier(parts[1]) {
+			return nil, fmt.Errorf("mock name %q for %s is not a Go identifier", parts[1], parts[0])
+		}
+		if _, ok := mocksMap[parts[0]]; ok {
+			return nil, fmt.Errorf("interface %s is named twice", parts[0])
 		}
 		mocksMap[parts[0]] = parts[1]
 	}
-	return mocksMap
+	return mocksMap, nil
 }
 
 func usage() {
@@ -150,6 +348,10 @@ type generator struct {
 	mockNames                 map[string]string //may be empty
 	filename                  string            // may be empty
 	srcPackage, srcInterfaces string            // may be empty
+	selfPackage               string            // may be empty; import path of the package of the mocks
+	copyrightHeader           string            // may be empty
+	importAliases             map[string]string // may be empty; import path => explicit local name
+	typed                     bool              // whether recorders return typed call wrappers
 
 	packageMap map[string]string // map from import path to package name
 }
@@ -168,13 +370,6 @@ func (g *generator) out() {
 	}
 }
 
-func removeDot(s string) string {
-	if len(s) > 0 && s[len(s)-1] == '.' {
-		return s[0 : len(s)-1]
-	}
-	return s
-}
-
 // sanitize cleans up a string to make a suitable package name.
 func sanitize(s string) string {
 	t := ""
@@ -199,6 +394,16 @@ func sanitize(s string) string {
 }
 
 func (g *generator) Generate(pkg *model.Package, pkgName string) error {
+	if err := g.checkMockNames(pkg); err != nil {
+		return err
+	}
+
+	if header := commentLines(g.copyrightHeader); len(header) > 0 {
+		for _, line := range header {
+			g.p("%s", line)
+		}
+		g.p("")
+	}
 	g.p("// Code generated by MockGen. DO NOT EDIT.")
 	if g.filename != "" {
 		g.p("// Source: %v", g.filename)
@@ -211,35 +416,13 @@ func (g *generator) Generate(pkg *model.Package, pkgName string) error {
 	im := pkg.Imports()
 	im[gomockImportPath] = true
 	im["reflect"] = true
+	// Types of the package the mocks are part of are not qualified.
+	delete(im, g.selfPackage)
 
-	// Sort keys to make import alias generation predictable
-	sorted_paths := make([]string, len(im), len(im))
-	x := 0
-	for pth := range im {
-		sorted_paths[x] = pth
-		x++
-	}
-	sort.Strings(sorted_paths)
-
-	g.packageMap = make(map[string]string, len(im))
-	localNames := make(map[string]bool, len(im))
-	for _, pth := range sorted_paths {
-		base := sanitize(path.Base(pth))
-
-		// Local names for an imported package can usually be the basename of the import path.
-		// A couple of situations don't permit that, such as duplicate local names
-		// (e.g. importing "html/template" and "text/template"), or where the basename is
-		// a keyword (e.g. "foo/case").
-		// try base0, base1, ...
-		pkgName := base
-		i := 0
-		for localNames[pkgName] || token.Lookup(pkgName).IsKeyword() {
-			pkgName = base + strconv.Itoa(i)
-			i++
-		}
-
-		g.packageMap[pth] = pkgName
-		localNames[pkgName] = true
+	var err error
+	g.packageMap, err = importAliases(im, g.importAliases, pkgName)
+	if err != nil {
+		return err
 	}
 
 	if *writePkgComment {
@@ -250,9 +433,6 @@ func (g *generator) Generate(pkg *model.Package, pkgName string) error {
 	g.p("import (")
 	g.in()
 	for path, pkg := range g.packageMap {
-		if path == *selfPackage {
-			continue
-		}
 		g.p("%v %q", pkg, path)
 	}
 	for _, path := range pkg.DotImports {
@@ -270,6 +450,151 @@ func (g *generator) Generate(pkg *model.Package, pkgName string) error {
 	return nil
 }
 
+// predeclared are the predeclared identifiers, which generated code must
+// not shadow.
+var predeclared = map[string]bool{
+	"any": true, "bool": true, "byte": true, "comparable": true,
+	"complex64": true, "complex128": true, "error": true, "float32": true,
+	"float64": true, "int": true, "int8": true, "int16": true, "int32": true,
+	"int64": true, "rune": true, "string": true, "uint": true, "uint8": true,
+	"uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"true": true, "false": true, "iota": true, "nil": true,
+	"append": true, "cap": true, "clear": true, "close": true, "complex": true,
+	"copy": true, "delete": true, "imag": true, "len": true, "make": true,
+	"max": true, "min": true, "new": true, "panic": true, "print": true,
+	"println": true, "real": true, "recover": true,
+}
+
+// importAliases returns a map from import path to the local name used for
+// each of the imports in im. Paths given an alias in explicit get that alias.
+// The others get the base name of the path, suffixed with 0, 1, ... if it is
+// taken, a keyword or predeclared identifier, or the name pkgName of the
+// package being generated. The names of gomock and reflect are assigned
+// first, and then those of the other paths in sorted order, so the names
+// don't change between runs.
+func importAliases(im map[string]bool, explicit map[string]string, pkgName string) (map[string]string, error) {
+	paths := make([]string, 0, len(im))
+	for pth := range im {
+		paths = append(paths, pth)
+	}
+	sort.Strings(paths)
+
+	aliases := make(map[string]string, len(paths))
+	localNames := map[string]bool{pkgName: true}
+	for _, pth := range paths {
+		alias, ok := explicit[pth]
+		if !ok {
+			continue
+		}
+		if localNames[alias] {
+			return nil, fmt.Errorf("import name %s of %s is already in use", alias, pth)
+		}
+		aliases[pth] = alias
+		localNames[alias] = true
+	}
+
+	for _, pth := range append([]string{gomockImportPath, "reflect"}, paths...) {
+		if _, ok := aliases[pth]; ok || !im[pth] {
+			continue
+		}
+		base := sanitize(path.Base(pth))
+
+		// Local names for an imported package can usually be the basename of the import path.
+		// A couple of situations don't permit that, such as duplicate local names
+		// (e.g. importing "html/template" and "text/template"), or where the basename is
+		// a keyword (e.g. "foo/case") or predeclared identifier (e.g. "foo/string").
+		// try base0, base1, ...
+		alias := base
+		i := 0
+		for localNames[alias] || token.Lookup(alias).IsKeyword() || predeclared[alias] {
+			alias = base + strconv.Itoa(i)
+			i++
+		}
+
+		aliases[pth] = alias
+		localNames[alias] = true
+	}
+	return aliases, nil
+}
+
+// parseImportAliases parses the name=path pairs of -imports into a map from
+// import path to name. Dot imports are left out.
+func parseImportAliases(spec string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	if spec == "" {
+		return aliases, nil
+	}
+	for _, kv := range strings.Split(spec, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("bad imports spec: %v", kv)
+		}
+		name, pth := parts[0], parts[1]
+		if name == "." {
+			continue
+		}
+		if !token.IsIdentifier(name) || predeclared[name] {
+			return nil, fmt.Errorf("import name %q for %s can't be used", name, pth)
+		}
+		if other, ok := aliases[pth]; ok && other != name {
+			return nil, fmt.Errorf("%s is imported as both %s and %s", pth, other, name)
+		}
+		aliases[pth] = name
+	}
+	return aliases, nil
+}
+
+// commentLines formats text as line comments. Lines that already are line
+// comments are kept, and blank lines at the start and end are dropped.
+func commentLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n") {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		switch {
+		case strings.HasPrefix(strings.TrimSpace(line), "//"):
+			line = strings.TrimSpace(line)
+		case line == "":
+			line = "//"
+		default:
+			line = "// " + line
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && lines[0] == "//" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "//" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// checkMockNames checks that the mock names refer to interfaces of pkg, and
+// that no two of its interfaces get the same mock.
+func (g *generator) checkMockNames(pkg *model.Package) error {
+	intfs := make(map[string]bool, len(pkg.Interfaces))
+	mocks := make(map[string]string, len(pkg.Interfaces))
+	for _, intf := range pkg.Interfaces {
+		intfs[intf.Name] = true
+		mockType := g.mockName(intf.Name)
+		if other, ok := mocks[mockType]; ok {
+			return fmt.Errorf("interfaces %s and %s would both be mocked as %s", other, intf.Name, mockType)
+		}
+		mocks[mockType] = intf.Name
+	}
+	names := make([]string, 0, len(g.mockNames))
+	for name := range g.mockNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !intfs[name] {
+			return fmt.Errorf("mock name given for unknown interface %s", name)
+		}
+	}
+	return nil
+}
+
 // The name of the mock type to use for the given interface identifier.
 func (g *generator) mockName(typeName string) string {
 	if mockName, ok := g.mockNames[typeName]; ok {
@@ -279,23 +604,40 @@ func (g *generator) mockName(typeName string) string {
 	return "Mock" + typeName
 }
 
+// typeParams returns the type parameter list of a mock of intf and the type
+// arguments that instantiate it with those parameters, such as
+// "[T any, K comparable]" and "[T, K]". Both are empty unless intf is generic.
+func (g *generator) typeParams(intf *model.Interface, pkgOverride string) (string, string) {
+	if len(intf.TypeParams) == 0 {
+		return "", ""
+	}
+	params := make([]string, len(intf.TypeParams))
+	args := make([]string, len(intf.TypeParams))
+	for i, tp := range intf.TypeParams {
+		params[i] = tp.Name + " " + tp.Type.String(g.packageMap, pkgOverride)
+		args[i] = tp.Name
+	}
+	return "[" + strings.Join(params, ", ") + "]", "[" + strings.Join(args, ", ") + "]"
+}
+
 func (g *generator) GenerateMockInterface(intf *model.Interface) error {
 	mockType := g.mockName(intf.Name)
+	typeParams, typeArgs := g.typeParams(intf, g.selfPackage)
 
 	g.p("")
 	g.p("// %v is a mock of %v interface", mockType, intf.Name)
-	g.p("type %v struct {", mockType)
+	g.p("type %v%v struct {", mockType, typeParams)
 	g.in()
-	g.p("ctrl     *gomock.Controller")
-	g.p("recorder *%vMockRecorder", mockType)
+	g.p("ctrl     *%v.Controller", g.packageMap[gomockImportPath])
+	g.p("recorder *%vMockRecorder%v", mockType, typeArgs)
 	g.out()
 	g.p("}")
 	g.p("")
 
 	g.p("// %vMockRecorder is the mock recorder for %v", mockType, mockType)
-	g.p("type %vMockRecorder struct {", mockType)
+	g.p("type %vMockRecorder%v struct {", mockType, typeParams)
 	g.in()
-	g.p("mock *%v", mockType)
+	g.p("mock *%v%v", mockType, typeArgs)
 	g.out()
 	g.p("}")
 	g.p("")
@@ -306,10 +648,10 @@ func (g *generator) GenerateMockInterface(intf *model.Interface) error {
 	//g.p("")
 
 	g.p("// New%v creates a new mock instance", mockType)
-	g.p("func New%v(ctrl *gomock.Controller) *%v {", mockType, mockType)
+	g.p("func New%v%v(ctrl *%v.Controller) *%v%v {", mockType, typeParams, g.packageMap[gomockImportPath], mockType, typeArgs)
 	g.in()
-	g.p("mock := &%v{ctrl: ctrl}", mockType)
-	g.p("mock.recorder = &%vMockRecorder{mock}", mockType)
+	g.p("mock := &%v%v{ctrl: ctrl}", mockType, typeArgs)
+	g.p("mock.recorder = &%vMockRecorder%v{mock}", mockType, typeArgs)
 	g.p("return mock")
 	g.out()
 	g.p("}")
@@ -317,24 +659,41 @@ func (g *generator) GenerateMockInterface(intf *model.Interface) error {
 
 	// XXX: possible name collision here if someone has EXPECT in their interface.
 	g.p("// EXPECT returns an object that allows the caller to indicate expected use")
-	g.p("func (m *%v) EXPECT() *%vMockRecorder {", mockType, mockType)
+	g.p("func (m *%v%v) EXPECT() *%vMockRecorder%v {", mockType, typeArgs, mockType, typeArgs)
 	g.in()
 	g.p("return m.recorder")
 	g.out()
 	g.p("}")
 
-	g.GenerateMockMethods(mockType, intf, *selfPackage)
+	g.GenerateMockMethods(mockType, intf, g.selfPackage)
 
 	return nil
 }
 
 func (g *generator) GenerateMockMethods(mockType string, intf *model.Interface, pkgOverride string) {
+	typeParams, typeArgs := g.typeParams(intf, pkgOverride)
 	for _, m := range intf.Methods {
 		g.p("")
-		g.GenerateMockMethod(mockType, m, pkgOverride)
+		g.GenerateMockMethod(mockType, typeArgs, m, pkgOverride)
 		g.p("")
-		g.GenerateMockRecorderMethod(mockType, m)
+		g.GenerateMockRecorderMethod(mockType, typeArgs, m)
+		if g.typed {
+			g.p("")
+			g.GenerateMockCallWrapper(mockType, typeParams, typeArgs, m, pkgOverride)
+		}
+	}
+}
+
+// resultsString returns the result list of a function returning rets,
+// including the space it is separated from the parameters with.
+func resultsString(rets []string) string {
+	switch len(rets) {
+	case 0:
+		return ""
+	case 1:
+		return " " + rets[0]
 	}
+	return " (" + strings.Join(rets, ", ") + ")"
 }
 
 func makeArgString(argNames, argTypes []string) string {
@@ -351,8 +710,9 @@ func makeArgString(argNames, argTypes []string) string {
 }
 
 // GenerateMockMethod generates a mock method implementation.
+// If non-empty, typeArgs instantiates a generic mock type with its type parameters.
 // If non-empty, pkgOverride is the package in which unqualified types reside.
-func (g *generator) GenerateMockMethod(mockType string, m *model.Method, pkgOverride string) error {
+func (g *generator) GenerateMockMethod(mockType, typeArgs string, m *model.Method, pkgOverride string) error {
 	argNames := g.getArgNames(m)
 	argTypes := g.getArgTypes(m, pkgOverride)
 	argString := makeArgString(argNames, argTypes)
@@ -361,19 +721,13 @@ func (g *generator) GenerateMockMethod(mockType string, m *model.Method, pkgOver
 	for i, p := range m.Out {
 		rets[i] = p.Type.String(g.packageMap, pkgOverride)
 	}
-	retString := strings.Join(rets, ", ")
-	if len(rets) > 1 {
-		retString = "(" + retString + ")"
-	}
-	if retString != "" {
-		retString = " " + retString
-	}
+	retString := resultsString(rets)
 
 	ia := newIdentifierAllocator(argNames)
 	idRecv := ia.allocateIdentifier("m")
 
 	g.p("// %v mocks base method", m.Name)
-	g.p("func (%v *%v) %v(%v)%v {", idRecv, mockType, m.Name, argString, retString)
+	g.p("func (%v *%v%v) %v(%v)%v {", idRecv, mockType, typeArgs, m.Name, argString, retString)
 	g.in()
 
 	var callArgs string
@@ -416,7 +770,7 @@ func (g *generator) GenerateMockMethod(mockType string, m *model.Method, pkgOver
 	return nil
 }
 
-func (g *generator) GenerateMockRecorderMethod(mockType string, m *model.Method) error {
+func (g *generator) GenerateMockRecorderMethod(mockType, typeArgs string, m *model.Method) error {
 	argNames := g.getArgNames(m)
 
 	var argString string
@@ -439,8 +793,13 @@ func (g *generator) GenerateMockRecorderMethod(mockType string, m *model.Method)
 	ia := newIdentifierAllocator(argNames)
 	idRecv := ia.allocateIdentifier("mr")
 
+	retType := g.packageMap[gomockImportPath] + ".Call"
+	if g.typed {
+		retType = callWrapperName(mockType, m) + typeArgs
+	}
+
 	g.p("// %v indicates an expected call of %v", m.Name, m.Name)
-	g.p("func (%s *%vMockRecorder) %v(%v) *gomock.Call {", idRecv, mockType, m.Name, argString)
+	g.p("func (%s *%vMockRecorder%v) %v(%v) *%v {", idRecv, mockType, typeArgs, m.Name, argString, retType)
 	g.in()
 
 	var callArgs string
@@ -462,8 +821,80 @@ func (g *generator) GenerateMockRecorderMethod(mockType string, m *model.Method)
 			callArgs = ", " + idVarArgs + "..."
 		}
 	}
-	g.p(`return %s.mock.ctrl.RecordCallWithMethodType(%s.mock, "%s", reflect.TypeOf((*%s)(nil).%s)%s)`, idRecv, idRecv, m.Name, mockType, m.Name, callArgs)
+	record := fmt.Sprintf(`%s.mock.ctrl.RecordCallWithMethodType(%s.mock, "%s", %s.TypeOf((*%s%s)(nil).%s)%s)`, idRecv, idRecv, m.Name, g.packageMap["reflect"], mockType, typeArgs, m.Name, callArgs)
+	if g.typed {
+		idCall := ia.allocateIdentifier("call")
+		g.p("%s := %s", idCall, record)
+		g.p("return &%s%s{Call: %s}", callWrapperName(mockType, m), typeArgs, idCall)
+	} else {
+		g.p("return %s", record)
+	}
+
+	g.out()
+	g.p("}")
+	return nil
+}
+
+// callWrapperName returns the name of the typed wrapper of *gomock.Call for
+// the method m of mockType.
+func callWrapperName(mockType string, m *model.Method) string {
+	return mockType + m.Name + "Call"
+}
+
+// GenerateMockCallWrapper generates the typed wrapper of *gomock.Call which
+// the recorder method of m returns in typed mode, with Return, Do and
+// DoAndReturn methods taking the types of m.
+func (g *generator) GenerateMockCallWrapper(mockType, typeParams, typeArgs string, m *model.Method, pkgOverride string) error {
+	wrapper := callWrapperName(mockType, m)
+	gomockName := g.packageMap[gomockImportPath]
 
+	argTypes := g.getArgTypes(m, pkgOverride)
+	rets := make([]string, len(m.Out))
+	for i, p := range m.Out {
+		rets[i] = p.Type.String(g.packageMap, pkgOverride)
+	}
+	retString := resultsString(rets)
+	argsFunc := "func(" + strings.Join(argTypes, ", ") + ")"
+
+	retNames := make([]string, len(rets))
+	for i := range rets {
+		retNames[i] = fmt.Sprintf("arg%d", i)
+	}
+	ia := newIdentifierAllocator(retNames)
+	idRecv := ia.allocateIdentifier("c")
+	idFunc := ia.allocateIdentifier("f")
+
+	g.p("// %v wraps *gomock.Call with the types of %v", wrapper, m.Name)
+	g.p("type %v%v struct {", wrapper, typeParams)
+	g.in()
+	g.p("*%v.Call", gomockName)
+	g.out()
+	g.p("}")
+	g.p("")
+
+	g.p("// Return declares the values to be returned by %v", m.Name)
+	g.p("func (%v *%v%v) Return(%v) *%v%v {", idRecv, wrapper, typeArgs, makeArgString(retNames, rets), wrapper, typeArgs)
+	g.in()
+	g.p("%v.Call = %v.Call.Return(%v)", idRecv, idRecv, strings.Join(retNames, ", "))
+	g.p("return %v", idRecv)
+	g.out()
+	g.p("}")
+	g.p("")
+
+	g.p("// Do declares the action to run when %v is called", m.Name)
+	g.p("func (%v *%v%v) Do(%v %v) *%v%v {", idRecv, wrapper, typeArgs, idFunc, argsFunc, wrapper, typeArgs)
+	g.in()
+	g.p("%v.Call = %v.Call.Do(%v)", idRecv, idRecv, idFunc)
+	g.p("return %v", idRecv)
+	g.out()
+	g.p("}")
+	g.p("")
+
+	g.p("// DoAndReturn declares the action to run when %v is called, returning its results", m.Name)
+	g.p("func (%v *%v%v) DoAndReturn(%v %v%v) *%v%v {", idRecv, wrapper, typeArgs, idFunc, argsFunc, retString, wrapper, typeArgs)
+	g.in()
+	g.p("%v.Call = %v.Call.DoAndReturn(%v)", idRecv, idRecv, idFunc)
+	g.p("return %v", idRecv)
 	g.out()
 	g.p("}")
 	return nil
@@ -1,8 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/golang/mock/mockgen/model"
 )
 
 func TestMakeArgString(t *testing.T) {
@@ -179,3 +186,436 @@ func TestIdentifierAllocator_allocateIdentifier(t *testing.T) {
 		t.Fatalf("allocator doesn't contain the expected items - allocator: %#v, expected items: %#v", a, expected)
 	}
 }
+
+// checkGolden generates the mocks of the interfaces in source into package
+// pkgName with g, as `mockgen -source` run in its directory does, and
+// compares them with golden.
+func checkGolden(t *testing.T, g *generator, pkgName, source, golden string) {
+	t.Helper()
+	pkg, err := ParseFile(source, nil)
+	if err != nil {
+		t.Fatalf("ParseFile(%q): %v", source, err)
+	}
+	g.filename = filepath.Base(source)
+	if err := g.Generate(pkg, pkgName); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("reading golden file: %v", err)
+	}
+	if got := g.Output(); string(got) != string(want) {
+		t.Errorf("generated mocks differ from %s; got\n%s", golden, got)
+	}
+}
+
+func TestGenerateGenerics(t *testing.T) {
+	checkGolden(t, new(generator), "mock_generics", "tests/generics/generics.go", "tests/generics/mock_generics/generics_mock.go")
+}
+
+func TestGenerateEmbedded(t *testing.T) {
+	checkGolden(t, new(generator), "mock_embedded_interface", "tests/embedded_interface/embedded.go", "tests/embedded_interface/mock_embedded_interface/embedded_mock.go")
+}
+
+func TestGenerateSelfPackage(t *testing.T) {
+	g := &generator{selfPackage: "github.com/golang/mock/mockgen/tests/self_package"}
+	checkGolden(t, g, "self_package", "tests/self_package/self_package.go", "tests/self_package/self_package_mock.go")
+}
+
+func TestInferSelfPackage(t *testing.T) {
+	dir, err := filepath.Abs("tests/self_package")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const path = "github.com/golang/mock/mockgen/tests/self_package"
+	if got := inferSelfPackage(dir, "self_package"); got != path {
+		t.Errorf("inferSelfPackage for the package itself = %q, want %q", got, path)
+	}
+	if got := inferSelfPackage(dir, "self_package_test"); got != "" {
+		t.Errorf("inferSelfPackage for its external test package = %q, want none", got)
+	}
+
+	// Mocks in the external test package import the package of the types.
+	g := &generator{selfPackage: inferSelfPackage(dir, "self_package_test")}
+	pkg, err := ParseFile("tests/self_package/self_package.go", nil)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if err := g.Generate(pkg, "self_package_test"); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	out := string(g.Output())
+	for _, want := range []string{
+		"package self_package_test\n",
+		`self_package "` + path + `"`,
+		"(*self_package.Record, error)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("the mocks of the external test package don't contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestParseMockNames(t *testing.T) {
+	testCases := []struct {
+		names string
+		want  map[string]string
+		err   string
+	}{
+		{
+			names: "Client=MockPaymentsClient",
+			want:  map[string]string{"Client": "MockPaymentsClient"},
+		},
+		{
+			names: "Client=MockPaymentsClient,Server=FakeServer",
+			want:  map[string]string{"Client": "MockPaymentsClient", "Server": "FakeServer"},
+		},
+		{names: "Client", err: "bad mock names spec: Client"},
+		{names: "Client=", err: "bad mock names spec: Client="},
+		{names: "=MockClient", err: "bad mock names spec: =MockClient"},
+		{names: "Client=Mock-Client", err: `mock name "Mock-Client" for Client is not a Go identifier`},
+		{names: "Client=A,Client=B", err: "interface Client is named twice"},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			got, err := parseMockNames(tc.names)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("parseMockNames(%q) error = %v, want %q", tc.names, err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMockNames(%q): %v", tc.names, err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("parseMockNames(%q) = %v, want %v", tc.names, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGenerateMockNames(t *testing.T) {
+	pkg := &model.Package{
+		Name: "clients",
+		Interfaces: []*model.Interface{
+			{Name: "Client", Methods: []*model.Method{{Name: "Pay"}}},
+			{Name: "Other", Methods: []*model.Method{{Name: "Order"}}},
+		},
+	}
+
+	g := &generator{filename: "clients.go", mockNames: map[string]string{"Client": "MockPaymentsClient"}}
+	if err := g.Generate(pkg, "mock_clients"); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	out := string(g.Output())
+	for _, want := range []string{
+		"type MockPaymentsClient struct",
+		"recorder *MockPaymentsClientMockRecorder",
+		"type MockPaymentsClientMockRecorder struct",
+		"func NewMockPaymentsClient(ctrl *gomock.Controller) *MockPaymentsClient {",
+		"func (m *MockPaymentsClient) EXPECT() *MockPaymentsClientMockRecorder {",
+		"func (m *MockPaymentsClient) Pay() {",
+		"func (mr *MockPaymentsClientMockRecorder) Pay() *gomock.Call {",
+		"type MockOther struct",
+		"func NewMockOther(ctrl *gomock.Controller) *MockOther {",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("generated code lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "MockClient") {
+		t.Errorf("generated code still uses the default mock name:\n%s", out)
+	}
+
+	for _, tc := range []struct {
+		mockNames map[string]string
+		err       string
+	}{
+		{map[string]string{"Missing": "MockMissing"}, "mock name given for unknown interface Missing"},
+		{map[string]string{"Client": "MockOther"}, "interfaces Client and Other would both be mocked as MockOther"},
+		{map[string]string{"Client": "MockX", "Other": "MockX"}, "interfaces Client and Other would both be mocked as MockX"},
+	} {
+		g := &generator{filename: "clients.go", mockNames: tc.mockNames}
+		if err := g.Generate(pkg, "mock_clients"); err == nil || err.Error() != tc.err {
+			t.Errorf("Generate with mock names %v: error = %v, want %q", tc.mockNames, err, tc.err)
+		}
+	}
+}
+
+func TestCommentLines(t *testing.T) {
+	testCases := []struct {
+		text string
+		want []string
+	}{
+		{text: "", want: nil},
+		{text: "\n  \n", want: nil},
+		{text: "Copyright 2018", want: []string{"// Copyright 2018"}},
+		{text: "\r\nA  \r\n\r\n  B\r\n", want: []string{"// A", "//", "//   B"}},
+		{text: "// A\n  //B\nC\n", want: []string{"// A", "//B", "// C"}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			if got := commentLines(tc.text); fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tc.want) {
+				t.Errorf("commentLines(%q) = %q, want %q", tc.text, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGenerateHeader(t *testing.T) {
+	license, err := ioutil.ReadFile("tests/copyright_file/LICENSE.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(write bool) { *writePkgComment = write }(*writePkgComment)
+
+	testCases := []struct {
+		pkgName         string
+		copyrightHeader string
+		writePkgComment bool
+	}{
+		{"mock_copyright", string(license), true},
+		{"mock_no_package_comment", "", false},
+		{"mock_both", string(license), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pkgName, func(t *testing.T) {
+			*writePkgComment = tc.writePkgComment
+			g := &generator{copyrightHeader: tc.copyrightHeader}
+			checkGolden(t, g, tc.pkgName, "tests/copyright_file/copyright_file.go", "tests/copyright_file/"+tc.pkgName+"/copyright_file_mock.go")
+		})
+	}
+}
+
+func TestImportAliases(t *testing.T) {
+	testCases := []struct {
+		name     string
+		paths    []string
+		explicit map[string]string
+		pkgName  string
+		want     map[string]string
+	}{
+		{
+			name:    "base names",
+			paths:   []string{"io", "net/http"},
+			pkgName: "mock_foo",
+			want:    map[string]string{"io": "io", "net/http": "http"},
+		},
+		{
+			name:    "same base name in path order",
+			paths:   []string{"c/types", "a/types", "b/types"},
+			pkgName: "mock_foo",
+			want:    map[string]string{"a/types": "types", "b/types": "types0", "c/types": "types1"},
+		},
+		{
+			name:    "keywords and predeclared identifiers",
+			paths:   []string{"foo/case", "foo/string", "foo/len"},
+			pkgName: "mock_foo",
+			want:    map[string]string{"foo/case": "case0", "foo/string": "string0", "foo/len": "len0"},
+		},
+		{
+			name:    "gomock and reflect first",
+			paths:   []string{gomockImportPath, "reflect", "a/gomock", "a/reflect"},
+			pkgName: "mock_foo",
+			want:    map[string]string{gomockImportPath: "gomock", "reflect": "reflect", "a/gomock": "gomock0", "a/reflect": "reflect0"},
+		},
+		{
+			name:    "package name",
+			paths:   []string{gomockImportPath, "a/types"},
+			pkgName: "gomock",
+			want:    map[string]string{gomockImportPath: "gomock0", "a/types": "types"},
+		},
+		{
+			name:     "explicit",
+			paths:    []string{"a/types", "b/types"},
+			explicit: map[string]string{"b/types": "types", "unused/path": "unused"},
+			pkgName:  "mock_foo",
+			want:     map[string]string{"a/types": "types0", "b/types": "types"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			im := make(map[string]bool)
+			for _, pth := range tc.paths {
+				im[pth] = true
+			}
+			got, err := importAliases(im, tc.explicit, tc.pkgName)
+			if err != nil {
+				t.Fatalf("importAliases: %v", err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("importAliases = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	im := map[string]bool{"a/types": true, "b/types": true}
+	if _, err := importAliases(im, map[string]string{"a/types": "t", "b/types": "t"}, "mock_foo"); err == nil || err.Error() != "import name t of b/types is already in use" {
+		t.Errorf("importAliases with a duplicate explicit name: err = %v", err)
+	}
+}
+
+func TestParseImportAliases(t *testing.T) {
+	got, err := parseImportAliases("t=a/types,.=b/dot,u=c/types")
+	if err != nil {
+		t.Fatalf("parseImportAliases: %v", err)
+	}
+	if want := map[string]string{"a/types": "t", "c/types": "u"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("parseImportAliases = %v, want %v", got, want)
+	}
+
+	for spec, want := range map[string]string{
+		"a/types":             "bad imports spec: a/types",
+		"string=a/types":      `import name "string" for a/types can't be used`,
+		"t=a/types,u=a/types": "a/types is imported as both t and u",
+	} {
+		if _, err := parseImportAliases(spec); err == nil || err.Error() != want {
+			t.Errorf("parseImportAliases(%q): err = %v, want %q", spec, err, want)
+		}
+	}
+}
+
+func TestGenerateImportAliases(t *testing.T) {
+	checkGolden(t, new(generator), "mock_import_aliases", "tests/import_aliases/import_aliases.go", "tests/import_aliases/mock_import_aliases/import_aliases_mock.go")
+
+	// Mocks generated into a package named gomock refer to the real one by another name.
+	pkg := &model.Package{
+		Name:       "clients",
+		Interfaces: []*model.Interface{{Name: "Client", Methods: []*model.Method{{Name: "Pay"}}}},
+	}
+	g := &generator{filename: "clients.go"}
+	if err := g.Generate(pkg, "gomock"); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	out := string(g.Output())
+	for _, want := range []string{
+		"package gomock\n",
+		`gomock0 "github.com/golang/mock/gomock"`,
+		"ctrl     *gomock0.Controller",
+		"func NewMockClient(ctrl *gomock0.Controller) *MockClient {",
+		"func (mr *MockClientMockRecorder) Pay() *gomock0.Call {",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("generated code lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestGenerateTyped(t *testing.T) {
+	checkGolden(t, &generator{typed: true}, "mock_typed", "tests/typed/typed.go", "tests/typed/mock_typed/typed_mock.go")
+}
+
+func TestParseDestinations(t *testing.T) {
+	got, err := parseDestinations("Iface1=foo_mock.go,Iface2=bar/bar_mock.go")
+	if err != nil {
+		t.Fatalf("parseDestinations: %v", err)
+	}
+	if want := map[string]string{"Iface1": "foo_mock.go", "Iface2": "bar/bar_mock.go"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("parseDestinations = %v, want %v", got, want)
+	}
+
+	for spec, want := range map[string]string{
+		"Iface1":                  "bad destination spec: Iface1",
+		"Iface1=":                 "bad destination spec: Iface1=",
+		"Iface1=a.go,Iface1=b.go": "interface Iface1 is given two destinations",
+	} {
+		if _, err := parseDestinations(spec); err == nil || err.Error() != want {
+			t.Errorf("parseDestinations(%q): err = %v, want %q", spec, err, want)
+		}
+	}
+}
+
+func TestSplitByDestination(t *testing.T) {
+	pkg := &model.Package{Name: "p", Interfaces: []*model.Interface{{Name: "A"}, {Name: "B"}, {Name: "C"}, {Name: "D"}}}
+
+	outputs, err := splitByDestination(pkg, "mocks.go", map[string]string{"B": "b_mock.go", "D": "./b_mock.go"})
+	if err != nil {
+		t.Fatalf("splitByDestination: %v", err)
+	}
+	var got []string
+	for _, out := range outputs {
+		got = append(got, out.destination+"="+strings.Join(out.interfaceNames(), ","))
+	}
+	if want := []string{"mocks.go=A,C", "b_mock.go=B,D"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("outputs = %v, want %v", got, want)
+	}
+
+	if _, err := splitByDestination(pkg, "", map[string]string{"E": "e_mock.go"}); err == nil || err.Error() != "no interface E to write to e_mock.go" {
+		t.Errorf("splitByDestination with an unknown interface: err = %v", err)
+	}
+}
+
+func TestWriteOutputs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mockgen_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pkg := &model.Package{Name: "p", Interfaces: []*model.Interface{
+		{Name: "A", Methods: []*model.Method{{Name: "Foo"}}},
+		{Name: "B", Methods: []*model.Method{{Name: "Bar"}}},
+		{Name: "C", Methods: []*model.Method{{Name: "Baz"}}},
+	}}
+	outputs, err := splitByDestination(pkg, "", map[string]string{"A": filepath.Join(dir, "a_mock.go"), "C": filepath.Join(dir, "c_mock.go")})
+	if err != nil {
+		t.Fatalf("splitByDestination: %v", err)
+	}
+	var stdout bytes.Buffer
+	newGenerator := func(out output) *generator {
+		return &generator{srcPackage: "p", srcInterfaces: strings.Join(out.interfaceNames(), ",")}
+	}
+	if err := writeOutputs(outputs, "mock_p", newGenerator, &stdout); err != nil {
+		t.Fatalf("writeOutputs: %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"a_mock.go": "MockA",
+		"c_mock.go": "MockC",
+		"":          "MockB",
+	} {
+		var got string
+		if name == "" {
+			got = stdout.String()
+		} else {
+			b, err := ioutil.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = string(b)
+		}
+		if !strings.Contains(got, "type "+want+" struct") || strings.Count(got, "MockRecorder struct") != 1 {
+			t.Errorf("output %q should only hold %s:\n%s", name, want, got)
+		}
+	}
+}
+
+func TestInterfaceDiscovery(t *testing.T) {
+	names, err := packageInterfaces("github.com/golang/mock/mockgen/tests/all_interfaces")
+	if err != nil {
+		t.Fatalf("packageInterfaces: %v", err)
+	}
+	if got, want := strings.Join(names, ","), "Alpha,Delta,Epsilon"; got != want {
+		t.Errorf("packageInterfaces = %s, want %s", got, want)
+	}
+
+	for _, tc := range []struct {
+		includeUnexported bool
+		want              string
+	}{
+		{false, "Alpha,Gamma"},
+		{true, "Alpha,beta,Gamma"},
+	} {
+		names, err := fileInterfaces("tests/all_interfaces/all_interfaces.go", tc.includeUnexported)
+		if err != nil {
+			t.Fatalf("fileInterfaces: %v", err)
+		}
+		if got := strings.Join(names, ","); got != tc.want {
+			t.Errorf("fileInterfaces(includeUnexported=%v) = %s, want %s", tc.includeUnexported, got, tc.want)
+		}
+	}
+}
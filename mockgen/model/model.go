@@ -51,18 +51,28 @@ func (pkg *Package) Imports() map[string]bool {
 
 // Interface is a Go interface.
 type Interface struct {
-	Name    string
-	Methods []*Method
+	Name       string
+	TypeParams []*Parameter // may be empty; the Type of each is its constraint
+	Methods    []*Method
 }
 
 func (intf *Interface) Print(w io.Writer) {
 	fmt.Fprintf(w, "interface %s\n", intf.Name)
+	if len(intf.TypeParams) > 0 {
+		fmt.Fprintf(w, "    type parameters:\n")
+		for _, p := range intf.TypeParams {
+			p.Print(w)
+		}
+	}
 	for _, m := range intf.Methods {
 		m.Print(w)
 	}
 }
 
 func (intf *Interface) addImports(im map[string]bool) {
+	for _, p := range intf.TypeParams {
+		p.Type.addImports(im)
+	}
 	for _, m := range intf.Methods {
 		m.addImports(im)
 	}
@@ -134,6 +144,8 @@ func init() {
 	gob.Register(&MapType{})
 	gob.Register(&NamedType{})
 	gob.Register(&PointerType{})
+	gob.Register(&TildeType{})
+	gob.Register(&UnionType{})
 
 	// Call gob.RegisterName to make sure it has the consistent name registered
 	// for both gob decoder and encoder.
@@ -243,21 +255,34 @@ func (mt *MapType) addImports(im map[string]bool) {
 
 // NamedType is an exported type in a package.
 type NamedType struct {
-	Package string // may be empty
-	Type    string // TODO: should this be typed Type?
+	Package  string // may be empty
+	Type     string // TODO: should this be typed Type?
+	TypeArgs []Type // may be empty; set for instantiations of generic types
 }
 
 func (nt *NamedType) String(pm map[string]string, pkgOverride string) string {
-	// TODO: is this right?
-	if pkgOverride == nt.Package {
-		return nt.Type
+	s := nt.Type
+	// A type of an unknown package is assumed to be a type of the package
+	// the mocks are part of.
+	if nt.Package != "" && pkgOverride != nt.Package {
+		s = pm[nt.Package] + "." + s
+	}
+	if len(nt.TypeArgs) > 0 {
+		args := make([]string, len(nt.TypeArgs))
+		for i, t := range nt.TypeArgs {
+			args[i] = t.String(pm, pkgOverride)
+		}
+		s += "[" + strings.Join(args, ", ") + "]"
 	}
-	return pm[nt.Package] + "." + nt.Type
+	return s
 }
 func (nt *NamedType) addImports(im map[string]bool) {
 	if nt.Package != "" {
 		im[nt.Package] = true
 	}
+	for _, t := range nt.TypeArgs {
+		t.addImports(im)
+	}
 }
 
 // PointerType is a pointer to another type.
@@ -276,6 +301,35 @@ type PredeclaredType string
 func (pt PredeclaredType) String(pm map[string]string, pkgOverride string) string { return string(pt) }
 func (pt PredeclaredType) addImports(im map[string]bool)                          {}
 
+// TildeType is the set of types whose underlying type is Type, as used in
+// type constraints.
+type TildeType struct {
+	Type Type
+}
+
+func (tt *TildeType) String(pm map[string]string, pkgOverride string) string {
+	return "~" + tt.Type.String(pm, pkgOverride)
+}
+func (tt *TildeType) addImports(im map[string]bool) { tt.Type.addImports(im) }
+
+// UnionType is a union of types, as used in type constraints.
+type UnionType struct {
+	Terms []Type
+}
+
+func (ut *UnionType) String(pm map[string]string, pkgOverride string) string {
+	terms := make([]string, len(ut.Terms))
+	for i, t := range ut.Terms {
+		terms[i] = t.String(pm, pkgOverride)
+	}
+	return strings.Join(terms, " | ")
+}
+func (ut *UnionType) addImports(im map[string]bool) {
+	for _, t := range ut.Terms {
+		t.addImports(im)
+	}
+}
+
 // The following code is intended to be called by the program generated by ../reflect.go.
 
 func InterfaceFromInterfaceType(it reflect.Type) (*Interface, error) {
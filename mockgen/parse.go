@@ -33,13 +33,17 @@ import (
 )
 
 var (
-	imports  = flag.String("imports", "", "(source mode) Comma-separated name=path pairs of explicit imports to use.")
+	imports  = flag.String("imports", "", "Comma-separated name=path pairs of explicit imports to use; in source mode, they also resolve the package names of the source file.")
 	auxFiles = flag.String("aux_files", "", "(source mode) Comma-separated pkg=path pairs of auxiliary Go source files.")
+	ifaces   = flag.String("interfaces", "", "(source mode) Comma-separated names of the interfaces to mock; defaults to all interfaces in the source file.")
 )
 
 // TODO: simplify error reporting
 
-func ParseFile(source string) (*model.Package, error) {
+// ParseFile builds the model of the interfaces declared in the source file
+// without compiling it. If names is not empty, only the named interfaces
+// are included, in the order given.
+func ParseFile(source string, names []string) (*model.Package, error) {
 	srcDir, err := filepath.Abs(filepath.Dir(source))
 	if err != nil {
 		return nil, fmt.Errorf("failed getting source directory: %v", err)
@@ -53,20 +57,20 @@ func ParseFile(source string) (*model.Package, error) {
 	p := &fileParser{
 		fileSet:            fs,
 		imports:            make(map[string]string),
-		importedInterfaces: make(map[string]map[string]*ast.InterfaceType),
-		auxInterfaces:      make(map[string]map[string]*ast.InterfaceType),
+		importedInterfaces: make(map[string]map[string]*namedInterface),
+		auxInterfaces:      make(map[string]map[string]*namedInterface),
+		dotImportedTypes:   make(map[string]map[string]bool),
 		srcDir:             srcDir,
+		srcPkg:             importPathOfDir(srcDir),
 	}
 
 	// Handle -imports.
-	dotImports := make(map[string]bool)
 	if *imports != "" {
 		for _, kv := range strings.Split(*imports, ",") {
 			eq := strings.Index(kv, "=")
 			k, v := kv[:eq], kv[eq+1:]
 			if k == "." {
-				// TODO: Catch dupes?
-				dotImports[v] = true
+				p.addDotImport(v)
 			} else {
 				// TODO: Catch dupes?
 				p.imports[k] = v
@@ -78,28 +82,86 @@ func ParseFile(source string) (*model.Package, error) {
 	if err := p.parseAuxFiles(*auxFiles); err != nil {
 		return nil, err
 	}
-	p.addAuxInterfacesFromFile("", file) // this file
+	p.addAuxInterfacesFromFile(p.srcPkg, file) // this file
+
+	return p.parseFile(file, names)
+}
 
-	pkg, err := p.parseFile(file)
+// fileInterfaces returns the names of the exported interfaces declared in
+// the source file, and of the unexported ones too if includeUnexported is
+// set, for -all in source mode.
+func fileInterfaces(source string, includeUnexported bool) ([]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), source, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing source file %v: %v", source, err)
+	}
+	return interfaceNames([]*ast.File{file}, includeUnexported, true), nil
+}
+
+// packageInterfaces returns the names of the exported interfaces declared in
+// the package importPath, for -all in reflect mode. Generic interfaces are
+// left out, as they can't be reflected on.
+func packageInterfaces(importPath string) ([]string, error) {
+	bp, err := build.Import(importPath, ".", 0)
 	if err != nil {
 		return nil, err
 	}
-	pkg.DotImports = make([]string, 0, len(dotImports))
-	for path := range dotImports {
-		pkg.DotImports = append(pkg.DotImports, path)
+	fs := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range bp.GoFiles {
+		file, err := parser.ParseFile(fs, filepath.Join(bp.Dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return interfaceNames(files, false, false), nil
+}
+
+// interfaceNames returns the names of the interfaces declared in files, in
+// the order of their declarations. Unexported and generic interfaces are only
+// included if includeUnexported and includeGeneric are set.
+func interfaceNames(files []*ast.File, includeUnexported, includeGeneric bool) []string {
+	var names []string
+	for _, file := range files {
+		for ni := range iterInterfaces(file) {
+			if !ni.name.IsExported() && !includeUnexported {
+				continue
+			}
+			if ni.typeParams != nil && !includeGeneric {
+				continue
+			}
+			names = append(names, ni.name.Name)
+		}
+	}
+	return names
+}
+
+// importPathOfDir returns the import path of the package in dir, or the
+// empty string if dir is not in a GOPATH workspace.
+func importPathOfDir(dir string) string {
+	bp, err := build.ImportDir(dir, build.FindOnly)
+	if err != nil || build.IsLocalImport(bp.ImportPath) {
+		return ""
 	}
-	return pkg, nil
+	return bp.ImportPath
 }
 
 type fileParser struct {
 	fileSet            *token.FileSet
-	imports            map[string]string                        // package name => import path
-	importedInterfaces map[string]map[string]*ast.InterfaceType // package (or "") => name => interface
+	imports            map[string]string                     // package name => import path
+	importedInterfaces map[string]map[string]*namedInterface // package (or "") => name => interface
 
 	auxFiles      []*ast.File
-	auxInterfaces map[string]map[string]*ast.InterfaceType // package (or "") => name => interface
+	auxInterfaces map[string]map[string]*namedInterface // package (or "") => name => interface
+
+	dotImports       []string                   // import paths, in order of appearance
+	dotImportedTypes map[string]map[string]bool // import path => exported type names; nil if it failed to load
 
 	srcDir string
+	srcPkg string // import path of the source package; may be empty
+
+	typeParams map[string]model.Type // type parameters in scope => their types
 }
 
 func (p *fileParser) errorf(pos token.Pos, format string, args ...interface{}) error {
@@ -131,61 +193,158 @@ func (p *fileParser) parseAuxFiles(auxFiles string) error {
 
 func (p *fileParser) addAuxInterfacesFromFile(pkg string, file *ast.File) {
 	if _, ok := p.auxInterfaces[pkg]; !ok {
-		p.auxInterfaces[pkg] = make(map[string]*ast.InterfaceType)
+		p.auxInterfaces[pkg] = make(map[string]*namedInterface)
 	}
 	for ni := range iterInterfaces(file) {
-		p.auxInterfaces[pkg][ni.name.Name] = ni.it
+		p.auxInterfaces[pkg][ni.name.Name] = ni
 	}
 }
 
-func (p *fileParser) parseFile(file *ast.File) (*model.Package, error) {
-	allImports := importsOfFile(file)
+func (p *fileParser) parseFile(file *ast.File, names []string) (*model.Package, error) {
+	allImports, dotImports := importsOfFile(file)
 	// Don't stomp imports provided by -imports. Those should take precedence.
 	for pkg, path := range allImports {
 		if _, ok := p.imports[pkg]; !ok {
 			p.imports[pkg] = path
 		}
 	}
+	for _, path := range dotImports {
+		p.addDotImport(path)
+	}
 	// Add imports from auxiliary files, which might be needed for embedded interfaces.
 	// Don't stomp any other imports.
 	for _, f := range p.auxFiles {
-		for pkg, path := range importsOfFile(f) {
+		fileImports, _ := importsOfFile(f)
+		for pkg, path := range fileImports {
 			if _, ok := p.imports[pkg]; !ok {
 				p.imports[pkg] = path
 			}
 		}
 	}
 
-	var is []*model.Interface
+	var nis []*namedInterface
 	for ni := range iterInterfaces(file) {
-		i, err := p.parseInterface(ni.name.String(), "", ni.it)
+		nis = append(nis, ni)
+	}
+	if len(names) > 0 {
+		byName := make(map[string]*namedInterface, len(nis))
+		for _, ni := range nis {
+			byName[ni.name.Name] = ni
+		}
+		nis = nis[:0]
+		for _, name := range names {
+			ni, ok := byName[name]
+			if !ok {
+				return nil, fmt.Errorf("interface %s not found in %s", name, p.fileSet.Position(file.Pos()).Filename)
+			}
+			nis = append(nis, ni)
+		}
+	}
+
+	var is []*model.Interface
+	for _, ni := range nis {
+		i, err := p.parseInterface(ni.name.String(), p.srcPkg, ni, nil)
 		if err != nil {
 			return nil, err
 		}
 		is = append(is, i)
 	}
+
+	// Types from dot imports that could be loaded have been qualified with
+	// their package; the rest still need to be dot-imported by the mocks.
+	var unresolved []string
+	for _, path := range p.dotImports {
+		if p.dotImportedTypes[path] == nil {
+			unresolved = append(unresolved, path)
+		}
+	}
 	return &model.Package{
 		Name:       file.Name.String(),
 		Interfaces: is,
+		DotImports: unresolved,
 	}, nil
 }
 
+func (p *fileParser) addDotImport(path string) {
+	if _, ok := p.dotImportedTypes[path]; ok {
+		return
+	}
+	p.dotImports = append(p.dotImports, path)
+	p.dotImportedTypes[path] = p.exportedTypes(path)
+}
+
+// exportedTypes returns the names of the exported types declared by the
+// package with the given import path, or nil if it can't be parsed.
+func (p *fileParser) exportedTypes(path string) map[string]bool {
+	imp, err := build.Import(path, p.srcDir, build.FindOnly)
+	if err != nil {
+		return nil
+	}
+	pkgs, err := parser.ParseDir(p.fileSet, imp.Dir, nil, 0)
+	if err != nil {
+		return nil
+	}
+	types := make(map[string]bool)
+	for name, pkg := range pkgs {
+		if strings.HasSuffix(name, "_test") {
+			continue
+		}
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gd, ok := decl.(*ast.GenDecl)
+				if !ok || gd.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gd.Specs {
+					if ts := spec.(*ast.TypeSpec); ts.Name.IsExported() {
+						types[ts.Name.Name] = true
+					}
+				}
+			}
+		}
+	}
+	return types
+}
+
+// dotImportedPackage returns the import path of the dot import that
+// declares the named type, if any.
+func (p *fileParser) dotImportedPackage(name string) (string, bool) {
+	for _, path := range p.dotImports {
+		if p.dotImportedTypes[path][name] {
+			return path, true
+		}
+	}
+	return "", false
+}
+
 func (p *fileParser) parsePackage(path string) error {
-	var pkgs map[string]*ast.Package
-	if imp, err := build.Import(path, p.srcDir, build.FindOnly); err != nil {
+	imp, err := build.Import(path, p.srcDir, build.FindOnly)
+	if err != nil {
 		return err
-	} else if pkgs, err = parser.ParseDir(p.fileSet, imp.Dir, nil, 0); err != nil {
+	}
+	return p.parseDir(path, imp.Dir)
+}
+
+// parseDir records the interfaces of the package in dir under path, which
+// is empty for a source package outside of a GOPATH workspace.
+func (p *fileParser) parseDir(path, dir string) error {
+	pkgs, err := parser.ParseDir(p.fileSet, dir, nil, 0)
+	if err != nil {
 		return err
 	}
-	for _, pkg := range pkgs {
-		file := ast.MergePackageFiles(pkg, ast.FilterFuncDuplicates|ast.FilterUnassociatedComments|ast.FilterImportDuplicates)
-		if _, ok := p.importedInterfaces[path]; !ok {
-			p.importedInterfaces[path] = make(map[string]*ast.InterfaceType)
+	if _, ok := p.importedInterfaces[path]; !ok {
+		p.importedInterfaces[path] = make(map[string]*namedInterface)
+	}
+	for name, pkg := range pkgs {
+		if strings.HasSuffix(name, "_test") {
+			continue
 		}
+		file := ast.MergePackageFiles(pkg, ast.FilterFuncDuplicates|ast.FilterUnassociatedComments|ast.FilterImportDuplicates)
 		for ni := range iterInterfaces(file) {
-			p.importedInterfaces[path][ni.name.Name] = ni.it
+			p.importedInterfaces[path][ni.name.Name] = ni
 		}
-		for pkgName, pkgPath := range importsOfFile(file) {
+		fileImports, _ := importsOfFile(file)
+		for pkgName, pkgPath := range fileImports {
 			if _, ok := p.imports[pkgName]; !ok {
 				p.imports[pkgName] = pkgPath
 			}
@@ -194,9 +353,59 @@ func (p *fileParser) parsePackage(path string) error {
 	return nil
 }
 
-func (p *fileParser) parseInterface(name, pkg string, it *ast.InterfaceType) (*model.Interface, error) {
+// parseInterface parses the interface ni of package pkg. The type parameters
+// of a generic interface are declared by it unless typeArgs are given, in
+// which case they stand for those types, as when it is embedded.
+func (p *fileParser) parseInterface(name, pkg string, ni *namedInterface, typeArgs []model.Type) (*model.Interface, error) {
 	intf := &model.Interface{Name: name}
-	for _, field := range it.Methods.List {
+
+	outer := p.typeParams
+	defer func() { p.typeParams = outer }()
+	p.typeParams = make(map[string]model.Type)
+	if ni.typeParams != nil {
+		var params []*model.Parameter
+		for _, field := range ni.typeParams.List {
+			for _, n := range field.Names {
+				params = append(params, &model.Parameter{Name: n.Name})
+			}
+		}
+		if typeArgs == nil {
+			for _, tp := range params {
+				p.typeParams[tp.Name] = model.PredeclaredType(tp.Name)
+			}
+			// Constraints may refer to any of the type parameters.
+			i := 0
+			for _, field := range ni.typeParams.List {
+				t, err := p.parseType(pkg, field.Type)
+				if err != nil {
+					return nil, err
+				}
+				for range field.Names {
+					params[i].Type = t
+					i++
+				}
+			}
+			intf.TypeParams = params
+		} else {
+			if len(typeArgs) != len(params) {
+				return nil, p.errorf(ni.name.Pos(), "%s has %d type parameters, got %d type arguments", name, len(params), len(typeArgs))
+			}
+			for i, tp := range params {
+				p.typeParams[tp.Name] = typeArgs[i]
+			}
+		}
+	}
+
+	// Methods reachable through several embedded interfaces are only added
+	// once, as they must have the same signature.
+	seen := make(map[string]bool)
+	addMethod := func(m *model.Method) {
+		if !seen[m.Name] {
+			seen[m.Name] = true
+			intf.Methods = append(intf.Methods, m)
+		}
+	}
+	for _, field := range ni.it.Methods.List {
 		switch v := field.Type.(type) {
 		case *ast.FuncType:
 			if nn := len(field.Names); nn != 1 {
@@ -210,57 +419,130 @@ func (p *fileParser) parseInterface(name, pkg string, it *ast.InterfaceType) (*m
 			if err != nil {
 				return nil, err
 			}
-			intf.Methods = append(intf.Methods, m)
-		case *ast.Ident:
-			// Embedded interface in this package.
-			ei := p.auxInterfaces[pkg][v.String()]
-			if ei == nil {
-				if ei = p.importedInterfaces[pkg][v.String()]; ei == nil {
-					return nil, p.errorf(v.Pos(), "unknown embedded interface %s", v.String())
-				}
-			}
-			eintf, err := p.parseInterface(v.String(), pkg, ei)
+			addMethod(m)
+		default:
+			eintf, err := p.parseEmbeddedInterface(pkg, field.Type)
 			if err != nil {
 				return nil, err
 			}
-			// Copy the methods.
-			// TODO: apply shadowing rules.
 			for _, m := range eintf.Methods {
-				intf.Methods = append(intf.Methods, m)
+				addMethod(m)
 			}
-		case *ast.SelectorExpr:
-			// Embedded interface in another package.
-			fpkg, sel := v.X.(*ast.Ident).String(), v.Sel.String()
-			epkg, ok := p.imports[fpkg]
-			if !ok {
-				return nil, p.errorf(v.X.Pos(), "unknown package %s", fpkg)
+		}
+	}
+	return intf, nil
+}
+
+// parseEmbeddedInterface parses the interface embedded as typ in an interface
+// of package pkg, instantiating it if it is generic.
+func (p *fileParser) parseEmbeddedInterface(pkg string, typ ast.Expr) (*model.Interface, error) {
+	var indices []ast.Expr
+	switch v := typ.(type) {
+	case *ast.IndexExpr:
+		typ, indices = v.X, []ast.Expr{v.Index}
+	case *ast.IndexListExpr:
+		typ, indices = v.X, v.Indices
+	}
+	var typeArgs []model.Type
+	for _, index := range indices {
+		t, err := p.parseType(pkg, index)
+		if err != nil {
+			return nil, err
+		}
+		typeArgs = append(typeArgs, t)
+	}
+
+	switch v := typ.(type) {
+	case *ast.InterfaceType:
+		// Embedded interface literal, such as interface{}.
+		return p.parseInterface("", pkg, &namedInterface{it: v}, nil)
+	case *ast.Ident:
+		// Embedded interface in this package.
+		ei, err := p.localInterface(pkg, v.String())
+		if err != nil {
+			return nil, p.errorf(v.Pos(), "could not parse package %s: %v", pkg, err)
+		}
+		if ei == nil {
+			if intf, ok := predeclaredInterface(v.String()); ok && typeArgs == nil {
+				return intf, nil
 			}
-			ei := p.auxInterfaces[fpkg][sel]
-			if ei == nil {
-				fpkg = epkg
-				if _, ok = p.importedInterfaces[epkg]; !ok {
-					if err := p.parsePackage(epkg); err != nil {
-						return nil, p.errorf(v.Pos(), "could not parse package %s: %v", fpkg, err)
-					}
-				}
-				if ei = p.importedInterfaces[epkg][sel]; ei == nil {
-					return nil, p.errorf(v.Pos(), "unknown embedded interface %s.%s", fpkg, sel)
+			return nil, p.errorf(v.Pos(), "unknown embedded interface %s", v.String())
+		}
+		if err := p.checkTypeArgs(v, ei, typeArgs); err != nil {
+			return nil, err
+		}
+		return p.parseInterface(v.String(), pkg, ei, typeArgs)
+	case *ast.SelectorExpr:
+		// Embedded interface in another package.
+		fpkg, sel := v.X.(*ast.Ident).String(), v.Sel.String()
+		epkg, ok := p.imports[fpkg]
+		if !ok {
+			return nil, p.errorf(v.X.Pos(), "unknown package %s", fpkg)
+		}
+		ei := p.auxInterfaces[fpkg][sel]
+		if ei == nil {
+			fpkg = epkg
+			if _, ok = p.importedInterfaces[epkg]; !ok {
+				if err := p.parsePackage(epkg); err != nil {
+					return nil, p.errorf(v.Pos(), "could not parse package %s: %v", fpkg, err)
 				}
 			}
-			eintf, err := p.parseInterface(sel, fpkg, ei)
-			if err != nil {
-				return nil, err
+			if ei = p.importedInterfaces[epkg][sel]; ei == nil {
+				return nil, p.errorf(v.Pos(), "unknown embedded interface %s.%s", fpkg, sel)
 			}
-			// Copy the methods.
-			// TODO: apply shadowing rules.
-			for _, m := range eintf.Methods {
-				intf.Methods = append(intf.Methods, m)
-			}
-		default:
-			return nil, fmt.Errorf("don't know how to mock method of type %T", field.Type)
 		}
+		if err := p.checkTypeArgs(v, ei, typeArgs); err != nil {
+			return nil, err
+		}
+		return p.parseInterface(sel, fpkg, ei, typeArgs)
 	}
-	return intf, nil
+	return nil, fmt.Errorf("don't know how to mock method of type %T", typ)
+}
+
+// localInterface returns the interface of package pkg with the given name,
+// or nil if there is none. The other files of the source package are only
+// parsed when the interface is not declared in the source or aux files.
+func (p *fileParser) localInterface(pkg, name string) (*namedInterface, error) {
+	if ni := p.auxInterfaces[pkg][name]; ni != nil {
+		return ni, nil
+	}
+	if _, ok := p.importedInterfaces[pkg]; !ok && pkg == p.srcPkg {
+		if err := p.parseDir(pkg, p.srcDir); err != nil {
+			return nil, err
+		}
+	}
+	return p.importedInterfaces[pkg][name], nil
+}
+
+// predeclaredInterface returns the model of the predeclared interface type
+// with the given name.
+func predeclaredInterface(name string) (*model.Interface, bool) {
+	switch name {
+	case "error":
+		return &model.Interface{
+			Name: name,
+			Methods: []*model.Method{{
+				Name: "Error",
+				Out:  []*model.Parameter{{Type: model.PredeclaredType("string")}},
+			}},
+		}, true
+	case "any":
+		return &model.Interface{Name: name}, true
+	}
+	return nil, false
+}
+
+// checkTypeArgs checks that the interface ni, embedded as typ, is given a
+// type argument for each of its type parameters.
+func (p *fileParser) checkTypeArgs(typ ast.Expr, ni *namedInterface, typeArgs []model.Type) error {
+	n := 0
+	if ni.typeParams != nil {
+		n = ni.typeParams.NumFields()
+	}
+	if n != len(typeArgs) {
+		return p.errorf(typ.Pos(), "embedded interface %s has %d type parameters, got %d type arguments", ni.name.Name, n, len(typeArgs))
+	}
+	return nil
 }
 
 func (p *fileParser) parseFunc(pkg string, f *ast.FuncType) (in []*model.Parameter, variadic *model.Parameter, out []*model.Parameter, err error) {
@@ -363,7 +645,16 @@ func (p *fileParser) parseType(pkg string, typ ast.Expr) (model.Type, error) {
 		}
 		return &model.FuncType{In: in, Out: out, Variadic: variadic}, nil
 	case *ast.Ident:
+		if t, ok := p.typeParams[v.Name]; ok {
+			return t, nil
+		}
 		if v.IsExported() {
+			if pkg == p.srcPkg {
+				// The source file can't declare a type it dot-imports.
+				if path, ok := p.dotImportedPackage(v.Name); ok {
+					return &model.NamedType{Package: path, Type: v.Name}, nil
+				}
+			}
 			// `pkg` may be an aliased imported pkg
 			// if so, patch the import w/ the fully qualified import
 			maybeImportedPkg, ok := p.imports[pkg]
@@ -376,6 +667,39 @@ func (p *fileParser) parseType(pkg string, typ ast.Expr) (model.Type, error) {
 			// assume predeclared type
 			return model.PredeclaredType(v.Name), nil
 		}
+	case *ast.IndexExpr:
+		return p.parseGenericType(pkg, v.X, []ast.Expr{v.Index})
+	case *ast.IndexListExpr:
+		return p.parseGenericType(pkg, v.X, v.Indices)
+	case *ast.BinaryExpr:
+		if v.Op != token.OR {
+			break
+		}
+		// A union in a type constraint.
+		x, err := p.parseType(pkg, v.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := p.parseType(pkg, v.Y)
+		if err != nil {
+			return nil, err
+		}
+		var terms []model.Type
+		if u, ok := x.(*model.UnionType); ok {
+			terms = append(terms, u.Terms...)
+		} else {
+			terms = append(terms, x)
+		}
+		return &model.UnionType{Terms: append(terms, y)}, nil
+	case *ast.UnaryExpr:
+		if v.Op != token.TILDE {
+			break
+		}
+		t, err := p.parseType(pkg, v.X)
+		if err != nil {
+			return nil, err
+		}
+		return &model.TildeType{Type: t}, nil
 	case *ast.InterfaceType:
 		if v.Methods != nil && len(v.Methods.List) > 0 {
 			return nil, p.errorf(v.Pos(), "can't handle non-empty unnamed interface types")
@@ -414,9 +738,30 @@ func (p *fileParser) parseType(pkg string, typ ast.Expr) (model.Type, error) {
 	return nil, fmt.Errorf("don't know how to parse type %T", typ)
 }
 
+// parseGenericType parses the instantiation of the generic type x with the
+// type arguments indices.
+func (p *fileParser) parseGenericType(pkg string, x ast.Expr, indices []ast.Expr) (model.Type, error) {
+	t, err := p.parseType(pkg, x)
+	if err != nil {
+		return nil, err
+	}
+	nt, ok := t.(*model.NamedType)
+	if !ok {
+		return nil, p.errorf(x.Pos(), "can't instantiate non-generic type %s", t.String(nil, ""))
+	}
+	for _, index := range indices {
+		arg, err := p.parseType(pkg, index)
+		if err != nil {
+			return nil, err
+		}
+		nt.TypeArgs = append(nt.TypeArgs, arg)
+	}
+	return nt, nil
+}
+
 // importsOfFile returns a map of package name to import path
-// of the imports in file.
-func importsOfFile(file *ast.File) map[string]string {
+// of the imports in file, and the import paths of its dot imports.
+func importsOfFile(file *ast.File) (map[string]string, []string) {
 	/* We have to make guesses about some imports, because imports are not required
 	 * to have names. Named imports are always certain. Unnamed imports are guessed
 	 * to have a name of the last path component; if the last path component has dots,
@@ -424,6 +769,7 @@ func importsOfFile(file *ast.File) map[string]string {
 	 */
 
 	m := make(map[string]string)
+	var dotImports []string
 	for _, is := range file.Imports {
 		var pkg string
 		importPath := is.Path.Value[1 : len(is.Path.Value)-1] // remove quotes
@@ -432,7 +778,11 @@ func importsOfFile(file *ast.File) map[string]string {
 			if is.Name.Name == "_" {
 				continue
 			}
-			pkg = removeDot(is.Name.Name)
+			if is.Name.Name == "." {
+				dotImports = append(dotImports, importPath)
+				continue
+			}
+			pkg = is.Name.Name
 		} else {
 			_, last := path.Split(importPath)
 			pkg = strings.SplitN(last, ".", 2)[0]
@@ -442,17 +792,18 @@ func importsOfFile(file *ast.File) map[string]string {
 		}
 		m[pkg] = importPath
 	}
-	return m
+	return m, dotImports
 }
 
 type namedInterface struct {
-	name *ast.Ident
-	it   *ast.InterfaceType
+	name       *ast.Ident
+	it         *ast.InterfaceType
+	typeParams *ast.FieldList // nil unless the interface is generic
 }
 
 // Create an iterator over all interfaces in file.
-func iterInterfaces(file *ast.File) <-chan namedInterface {
-	ch := make(chan namedInterface)
+func iterInterfaces(file *ast.File) <-chan *namedInterface {
+	ch := make(chan *namedInterface)
 	go func() {
 		for _, decl := range file.Decls {
 			gd, ok := decl.(*ast.GenDecl)
@@ -469,7 +820,7 @@ func iterInterfaces(file *ast.File) <-chan namedInterface {
 					continue
 				}
 
-				ch <- namedInterface{ts.Name, it}
+				ch <- &namedInterface{ts.Name, it, ts.TypeParams}
 			}
 		}
 		close(ch)
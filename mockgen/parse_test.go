@@ -0,0 +1,119 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/mockgen/model"
+	"github.com/golang/mock/mockgen/tests/embedded_interface"
+	"github.com/golang/mock/mockgen/tests/source_mode"
+)
+
+type byMethodName []*model.Method
+
+func (ms byMethodName) Len() int           { return len(ms) }
+func (ms byMethodName) Swap(i, j int)      { ms[i], ms[j] = ms[j], ms[i] }
+func (ms byMethodName) Less(i, j int) bool { return ms[i].Name < ms[j].Name }
+
+// generateInterface generates the mock of intf, with its methods in name
+// order as reflect mode lists them.
+func generateInterface(t *testing.T, intf *model.Interface) string {
+	sort.Sort(byMethodName(intf.Methods))
+	g := new(generator)
+	g.filename = "source.go"
+	if err := g.Generate(&model.Package{Name: "source_mode", Interfaces: []*model.Interface{intf}}, "mock_source_mode"); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	return string(g.Output())
+}
+
+func TestParseFileMatchesReflect(t *testing.T) {
+	pkg, err := ParseFile("tests/source_mode/source.go", nil)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(pkg.DotImports) != 0 {
+		t.Errorf("DotImports = %v, want the dot import resolved", pkg.DotImports)
+	}
+	var parsed *model.Interface
+	for _, intf := range pkg.Interfaces {
+		if intf.Name == "Store" {
+			parsed = intf
+		}
+	}
+	if parsed == nil {
+		t.Fatalf("Store not found in %v interfaces", len(pkg.Interfaces))
+	}
+
+	reflected, err := model.InterfaceFromInterfaceType(reflect.TypeOf((*source_mode.Store)(nil)).Elem())
+	if err != nil {
+		t.Fatalf("InterfaceFromInterfaceType: %v", err)
+	}
+	reflected.Name = "Store"
+
+	if got, want := generateInterface(t, parsed), generateInterface(t, reflected); got != want {
+		t.Errorf("source mode generated\n%s\nreflect mode generated\n%s", got, want)
+	}
+}
+
+// clearParameterNames drops the parameter names of the methods of intf, which
+// reflect mode doesn't know.
+func clearParameterNames(intf *model.Interface) {
+	for _, m := range intf.Methods {
+		for _, p := range append(append(m.In, m.Out...), m.Variadic) {
+			if p != nil {
+				p.Name = ""
+			}
+		}
+	}
+}
+
+func TestParseEmbeddedMatchesReflect(t *testing.T) {
+	pkg, err := ParseFile("tests/embedded_interface/embedded.go", nil)
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	types := map[string]reflect.Type{
+		"ReadCloseWriter": reflect.TypeOf((*embedded_interface.ReadCloseWriter)(nil)).Elem(),
+		"Both":            reflect.TypeOf((*embedded_interface.Both)(nil)).Elem(),
+		"Cache":           reflect.TypeOf((*embedded_interface.Cache)(nil)).Elem(),
+		"Failure":         reflect.TypeOf((*embedded_interface.Failure)(nil)).Elem(),
+		"Anything":        reflect.TypeOf((*embedded_interface.Anything)(nil)).Elem(),
+	}
+	if len(pkg.Interfaces) != len(types) {
+		t.Fatalf("got %d interfaces, want %d", len(pkg.Interfaces), len(types))
+	}
+	for _, parsed := range pkg.Interfaces {
+		reflected, err := model.InterfaceFromInterfaceType(types[parsed.Name])
+		if err != nil {
+			t.Fatalf("InterfaceFromInterfaceType(%s): %v", parsed.Name, err)
+		}
+		reflected.Name = parsed.Name
+		clearParameterNames(parsed)
+
+		if got, want := generateInterface(t, parsed), generateInterface(t, reflected); got != want {
+			t.Errorf("source mode generated\n%s\nreflect mode generated\n%s", got, want)
+		}
+	}
+}
+
+func TestParseFileNamedInterfaces(t *testing.T) {
+	pkg, err := ParseFile("tests/source_mode/source.go", []string{"Store", "Reader"})
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	var names []string
+	for _, intf := range pkg.Interfaces {
+		names = append(names, intf.Name)
+	}
+	if got, want := strings.Join(names, ","), "Store,Reader"; got != want {
+		t.Errorf("interfaces = %s, want %s", got, want)
+	}
+
+	_, err = ParseFile("tests/source_mode/source.go", []string{"Missing"})
+	if err == nil || !strings.Contains(err.Error(), "interface Missing not found") {
+		t.Errorf("ParseFile with unknown interface: err = %v", err)
+	}
+}
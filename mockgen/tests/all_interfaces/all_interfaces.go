@@ -0,0 +1,18 @@
+//go:generate mockgen -all -destination mock_all_interfaces/mocks.go -destination_per_interface Alpha=mock_all_interfaces/alpha_mock.go github.com/golang/mock/mockgen/tests/all_interfaces
+
+package all_interfaces
+
+// Alpha is mocked into a file of its own.
+type Alpha interface {
+	A() int
+}
+
+// beta is unexported, so -all leaves it out.
+type beta interface {
+	B() int
+}
+
+// Gamma is generic, so -all leaves it out in reflect mode.
+type Gamma[T any] interface {
+	G() T
+}
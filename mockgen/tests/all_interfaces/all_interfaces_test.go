@@ -0,0 +1,31 @@
+package all_interfaces_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/mock/mockgen/tests/all_interfaces"
+	"github.com/golang/mock/mockgen/tests/all_interfaces/mock_all_interfaces"
+)
+
+// Zeta is declared in a test file, so -all leaves it out in reflect mode.
+type Zeta interface {
+	Z()
+}
+
+// TestValidInterface assesses whether or not the generated mocks are valid
+func TestValidInterface(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var a all_interfaces.Alpha = mock_all_interfaces.NewMockAlpha(ctrl)
+	var d all_interfaces.Delta = mock_all_interfaces.NewMockDelta(ctrl)
+	e := mock_all_interfaces.NewMockEpsilon(ctrl)
+	e.EXPECT().E(d).Return(nil)
+
+	var ei all_interfaces.Epsilon = e
+	if err := ei.E(d); err != nil {
+		t.Errorf("E(d) = %v", err)
+	}
+	_ = a
+}
@@ -0,0 +1,11 @@
+package all_interfaces
+
+// Delta is mocked together with the other interfaces.
+type Delta interface {
+	D() string
+}
+
+// Epsilon is mocked together with the other interfaces.
+type Epsilon interface {
+	E(Delta) error
+}
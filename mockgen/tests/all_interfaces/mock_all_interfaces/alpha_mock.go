@@ -0,0 +1,45 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/golang/mock/mockgen/tests/all_interfaces (interfaces: Alpha)
+
+// Package mock_all_interfaces is a generated GoMock package.
+package mock_all_interfaces
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockAlpha is a mock of Alpha interface
+type MockAlpha struct {
+	ctrl     *gomock.Controller
+	recorder *MockAlphaMockRecorder
+}
+
+// MockAlphaMockRecorder is the mock recorder for MockAlpha
+type MockAlphaMockRecorder struct {
+	mock *MockAlpha
+}
+
+// NewMockAlpha creates a new mock instance
+func NewMockAlpha(ctrl *gomock.Controller) *MockAlpha {
+	mock := &MockAlpha{ctrl: ctrl}
+	mock.recorder = &MockAlphaMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAlpha) EXPECT() *MockAlphaMockRecorder {
+	return m.recorder
+}
+
+// A mocks base method
+func (m *MockAlpha) A() int {
+	ret := m.ctrl.Call(m, "A")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// A indicates an expected call of A
+func (mr *MockAlphaMockRecorder) A() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "A", reflect.TypeOf((*MockAlpha)(nil).A))
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/golang/mock/mockgen/tests/all_interfaces (interfaces: Delta,Epsilon)
+
+// Package mock_all_interfaces is a generated GoMock package.
+package mock_all_interfaces
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	all_interfaces "github.com/golang/mock/mockgen/tests/all_interfaces"
+	reflect "reflect"
+)
+
+// MockDelta is a mock of Delta interface
+type MockDelta struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeltaMockRecorder
+}
+
+// MockDeltaMockRecorder is the mock recorder for MockDelta
+type MockDeltaMockRecorder struct {
+	mock *MockDelta
+}
+
+// NewMockDelta creates a new mock instance
+func NewMockDelta(ctrl *gomock.Controller) *MockDelta {
+	mock := &MockDelta{ctrl: ctrl}
+	mock.recorder = &MockDeltaMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDelta) EXPECT() *MockDeltaMockRecorder {
+	return m.recorder
+}
+
+// D mocks base method
+func (m *MockDelta) D() string {
+	ret := m.ctrl.Call(m, "D")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// D indicates an expected call of D
+func (mr *MockDeltaMockRecorder) D() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "D", reflect.TypeOf((*MockDelta)(nil).D))
+}
+
+// MockEpsilon is a mock of Epsilon interface
+type MockEpsilon struct {
+	ctrl     *gomock.Controller
+	recorder *MockEpsilonMockRecorder
+}
+
+// MockEpsilonMockRecorder is the mock recorder for MockEpsilon
+type MockEpsilonMockRecorder struct {
+	mock *MockEpsilon
+}
+
+// NewMockEpsilon creates a new mock instance
+func NewMockEpsilon(ctrl *gomock.Controller) *MockEpsilon {
+	mock := &MockEpsilon{ctrl: ctrl}
+	mock.recorder = &MockEpsilonMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockEpsilon) EXPECT() *MockEpsilonMockRecorder {
+	return m.recorder
+}
+
+// E mocks base method
+func (m *MockEpsilon) E(arg0 all_interfaces.Delta) error {
+	ret := m.ctrl.Call(m, "E", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// E indicates an expected call of E
+func (mr *MockEpsilonMockRecorder) E(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "E", reflect.TypeOf((*MockEpsilon)(nil).E), arg0)
+}
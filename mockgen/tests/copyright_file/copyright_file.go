@@ -0,0 +1,10 @@
+//go:generate mockgen -destination mock_copyright/copyright_file_mock.go -package mock_copyright -copyright_file LICENSE.txt -source=copyright_file.go
+//go:generate mockgen -destination mock_no_package_comment/copyright_file_mock.go -package mock_no_package_comment -write_package_comment=false -source=copyright_file.go
+//go:generate mockgen -destination mock_both/copyright_file_mock.go -package mock_both -copyright_file LICENSE.txt -write_package_comment=false -source=copyright_file.go
+
+package copyright_file
+
+// Greeter is mocked with the different headers.
+type Greeter interface {
+	Greet(name string) string
+}
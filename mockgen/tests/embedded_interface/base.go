@@ -0,0 +1,15 @@
+package embedded_interface
+
+type Base interface {
+	ID() string
+}
+
+type Left interface {
+	Base
+	L()
+}
+
+type Right interface {
+	Base
+	R()
+}
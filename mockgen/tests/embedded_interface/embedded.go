@@ -0,0 +1,43 @@
+//go:generate mockgen -destination mock_embedded_interface/embedded_mock.go -source=embedded.go
+
+package embedded_interface
+
+import (
+	"io"
+
+	"github.com/golang/mock/mockgen/tests/embedded_interface/store"
+)
+
+// ReadCloseWriter gets Close from both io.ReadCloser and io.WriteCloser.
+type ReadCloseWriter interface {
+	io.ReadCloser
+	io.WriteCloser
+}
+
+// Both gets ID from both Left and Right, which are declared in base.go.
+type Both interface {
+	Left
+	Right
+}
+
+// Cache embeds an interface of another package that embeds one of its own.
+type Cache interface {
+	store.Store
+	Base
+	Flush() error
+}
+
+// Failure is an error with a code.
+type Failure interface {
+	error
+	Code() int
+}
+
+// Anything embeds the empty interface in all of its spellings.
+type Anything interface {
+	any
+	interface{}
+	interface {
+		Name() string
+	}
+}
@@ -0,0 +1,46 @@
+package embedded_interface_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/mock/mockgen/tests/embedded_interface"
+	"github.com/golang/mock/mockgen/tests/embedded_interface/mock_embedded_interface"
+	"github.com/golang/mock/mockgen/tests/embedded_interface/store"
+)
+
+// TestValidInterface assesses whether or not the generated mocks are valid
+func TestValidInterface(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rcw := mock_embedded_interface.NewMockReadCloseWriter(ctrl)
+	rcw.EXPECT().Close().Return(nil)
+	var c interface{ Close() error } = rcw
+	if err := c.Close(); err != nil {
+		t.Errorf("Close() = %v", err)
+	}
+
+	both := mock_embedded_interface.NewMockBoth(ctrl)
+	both.EXPECT().ID().Return("both")
+	var left embedded_interface.Left = both
+	if id := left.ID(); id != "both" {
+		t.Errorf("ID() = %q", id)
+	}
+
+	cache := mock_embedded_interface.NewMockCache(ctrl)
+	cache.EXPECT().Get(store.Key("k")).Return(store.Value("v"), nil)
+	var s store.Store = cache
+	if v, _ := s.Get("k"); string(v) != "v" {
+		t.Errorf("Get(k) = %q", v)
+	}
+
+	failure := mock_embedded_interface.NewMockFailure(ctrl)
+	failure.EXPECT().Error().Return("failed")
+	var err error = failure
+	if err.Error() != "failed" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+
+	var _ embedded_interface.Anything = mock_embedded_interface.NewMockAnything(ctrl)
+}
@@ -0,0 +1,281 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: embedded.go
+
+// Package mock_embedded_interface is a generated GoMock package.
+package mock_embedded_interface
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	store "github.com/golang/mock/mockgen/tests/embedded_interface/store"
+	reflect "reflect"
+)
+
+// MockReadCloseWriter is a mock of ReadCloseWriter interface
+type MockReadCloseWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockReadCloseWriterMockRecorder
+}
+
+// MockReadCloseWriterMockRecorder is the mock recorder for MockReadCloseWriter
+type MockReadCloseWriterMockRecorder struct {
+	mock *MockReadCloseWriter
+}
+
+// NewMockReadCloseWriter creates a new mock instance
+func NewMockReadCloseWriter(ctrl *gomock.Controller) *MockReadCloseWriter {
+	mock := &MockReadCloseWriter{ctrl: ctrl}
+	mock.recorder = &MockReadCloseWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockReadCloseWriter) EXPECT() *MockReadCloseWriterMockRecorder {
+	return m.recorder
+}
+
+// Read mocks base method
+func (m *MockReadCloseWriter) Read(p []byte) (int, error) {
+	ret := m.ctrl.Call(m, "Read", p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read
+func (mr *MockReadCloseWriterMockRecorder) Read(p interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockReadCloseWriter)(nil).Read), p)
+}
+
+// Close mocks base method
+func (m *MockReadCloseWriter) Close() error {
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close
+func (mr *MockReadCloseWriterMockRecorder) Close() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockReadCloseWriter)(nil).Close))
+}
+
+// Write mocks base method
+func (m *MockReadCloseWriter) Write(p []byte) (int, error) {
+	ret := m.ctrl.Call(m, "Write", p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Write indicates an expected call of Write
+func (mr *MockReadCloseWriterMockRecorder) Write(p interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockReadCloseWriter)(nil).Write), p)
+}
+
+// MockBoth is a mock of Both interface
+type MockBoth struct {
+	ctrl     *gomock.Controller
+	recorder *MockBothMockRecorder
+}
+
+// MockBothMockRecorder is the mock recorder for MockBoth
+type MockBothMockRecorder struct {
+	mock *MockBoth
+}
+
+// NewMockBoth creates a new mock instance
+func NewMockBoth(ctrl *gomock.Controller) *MockBoth {
+	mock := &MockBoth{ctrl: ctrl}
+	mock.recorder = &MockBothMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockBoth) EXPECT() *MockBothMockRecorder {
+	return m.recorder
+}
+
+// ID mocks base method
+func (m *MockBoth) ID() string {
+	ret := m.ctrl.Call(m, "ID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ID indicates an expected call of ID
+func (mr *MockBothMockRecorder) ID() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ID", reflect.TypeOf((*MockBoth)(nil).ID))
+}
+
+// L mocks base method
+func (m *MockBoth) L() {
+	m.ctrl.Call(m, "L")
+}
+
+// L indicates an expected call of L
+func (mr *MockBothMockRecorder) L() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "L", reflect.TypeOf((*MockBoth)(nil).L))
+}
+
+// R mocks base method
+func (m *MockBoth) R() {
+	m.ctrl.Call(m, "R")
+}
+
+// R indicates an expected call of R
+func (mr *MockBothMockRecorder) R() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "R", reflect.TypeOf((*MockBoth)(nil).R))
+}
+
+// MockCache is a mock of Cache interface
+type MockCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockCacheMockRecorder
+}
+
+// MockCacheMockRecorder is the mock recorder for MockCache
+type MockCacheMockRecorder struct {
+	mock *MockCache
+}
+
+// NewMockCache creates a new mock instance
+func NewMockCache(ctrl *gomock.Controller) *MockCache {
+	mock := &MockCache{ctrl: ctrl}
+	mock.recorder = &MockCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCache) EXPECT() *MockCacheMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockCache) Get(arg0 store.Key) (store.Value, error) {
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(store.Value)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockCacheMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCache)(nil).Get), arg0)
+}
+
+// Put mocks base method
+func (m *MockCache) Put(arg0 store.Key, arg1 store.Value) error {
+	ret := m.ctrl.Call(m, "Put", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put
+func (mr *MockCacheMockRecorder) Put(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockCache)(nil).Put), arg0, arg1)
+}
+
+// ID mocks base method
+func (m *MockCache) ID() string {
+	ret := m.ctrl.Call(m, "ID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ID indicates an expected call of ID
+func (mr *MockCacheMockRecorder) ID() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ID", reflect.TypeOf((*MockCache)(nil).ID))
+}
+
+// Flush mocks base method
+func (m *MockCache) Flush() error {
+	ret := m.ctrl.Call(m, "Flush")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush
+func (mr *MockCacheMockRecorder) Flush() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockCache)(nil).Flush))
+}
+
+// MockFailure is a mock of Failure interface
+type MockFailure struct {
+	ctrl     *gomock.Controller
+	recorder *MockFailureMockRecorder
+}
+
+// MockFailureMockRecorder is the mock recorder for MockFailure
+type MockFailureMockRecorder struct {
+	mock *MockFailure
+}
+
+// NewMockFailure creates a new mock instance
+func NewMockFailure(ctrl *gomock.Controller) *MockFailure {
+	mock := &MockFailure{ctrl: ctrl}
+	mock.recorder = &MockFailureMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockFailure) EXPECT() *MockFailureMockRecorder {
+	return m.recorder
+}
+
+// Error mocks base method
+func (m *MockFailure) Error() string {
+	ret := m.ctrl.Call(m, "Error")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Error indicates an expected call of Error
+func (mr *MockFailureMockRecorder) Error() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*MockFailure)(nil).Error))
+}
+
+// Code mocks base method
+func (m *MockFailure) Code() int {
+	ret := m.ctrl.Call(m, "Code")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Code indicates an expected call of Code
+func (mr *MockFailureMockRecorder) Code() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Code", reflect.TypeOf((*MockFailure)(nil).Code))
+}
+
+// MockAnything is a mock of Anything interface
+type MockAnything struct {
+	ctrl     *gomock.Controller
+	recorder *MockAnythingMockRecorder
+}
+
+// MockAnythingMockRecorder is the mock recorder for MockAnything
+type MockAnythingMockRecorder struct {
+	mock *MockAnything
+}
+
+// NewMockAnything creates a new mock instance
+func NewMockAnything(ctrl *gomock.Controller) *MockAnything {
+	mock := &MockAnything{ctrl: ctrl}
+	mock.recorder = &MockAnythingMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAnything) EXPECT() *MockAnythingMockRecorder {
+	return m.recorder
+}
+
+// Name mocks base method
+func (m *MockAnything) Name() string {
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name
+func (mr *MockAnythingMockRecorder) Name() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockAnything)(nil).Name))
+}
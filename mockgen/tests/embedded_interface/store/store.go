@@ -0,0 +1,14 @@
+package store
+
+type Key string
+
+type Value []byte
+
+type Getter interface {
+	Get(Key) (Value, error)
+}
+
+type Store interface {
+	Getter
+	Put(Key, Value) error
+}
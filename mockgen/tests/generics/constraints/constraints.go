@@ -0,0 +1,6 @@
+package constraints
+
+// Number is a constraint used by generics.
+type Number interface {
+	~int | ~float64
+}
@@ -0,0 +1,28 @@
+//go:generate mockgen -destination mock_generics/generics_mock.go -source=generics.go
+
+package generics
+
+import "github.com/golang/mock/mockgen/tests/generics/constraints"
+
+// Page is a generic type of this package.
+type Page[T any] struct {
+	Items []T
+}
+
+// Getter is embedded in Repo with type parameters of Repo.
+type Getter[K comparable, V any] interface {
+	Get(K) (V, error)
+}
+
+// Repo has several type parameters, one of them constrained by a union.
+type Repo[T any, K comparable, N ~int | ~int64] interface {
+	Getter[K, T]
+	List(N, ...K) (Page[T], error)
+	Put(K, T) error
+	Watch(func(K, T)) <-chan []T
+}
+
+// Summer has a type parameter constrained by a type from another package.
+type Summer[N constraints.Number] interface {
+	Sum(...N) N
+}
@@ -0,0 +1,33 @@
+package generics_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/mock/mockgen/tests/generics"
+	"github.com/golang/mock/mockgen/tests/generics/mock_generics"
+)
+
+// TestValidInterface assesses whether or not the generated mocks are valid
+func TestValidInterface(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_generics.NewMockRepo[string, int, int64](ctrl)
+	repo.EXPECT().Get(1).Return("a", nil)
+	repo.EXPECT().List(int64(10), 1, 2).Return(generics.Page[string]{Items: []string{"a", "b"}}, nil)
+	var r generics.Repo[string, int, int64] = repo
+	if v, err := r.Get(1); v != "a" || err != nil {
+		t.Errorf("Get(1) = %q, %v", v, err)
+	}
+	if p, err := r.List(10, 1, 2); len(p.Items) != 2 || err != nil {
+		t.Errorf("List(10, 1, 2) = %v, %v", p, err)
+	}
+
+	summer := mock_generics.NewMockSummer[float64](ctrl)
+	summer.EXPECT().Sum(1.5, 2.5).Return(4.0)
+	var s generics.Summer[float64] = summer
+	if got := s.Sum(1.5, 2.5); got != 4 {
+		t.Errorf("Sum(1.5, 2.5) = %v, want 4", got)
+	}
+}
@@ -0,0 +1,165 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: generics.go
+
+// Package mock_generics is a generated GoMock package.
+package mock_generics
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	generics "github.com/golang/mock/mockgen/tests/generics"
+	constraints "github.com/golang/mock/mockgen/tests/generics/constraints"
+	reflect "reflect"
+)
+
+// MockGetter is a mock of Getter interface
+type MockGetter[K comparable, V any] struct {
+	ctrl     *gomock.Controller
+	recorder *MockGetterMockRecorder[K, V]
+}
+
+// MockGetterMockRecorder is the mock recorder for MockGetter
+type MockGetterMockRecorder[K comparable, V any] struct {
+	mock *MockGetter[K, V]
+}
+
+// NewMockGetter creates a new mock instance
+func NewMockGetter[K comparable, V any](ctrl *gomock.Controller) *MockGetter[K, V] {
+	mock := &MockGetter[K, V]{ctrl: ctrl}
+	mock.recorder = &MockGetterMockRecorder[K, V]{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockGetter[K, V]) EXPECT() *MockGetterMockRecorder[K, V] {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockGetter[K, V]) Get(arg0 K) (V, error) {
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(V)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockGetterMockRecorder[K, V]) Get(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockGetter[K, V])(nil).Get), arg0)
+}
+
+// MockRepo is a mock of Repo interface
+type MockRepo[T any, K comparable, N ~int | ~int64] struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepoMockRecorder[T, K, N]
+}
+
+// MockRepoMockRecorder is the mock recorder for MockRepo
+type MockRepoMockRecorder[T any, K comparable, N ~int | ~int64] struct {
+	mock *MockRepo[T, K, N]
+}
+
+// NewMockRepo creates a new mock instance
+func NewMockRepo[T any, K comparable, N ~int | ~int64](ctrl *gomock.Controller) *MockRepo[T, K, N] {
+	mock := &MockRepo[T, K, N]{ctrl: ctrl}
+	mock.recorder = &MockRepoMockRecorder[T, K, N]{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRepo[T, K, N]) EXPECT() *MockRepoMockRecorder[T, K, N] {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockRepo[T, K, N]) Get(arg0 K) (T, error) {
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(T)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockRepoMockRecorder[T, K, N]) Get(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRepo[T, K, N])(nil).Get), arg0)
+}
+
+// List mocks base method
+func (m *MockRepo[T, K, N]) List(arg0 N, arg1 ...K) (generics.Page[T], error) {
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "List", varargs...)
+	ret0, _ := ret[0].(generics.Page[T])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List
+func (mr *MockRepoMockRecorder[T, K, N]) List(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepo[T, K, N])(nil).List), varargs...)
+}
+
+// Put mocks base method
+func (m *MockRepo[T, K, N]) Put(arg0 K, arg1 T) error {
+	ret := m.ctrl.Call(m, "Put", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Put indicates an expected call of Put
+func (mr *MockRepoMockRecorder[T, K, N]) Put(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockRepo[T, K, N])(nil).Put), arg0, arg1)
+}
+
+// Watch mocks base method
+func (m *MockRepo[T, K, N]) Watch(arg0 func(K, T)) <-chan []T {
+	ret := m.ctrl.Call(m, "Watch", arg0)
+	ret0, _ := ret[0].(<-chan []T)
+	return ret0
+}
+
+// Watch indicates an expected call of Watch
+func (mr *MockRepoMockRecorder[T, K, N]) Watch(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Watch", reflect.TypeOf((*MockRepo[T, K, N])(nil).Watch), arg0)
+}
+
+// MockSummer is a mock of Summer interface
+type MockSummer[N constraints.Number] struct {
+	ctrl     *gomock.Controller
+	recorder *MockSummerMockRecorder[N]
+}
+
+// MockSummerMockRecorder is the mock recorder for MockSummer
+type MockSummerMockRecorder[N constraints.Number] struct {
+	mock *MockSummer[N]
+}
+
+// NewMockSummer creates a new mock instance
+func NewMockSummer[N constraints.Number](ctrl *gomock.Controller) *MockSummer[N] {
+	mock := &MockSummer[N]{ctrl: ctrl}
+	mock.recorder = &MockSummerMockRecorder[N]{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSummer[N]) EXPECT() *MockSummerMockRecorder[N] {
+	return m.recorder
+}
+
+// Sum mocks base method
+func (m *MockSummer[N]) Sum(arg0 ...N) N {
+	varargs := []interface{}{}
+	for _, a := range arg0 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Sum", varargs...)
+	ret0, _ := ret[0].(N)
+	return ret0
+}
+
+// Sum indicates an expected call of Sum
+func (mr *MockSummerMockRecorder[N]) Sum(arg0 ...interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sum", reflect.TypeOf((*MockSummer[N])(nil).Sum), arg0...)
+}
@@ -0,0 +1,6 @@
+package types
+
+// Value is one of several types named types.Value.
+type Value struct {
+	B int
+}
@@ -0,0 +1,4 @@
+package gomock
+
+// Option is a type of a package named like gomock.
+type Option struct{}
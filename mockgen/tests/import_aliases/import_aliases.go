@@ -0,0 +1,15 @@
+//go:generate mockgen -destination mock_import_aliases/import_aliases_mock.go -source=import_aliases.go
+
+package import_aliases
+
+import (
+	atypes "github.com/golang/mock/mockgen/tests/import_aliases/a/types"
+	btypes "github.com/golang/mock/mockgen/tests/import_aliases/b/types"
+	ctypes "github.com/golang/mock/mockgen/tests/import_aliases/c/types"
+	"github.com/golang/mock/mockgen/tests/import_aliases/gomock"
+)
+
+// Converter uses three packages named types and one named gomock.
+type Converter interface {
+	Convert(atypes.Value, btypes.Value, ...gomock.Option) ctypes.Value
+}
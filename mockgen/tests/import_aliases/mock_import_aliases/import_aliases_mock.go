@@ -0,0 +1,54 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: import_aliases.go
+
+// Package mock_import_aliases is a generated GoMock package.
+package mock_import_aliases
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	types "github.com/golang/mock/mockgen/tests/import_aliases/a/types"
+	types0 "github.com/golang/mock/mockgen/tests/import_aliases/b/types"
+	types1 "github.com/golang/mock/mockgen/tests/import_aliases/c/types"
+	gomock0 "github.com/golang/mock/mockgen/tests/import_aliases/gomock"
+	reflect "reflect"
+)
+
+// MockConverter is a mock of Converter interface
+type MockConverter struct {
+	ctrl     *gomock.Controller
+	recorder *MockConverterMockRecorder
+}
+
+// MockConverterMockRecorder is the mock recorder for MockConverter
+type MockConverterMockRecorder struct {
+	mock *MockConverter
+}
+
+// NewMockConverter creates a new mock instance
+func NewMockConverter(ctrl *gomock.Controller) *MockConverter {
+	mock := &MockConverter{ctrl: ctrl}
+	mock.recorder = &MockConverterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockConverter) EXPECT() *MockConverterMockRecorder {
+	return m.recorder
+}
+
+// Convert mocks base method
+func (m *MockConverter) Convert(arg0 types.Value, arg1 types0.Value, arg2 ...gomock0.Option) types1.Value {
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Convert", varargs...)
+	ret0, _ := ret[0].(types1.Value)
+	return ret0
+}
+
+// Convert indicates an expected call of Convert
+func (mr *MockConverterMockRecorder) Convert(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Convert", reflect.TypeOf((*MockConverter)(nil).Convert), varargs...)
+}
@@ -0,0 +1,19 @@
+//go:generate mockgen -package self_package -self_package github.com/golang/mock/mockgen/tests/self_package -destination self_package_mock.go -source=self_package.go
+
+package self_package
+
+import (
+	"io"
+	"time"
+)
+
+// Record is a type of this package.
+type Record struct {
+	ID int
+}
+
+// Store uses types of this package and of other packages.
+type Store interface {
+	Load(id int, timeout time.Duration) (*Record, error)
+	Save(r Record, w io.Writer) ([]Record, map[string]*Record)
+}
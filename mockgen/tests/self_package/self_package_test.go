@@ -0,0 +1,21 @@
+package self_package
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+)
+
+// TestValidInterface assesses whether or not the generated mock is valid
+func TestValidInterface(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var s Store = NewMockStore(ctrl)
+	s.(*MockStore).EXPECT().Load(1, time.Second).Return(&Record{ID: 1}, nil)
+
+	if r, err := s.Load(1, time.Second); err != nil || r.ID != 1 {
+		t.Errorf("Load(1, time.Second) = %v, %v", r, err)
+	}
+}
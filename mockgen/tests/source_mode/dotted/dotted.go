@@ -0,0 +1,9 @@
+package dotted
+
+// Tag is used by source_mode through a dot import.
+type Tag string
+
+// Event is used by source_mode through a dot import.
+type Event struct {
+	Item string
+}
@@ -0,0 +1,9 @@
+package source_mode
+
+// Item is declared in a file other than the source file.
+type Item struct {
+	Name string
+}
+
+// Option configures an Item.
+type Option func(*Item)
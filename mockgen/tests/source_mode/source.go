@@ -0,0 +1,29 @@
+//go:generate mockgen -destination mock_source_mode/source_mock.go -source=source.go -interfaces=Store
+
+package source_mode
+
+import (
+	"io"
+
+	. "github.com/golang/mock/mockgen/tests/source_mode/dotted"
+)
+
+// Reader is embedded in Store from the same file.
+type Reader interface {
+	Read([]byte) (int, error)
+}
+
+// Store uses types from this package, from a dot import and from io.
+type Store interface {
+	Reader
+	io.Closer
+	Delete(*Item) error
+	Get(string, ...Option) (*Item, bool)
+	Put(Item, map[string]Tag) ([]*Item, error)
+	Watch(func(Item)) <-chan Event
+}
+
+// Unused is not mocked, as it is not listed in -interfaces.
+type Unused interface {
+	Frob()
+}
@@ -0,0 +1,22 @@
+package source_mode_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/mock/mockgen/tests/source_mode"
+	"github.com/golang/mock/mockgen/tests/source_mode/mock_source_mode"
+)
+
+// TestValidInterface assesses whether or not the generated mock is valid
+func TestValidInterface(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var s source_mode.Store = mock_source_mode.NewMockStore(ctrl)
+	s.(*mock_source_mode.MockStore).EXPECT().Get("a").Return(&source_mode.Item{Name: "a"}, true)
+
+	if item, ok := s.Get("a"); !ok || item.Name != "a" {
+		t.Errorf("Get(%q) = %v, %v", "a", item, ok)
+	}
+}
@@ -0,0 +1,205 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: typed.go
+
+// Package mock_typed is a generated GoMock package.
+package mock_typed
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockRepo is a mock of Repo interface
+type MockRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepoMockRecorder
+}
+
+// MockRepoMockRecorder is the mock recorder for MockRepo
+type MockRepoMockRecorder struct {
+	mock *MockRepo
+}
+
+// NewMockRepo creates a new mock instance
+func NewMockRepo(ctrl *gomock.Controller) *MockRepo {
+	mock := &MockRepo{ctrl: ctrl}
+	mock.recorder = &MockRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRepo) EXPECT() *MockRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockRepo) Get(key string) (string, error) {
+	ret := m.ctrl.Call(m, "Get", key)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockRepoMockRecorder) Get(key interface{}) *MockRepoGetCall {
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRepo)(nil).Get), key)
+	return &MockRepoGetCall{Call: call}
+}
+
+// MockRepoGetCall wraps *gomock.Call with the types of Get
+type MockRepoGetCall struct {
+	*gomock.Call
+}
+
+// Return declares the values to be returned by Get
+func (c *MockRepoGetCall) Return(arg0 string, arg1 error) *MockRepoGetCall {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do declares the action to run when Get is called
+func (c *MockRepoGetCall) Do(f func(string)) *MockRepoGetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn declares the action to run when Get is called, returning its results
+func (c *MockRepoGetCall) DoAndReturn(f func(string) (string, error)) *MockRepoGetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// List mocks base method
+func (m *MockRepo) List(prefix string, keys ...string) []string {
+	varargs := []interface{}{prefix}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "List", varargs...)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// List indicates an expected call of List
+func (mr *MockRepoMockRecorder) List(prefix interface{}, keys ...interface{}) *MockRepoListCall {
+	varargs := append([]interface{}{prefix}, keys...)
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockRepo)(nil).List), varargs...)
+	return &MockRepoListCall{Call: call}
+}
+
+// MockRepoListCall wraps *gomock.Call with the types of List
+type MockRepoListCall struct {
+	*gomock.Call
+}
+
+// Return declares the values to be returned by List
+func (c *MockRepoListCall) Return(arg0 []string) *MockRepoListCall {
+	c.Call = c.Call.Return(arg0)
+	return c
+}
+
+// Do declares the action to run when List is called
+func (c *MockRepoListCall) Do(f func(string, ...string)) *MockRepoListCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn declares the action to run when List is called, returning its results
+func (c *MockRepoListCall) DoAndReturn(f func(string, ...string) []string) *MockRepoListCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// Reset mocks base method
+func (m *MockRepo) Reset() {
+	m.ctrl.Call(m, "Reset")
+}
+
+// Reset indicates an expected call of Reset
+func (mr *MockRepoMockRecorder) Reset() *MockRepoResetCall {
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockRepo)(nil).Reset))
+	return &MockRepoResetCall{Call: call}
+}
+
+// MockRepoResetCall wraps *gomock.Call with the types of Reset
+type MockRepoResetCall struct {
+	*gomock.Call
+}
+
+// Return declares the values to be returned by Reset
+func (c *MockRepoResetCall) Return() *MockRepoResetCall {
+	c.Call = c.Call.Return()
+	return c
+}
+
+// Do declares the action to run when Reset is called
+func (c *MockRepoResetCall) Do(f func()) *MockRepoResetCall {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn declares the action to run when Reset is called, returning its results
+func (c *MockRepoResetCall) DoAndReturn(f func()) *MockRepoResetCall {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
+
+// MockCache is a mock of Cache interface
+type MockCache[K comparable, V any] struct {
+	ctrl     *gomock.Controller
+	recorder *MockCacheMockRecorder[K, V]
+}
+
+// MockCacheMockRecorder is the mock recorder for MockCache
+type MockCacheMockRecorder[K comparable, V any] struct {
+	mock *MockCache[K, V]
+}
+
+// NewMockCache creates a new mock instance
+func NewMockCache[K comparable, V any](ctrl *gomock.Controller) *MockCache[K, V] {
+	mock := &MockCache[K, V]{ctrl: ctrl}
+	mock.recorder = &MockCacheMockRecorder[K, V]{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockCache[K, V]) EXPECT() *MockCacheMockRecorder[K, V] {
+	return m.recorder
+}
+
+// Load mocks base method
+func (m *MockCache[K, V]) Load(arg0 K) (V, bool) {
+	ret := m.ctrl.Call(m, "Load", arg0)
+	ret0, _ := ret[0].(V)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Load indicates an expected call of Load
+func (mr *MockCacheMockRecorder[K, V]) Load(arg0 interface{}) *MockCacheLoadCall[K, V] {
+	call := mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Load", reflect.TypeOf((*MockCache[K, V])(nil).Load), arg0)
+	return &MockCacheLoadCall[K, V]{Call: call}
+}
+
+// MockCacheLoadCall wraps *gomock.Call with the types of Load
+type MockCacheLoadCall[K comparable, V any] struct {
+	*gomock.Call
+}
+
+// Return declares the values to be returned by Load
+func (c *MockCacheLoadCall[K, V]) Return(arg0 V, arg1 bool) *MockCacheLoadCall[K, V] {
+	c.Call = c.Call.Return(arg0, arg1)
+	return c
+}
+
+// Do declares the action to run when Load is called
+func (c *MockCacheLoadCall[K, V]) Do(f func(K)) *MockCacheLoadCall[K, V] {
+	c.Call = c.Call.Do(f)
+	return c
+}
+
+// DoAndReturn declares the action to run when Load is called, returning its results
+func (c *MockCacheLoadCall[K, V]) DoAndReturn(f func(K) (V, bool)) *MockCacheLoadCall[K, V] {
+	c.Call = c.Call.DoAndReturn(f)
+	return c
+}
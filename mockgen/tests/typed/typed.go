@@ -0,0 +1,15 @@
+//go:generate mockgen -destination mock_typed/typed_mock.go -typed -source=typed.go
+
+package typed
+
+// Repo is mocked with typed call wrappers.
+type Repo interface {
+	Get(key string) (string, error)
+	List(prefix string, keys ...string) []string
+	Reset()
+}
+
+// Cache is generic, so are its call wrappers.
+type Cache[K comparable, V any] interface {
+	Load(K) (V, bool)
+}
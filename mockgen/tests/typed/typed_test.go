@@ -0,0 +1,44 @@
+package typed_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/mock/mockgen/tests/typed"
+	"github.com/golang/mock/mockgen/tests/typed/mock_typed"
+)
+
+func TestTypedCalls(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	m := mock_typed.NewMockRepo(ctrl)
+	m.EXPECT().Get("a").Return("b", nil)
+	m.EXPECT().Get("c").DoAndReturn(func(key string) (string, error) {
+		return key + key, nil
+	})
+	var listed []string
+	m.EXPECT().List("p", "x", "y").Do(func(prefix string, keys ...string) {
+		listed = keys
+	}).Return([]string{"px"})
+	m.EXPECT().Reset().Return().Times(1)
+
+	var r typed.Repo = m
+	if v, err := r.Get("a"); v != "b" || err != nil {
+		t.Errorf(`Get("a") = %q, %v`, v, err)
+	}
+	if v, err := r.Get("c"); v != "cc" || err != nil {
+		t.Errorf(`Get("c") = %q, %v`, v, err)
+	}
+	if got := r.List("p", "x", "y"); len(got) != 1 || len(listed) != 2 {
+		t.Errorf(`List("p", "x", "y") = %v, Do saw %v`, got, listed)
+	}
+	r.Reset()
+
+	c := mock_typed.NewMockCache[string, int](ctrl)
+	c.EXPECT().Load("n").Return(1, true)
+	var cache typed.Cache[string, int] = c
+	if v, ok := cache.Load("n"); v != 1 || !ok {
+		t.Errorf(`Load("n") = %v, %v`, v, ok)
+	}
+}